@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dkrasnykh/graphql-app/internal/config"
+)
+
+const defaultConfigPath = "./config/config.yml"
+
+// --config can be repeated, values of the next file override the previous ones
+type configPaths []string
+
+func (p *configPaths) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *configPaths) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
+
+func loadConfig(name string, args []string) (*config.Config, error) {
+	var paths configPaths
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Var(&paths, "config", "path to config file (can be repeated), default "+defaultConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		paths = configPaths{defaultConfigPath}
+	}
+	return config.Load(paths...)
+}
+
+// config validate [--config path]... prints effective config with redacted secrets
+func validateConfig(args []string, stdout io.Writer, stderr io.Writer) int {
+	cfg, err := loadConfig("config validate", args)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	enc := yaml.NewEncoder(stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func isCommand(args []string, command ...string) bool {
+	if len(args) < len(command) {
+		return false
+	}
+	for i, c := range command {
+		if args[i] != c {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dkrasnykh/graphql-app/internal/config"
+	"github.com/dkrasnykh/graphql-app/internal/storage/database"
+)
+
+// reconcile counters [--config path]... recomputes comment counters of posts and comments from scratch
+func reconcileCounters(args []string, stdout io.Writer, stderr io.Writer) int {
+	return reconcile("reconcile counters", args, stdout, stderr, func(ctx context.Context, storage *database.StoragePostgres) (string, error) {
+		reconciled, err := storage.ReconcileCounters(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to reconcile counters: %w", err)
+		}
+		return fmt.Sprintf("fixed counters of %d posts and %d comments", reconciled.Posts, reconciled.Comments), nil
+	})
+}
+
+// reconcile timelines [--config path]... rebuilds timelines of the home feed (fan-out on write) from follows and posts
+func reconcileTimelines(args []string, stdout io.Writer, stderr io.Writer) int {
+	return reconcile("reconcile timelines", args, stdout, stderr, func(ctx context.Context, storage *database.StoragePostgres) (string, error) {
+		reconciled, err := storage.ReconcileTimelines(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to reconcile timelines: %w", err)
+		}
+		return fmt.Sprintf("added %d and removed %d timeline posts", reconciled.Added, reconciled.Removed), nil
+	})
+}
+
+// runs the reconciliation against the postgres storage of the config and prints its result
+func reconcile(name string, args []string, stdout io.Writer, stderr io.Writer,
+	run func(ctx context.Context, storage *database.StoragePostgres) (string, error)) int {
+	cfg, err := loadConfig(name, args)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if cfg.Storage.Driver == config.DriverMemory {
+		fmt.Fprintln(stdout, "memory storage is empty on start, nothing to reconcile")
+		return 0
+	}
+
+	if err := database.Migrate(cfg.Storage.Postgres); err != nil {
+		fmt.Fprintln(stderr, "failed to migrate database:", err)
+		return 1
+	}
+	storage, err := database.New(cfg.Storage.Postgres)
+	if err != nil {
+		fmt.Fprintln(stderr, "failed to connect to database:", err)
+		return 1
+	}
+	defer storage.Close()
+
+	result, err := run(context.Background(), storage)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintln(stdout, result)
+	return 0
+}
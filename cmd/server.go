@@ -1,69 +1,163 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/99designs/gqlgen/graphql/handler"
-	"github.com/99designs/gqlgen/graphql/handler/extension"
-	"github.com/99designs/gqlgen/graphql/handler/transport"
-	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/gorilla/websocket"
-
 	"github.com/dkrasnykh/graphql-app/graph"
 	"github.com/dkrasnykh/graphql-app/internal/config"
+	"github.com/dkrasnykh/graphql-app/internal/logging"
+	"github.com/dkrasnykh/graphql-app/internal/metrics"
+	"github.com/dkrasnykh/graphql-app/internal/server"
 	"github.com/dkrasnykh/graphql-app/internal/service"
 	"github.com/dkrasnykh/graphql-app/internal/storage/database"
 	"github.com/dkrasnykh/graphql-app/internal/storage/memory"
 	"github.com/dkrasnykh/graphql-app/internal/subscription"
+	"github.com/dkrasnykh/graphql-app/internal/tracing"
 )
 
 func main() {
-	cfg, err := config.Load()
+	if isCommand(os.Args[1:], "config", "validate") {
+		os.Exit(validateConfig(os.Args[3:], os.Stdout, os.Stderr))
+	}
+	if isCommand(os.Args[1:], "reconcile", "counters") {
+		os.Exit(reconcileCounters(os.Args[3:], os.Stdout, os.Stderr))
+	}
+	if isCommand(os.Args[1:], "reconcile", "timelines") {
+		os.Exit(reconcileTimelines(os.Args[3:], os.Stdout, os.Stderr))
+	}
+
+	cfg, err := loadConfig(os.Args[0], os.Args[1:])
+	if err != nil {
+		panic(err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.Logging)
 	if err != nil {
 		panic(err)
 	}
+	slog.SetDefault(logger)
 
-	storager := storage(cfg.IsMemoty, cfg.DatabaseURL)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to setup tracing", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush traces", slog.Any("error", err))
+		}
+	}()
+
+	storager := storage(cfg.Storage)
 	subscriptions := subscription.New()
-	serv := service.New(storager, subscriptions)
 
-	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
+	m := metrics.New(subscriptions)
+	if pg, ok := storager.(*database.StoragePostgres); ok {
+		m.MustRegister(metrics.NewPoolCollector(pg.Stat))
+	}
+
+	serv := service.New(m.Storager(storager), subscriptions,
+		service.WithIdempotencyWindow(cfg.Idempotency.Window),
+		service.WithModerators(cfg.Moderation.Moderators),
+		service.WithFeed(cfg.Feed.SnapshotSize, cfg.Feed.SnapshotTTL, cfg.Feed.SnapshotInterval),
+		service.WithHomeFanOut(cfg.Feed.HomeFanOut))
+
+	h, err := server.NewHandler(cfg, &graph.Resolver{
 		Service:       serv,
 		Subscriptions: subscriptions,
-	}}))
+	}, m)
+	if err != nil {
+		fatal("failed to create handler", err)
+	}
 
-	srv.AddTransport(transport.POST{})
-	srv.AddTransport(transport.Websocket{
-		KeepAlivePingInterval: 10 * time.Second,
-		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
-		},
-	})
-	srv.Use(extension.Introspection{})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	go purgeFeedSnapshots(ctx, serv, cfg.Feed.SnapshotInterval)
 
-	log.Printf("connect to http://localhost:%s/ for GraphQL playground", cfg.Port)
+	srv := server.New(":"+cfg.Port, h, subscriptions, storager, cfg.ShutdownTimeout)
+	scheme := "http"
+	if cfg.HTTP.TLS.Enabled() {
+		certs, err := server.NewCertReloader(cfg.HTTP.TLS)
+		if err != nil {
+			fatal("failed to load tls certificates", err)
+		}
+		go reloadOnSIGHUP(ctx, certs)
+		srv.UseTLS(certs.TLSConfig())
+		scheme = "https"
+	}
+
+	if cfg.PlaygroundEnabled() {
+		slog.Info("connect to " + scheme + "://localhost:" + cfg.Port + "/ for GraphQL playground")
+	}
+
+	if err := srv.Run(ctx); err != nil {
+		slog.Error("server stopped", slog.Any("error", err))
+	}
+
+	// storage is closed after all operations are finished
+	if closer, ok := storager.(interface{ Close() }); ok {
+		closer.Close()
+	}
+	slog.Info("server stopped")
+}
 
-	log.Fatal(http.ListenAndServe(":"+cfg.Port, nil))
+func reloadOnSIGHUP(ctx context.Context, certs *server.CertReloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := certs.Reload(); err != nil {
+				slog.Error("failed to reload tls certificates", slog.Any("error", err))
+				continue
+			}
+			slog.Info("tls certificates reloaded")
+		}
+	}
+}
+
+// deletes expired snapshots of the feed in background, so requests of the feed do not sweep them
+func purgeFeedSnapshots(ctx context.Context, serv *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := serv.PurgeFeedSnapshots(ctx); err != nil {
+				slog.Error("failed to delete expired feed snapshots", slog.Any("error", err))
+			}
+		}
+	}
 }
 
-func storage(isMemory bool, databaseURL string) service.Storager {
-	if isMemory {
+func storage(cfg config.Storage) service.Storager {
+	if cfg.Driver == config.DriverMemory {
 		return memory.New()
 	}
-	err := database.Migrate(databaseURL)
+	err := database.Migrate(cfg.Postgres)
 	if err != nil {
-		log.Fatal(err)
+		fatal("failed to migrate database", err)
 	}
-	storage, err := database.New(databaseURL)
+	storage, err := database.New(cfg.Postgres)
 	if err != nil {
-		log.Fatal(err)
+		fatal("failed to connect to database", err)
 	}
 	return storage
 }
+
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"math"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+)
+
+// NewComplexity returns complexity functions for list fields.
+// Cost of the list field is the cost of one element multiplied by the number of requested elements:
+// limit argument of the field or defaultListSize for the lists without limit.
+func NewComplexity(defaultListSize int) ComplexityRoot {
+	var c ComplexityRoot
+
+	c.Query.Posts = func(childComplexity int) int {
+		return listComplexity(childComplexity, defaultListSize)
+	}
+	c.Query.Comments = func(childComplexity int, postID string, limit *int, offset *int, sort model.CommentSort, after *string) int {
+		return listComplexity(childComplexity, listSize(limit, defaultListSize))
+	}
+	// child complexity includes the comment and one element of both lists
+	c.Query.CommentContext = func(childComplexity int, id string, ancestors *int, descendants *int) int {
+		return listComplexity(childComplexity, listSize(ancestors, defaultListSize)+listSize(descendants, defaultListSize))
+	}
+	c.Query.Feed = func(childComplexity int, algorithm model.FeedAlgorithm, first *int, after *string) int {
+		return listComplexity(childComplexity, listSize(first, defaultListSize))
+	}
+	c.Query.HomeFeed = func(childComplexity int, first *int, after *string) int {
+		return listComplexity(childComplexity, listSize(first, defaultListSize))
+	}
+	c.Query.Followers = func(childComplexity int, userID string, first *int, after *string) int {
+		return listComplexity(childComplexity, listSize(first, defaultListSize))
+	}
+	c.Query.Following = func(childComplexity int, userID string, first *int, after *string) int {
+		return listComplexity(childComplexity, listSize(first, defaultListSize))
+	}
+	c.Query.Notifications = func(childComplexity int, first *int, after *string, unreadOnly *bool) int {
+		return listComplexity(childComplexity, listSize(first, defaultListSize))
+	}
+
+	return c
+}
+
+// returns limit (first) argument of the field or the default size
+func listSize(limit *int, defaultListSize int) int {
+	if limit != nil {
+		return *limit
+	}
+	return defaultListSize
+}
+
+func listComplexity(childComplexity int, size int) int {
+	if size < 1 {
+		size = 1
+	}
+	// avoid overflow for huge limit values
+	if childComplexity > math.MaxInt32/size {
+		return math.MaxInt32
+	}
+	return childComplexity * size
+}
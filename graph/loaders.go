@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/loader"
+)
+
+type loadersKey struct{}
+
+// loaders of the operation, reactions (unread comments) of all posts (comments) in the response are loaded
+// with one storage call
+type loaders struct {
+	reactions map[model.ReactionTargetType]*loader.Loader[int64, []*model.ReactionSummary]
+	unread    *loader.Loader[int64, *model.UnreadComments]
+}
+
+// Loaders is an extension, which puts new loaders into context of every operation,
+// batches are fetched with the context of the operation rather than of the field, which starts the batch
+type Loaders struct {
+	Service IService
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = Loaders{}
+
+func (Loaders) ExtensionName() string {
+	return "Loaders"
+}
+
+func (Loaders) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (l Loaders) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	reactions := make(map[model.ReactionTargetType]*loader.Loader[int64, []*model.ReactionSummary])
+	for _, targetType := range model.AllReactionTargetType {
+		targetType := targetType
+		reactions[targetType] = loader.New(ctx, func(ctx context.Context, ids []int64) (map[int64][]*model.ReactionSummary, error) {
+			return l.Service.ReactionSummaries(ctx, targetType, ids)
+		}, loader.DefaultWait)
+	}
+	unread := loader.New(ctx, l.Service.UnreadComments, loader.DefaultWait)
+	return next(context.WithValue(ctx, loadersKey{}, &loaders{reactions: reactions, unread: unread}))
+}
+
+// loads reactions in the batch of the operation or directly, if resolver is called outside of the operation
+func (r *Resolver) reactions(ctx context.Context, targetType model.ReactionTargetType, id int64) ([]*model.ReactionSummary, error) {
+	var summaries []*model.ReactionSummary
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		var err error
+		if summaries, err = l.reactions[targetType].Load(ctx, id); err != nil {
+			return nil, err
+		}
+	} else {
+		all, err := r.Service.ReactionSummaries(ctx, targetType, []int64{id})
+		if err != nil {
+			return nil, err
+		}
+		summaries = all[id]
+	}
+	// target without reactions, list is not nullable
+	if summaries == nil {
+		summaries = []*model.ReactionSummary{}
+	}
+	return summaries, nil
+}
+
+// loads unread comments in the batch of the operation or directly, nil for anonymous requests
+func (r *Resolver) unreadComments(ctx context.Context, postID int64) (*model.UnreadComments, error) {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l.unread.Load(ctx, postID)
+	}
+	all, err := r.Service.UnreadComments(ctx, []int64{postID})
+	if err != nil {
+		return nil, err
+	}
+	return all[postID], nil
+}
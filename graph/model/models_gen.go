@@ -2,14 +2,70 @@
 
 package model
 
-type Comment struct {
-	ID              string  `json:"id"`
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type BatchComment struct {
+	TempID          *string `json:"tempID,omitempty"`
 	Text            string  `json:"text"`
 	ParentCommentID *string `json:"parentCommentID,omitempty"`
+	ParentTempID    *string `json:"parentTempID,omitempty"`
 	PostID          string  `json:"postID"`
 	UserID          string  `json:"userID"`
 }
 
+type BatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type BatchPost struct {
+	TempID      *string `json:"tempID,omitempty"`
+	Text        string  `json:"text"`
+	UserID      string  `json:"userID"`
+	CommentsOff *bool   `json:"commentsOff,omitempty"`
+}
+
+type Comment struct {
+	ID              string             `json:"id"`
+	Text            string             `json:"text"`
+	ParentCommentID *string            `json:"parentCommentID,omitempty"`
+	PostID          string             `json:"postID"`
+	UserID          string             `json:"userID"`
+	Version         int                `json:"version"`
+	Revisions       []*Revision        `json:"revisions"`
+	Reactions       []*ReactionSummary `json:"reactions"`
+	Score           int                `json:"score"`
+	Upvotes         int                `json:"upvotes"`
+	Downvotes       int                `json:"downvotes"`
+	ReplyCount      int                `json:"replyCount"`
+}
+
+type CommentContext struct {
+	Comment            *Comment   `json:"comment"`
+	Ancestors          []*Comment `json:"ancestors"`
+	HasMoreAncestors   bool       `json:"hasMoreAncestors"`
+	Descendants        []*Comment `json:"descendants"`
+	HasMoreDescendants bool       `json:"hasMoreDescendants"`
+	Cursor             string     `json:"cursor"`
+}
+
+type CreateCommentResult struct {
+	TempID  *string         `json:"tempID,omitempty"`
+	Comment *Comment        `json:"comment,omitempty"`
+	Error   *BatchItemError `json:"error,omitempty"`
+}
+
+type CreatePostResult struct {
+	TempID *string         `json:"tempID,omitempty"`
+	Post   *Post           `json:"post,omitempty"`
+	Error  *BatchItemError `json:"error,omitempty"`
+}
+
 type DisableCommentsRequest struct {
 	UserID string `json:"userID"`
 	PostID string `json:"postID"`
@@ -19,23 +75,67 @@ type Mutation struct {
 }
 
 type NewComment struct {
-	Text            string  `json:"text"`
-	ParentCommentID *string `json:"parentCommentID,omitempty"`
-	PostID          string  `json:"postID"`
-	UserID          string  `json:"userID"`
+	Text             string  `json:"text"`
+	ParentCommentID  *string `json:"parentCommentID,omitempty"`
+	PostID           string  `json:"postID"`
+	UserID           string  `json:"userID"`
+	ClientMutationID *string `json:"clientMutationID,omitempty"`
 }
 
 type NewPost struct {
-	Text        string `json:"text"`
-	UserID      string `json:"userID"`
-	CommentsOff *bool  `json:"commentsOff,omitempty"`
+	Text             string  `json:"text"`
+	UserID           string  `json:"userID"`
+	CommentsOff      *bool   `json:"commentsOff,omitempty"`
+	ClientMutationID *string `json:"clientMutationID,omitempty"`
+}
+
+type Notification struct {
+	ID        string           `json:"id"`
+	Kind      NotificationKind `json:"kind"`
+	ActorID   string           `json:"actorID"`
+	PostID    string           `json:"postID"`
+	CommentID string           `json:"commentID"`
+	Read      bool             `json:"read"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+type NotificationConnection struct {
+	Edges    []*NotificationEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type NotificationEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *Notification `json:"node"`
+}
+
+type PageInfo struct {
+	EndCursor   *string `json:"endCursor,omitempty"`
+	HasNextPage bool    `json:"hasNextPage"`
 }
 
 type Post struct {
-	ID          string `json:"id"`
-	Text        string `json:"text"`
-	UserID      string `json:"userID"`
-	CommentsOff bool   `json:"commentsOff"`
+	ID                 string             `json:"id"`
+	Text               string             `json:"text"`
+	UserID             string             `json:"userID"`
+	CommentsOff        bool               `json:"commentsOff"`
+	Version            int                `json:"version"`
+	Revisions          []*Revision        `json:"revisions"`
+	Reactions          []*ReactionSummary `json:"reactions"`
+	CommentCount       int                `json:"commentCount"`
+	LastActivityAt     time.Time          `json:"lastActivityAt"`
+	UnreadCommentCount *int               `json:"unreadCommentCount,omitempty"`
+	FirstUnreadCursor  *string            `json:"firstUnreadCursor,omitempty"`
+}
+
+type PostConnection struct {
+	Edges    []*PostEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type PostEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Post  `json:"node"`
 }
 
 type PostsSubscribeInput struct {
@@ -45,5 +145,274 @@ type PostsSubscribeInput struct {
 type Query struct {
 }
 
+type ReactionSummary struct {
+	Emoji            string `json:"emoji"`
+	Count            int    `json:"count"`
+	ViewerHasReacted bool   `json:"viewerHasReacted"`
+}
+
+type RestoreRevision struct {
+	PostID          *string `json:"postID,omitempty"`
+	CommentID       *string `json:"commentID,omitempty"`
+	Version         int     `json:"version"`
+	ExpectedVersion int     `json:"expectedVersion"`
+}
+
+type RestoreRevisionResult struct {
+	Post    *Post    `json:"post,omitempty"`
+	Comment *Comment `json:"comment,omitempty"`
+}
+
+type Revision struct {
+	Version   int       `json:"version"`
+	Text      string    `json:"text"`
+	EditorID  string    `json:"editorID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type Subscription struct {
 }
+
+type UpdateComment struct {
+	CommentID       string `json:"commentID"`
+	Text            string `json:"text"`
+	ExpectedVersion int    `json:"expectedVersion"`
+}
+
+type UpdatePost struct {
+	PostID          string `json:"postID"`
+	Text            string `json:"text"`
+	ExpectedVersion int    `json:"expectedVersion"`
+}
+
+type User struct {
+	ID string `json:"id"`
+}
+
+type UserConnection struct {
+	Edges    []*UserEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type UserEdge struct {
+	Cursor     string    `json:"cursor"`
+	Node       *User     `json:"node"`
+	FollowedAt time.Time `json:"followedAt"`
+}
+
+type CommentSort string
+
+const (
+	CommentSortTree          CommentSort = "TREE"
+	CommentSortTop           CommentSort = "TOP"
+	CommentSortNew           CommentSort = "NEW"
+	CommentSortControversial CommentSort = "CONTROVERSIAL"
+)
+
+var AllCommentSort = []CommentSort{
+	CommentSortTree,
+	CommentSortTop,
+	CommentSortNew,
+	CommentSortControversial,
+}
+
+func (e CommentSort) IsValid() bool {
+	switch e {
+	case CommentSortTree, CommentSortTop, CommentSortNew, CommentSortControversial:
+		return true
+	}
+	return false
+}
+
+func (e CommentSort) String() string {
+	return string(e)
+}
+
+func (e *CommentSort) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentSort", str)
+	}
+	return nil
+}
+
+func (e CommentSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FeedAlgorithm string
+
+const (
+	FeedAlgorithmHot     FeedAlgorithm = "HOT"
+	FeedAlgorithmTopDay  FeedAlgorithm = "TOP_DAY"
+	FeedAlgorithmTopWeek FeedAlgorithm = "TOP_WEEK"
+	FeedAlgorithmNew     FeedAlgorithm = "NEW"
+)
+
+var AllFeedAlgorithm = []FeedAlgorithm{
+	FeedAlgorithmHot,
+	FeedAlgorithmTopDay,
+	FeedAlgorithmTopWeek,
+	FeedAlgorithmNew,
+}
+
+func (e FeedAlgorithm) IsValid() bool {
+	switch e {
+	case FeedAlgorithmHot, FeedAlgorithmTopDay, FeedAlgorithmTopWeek, FeedAlgorithmNew:
+		return true
+	}
+	return false
+}
+
+func (e FeedAlgorithm) String() string {
+	return string(e)
+}
+
+func (e *FeedAlgorithm) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FeedAlgorithm(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FeedAlgorithm", str)
+	}
+	return nil
+}
+
+func (e FeedAlgorithm) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindMention NotificationKind = "MENTION"
+	NotificationKindReply   NotificationKind = "REPLY"
+	NotificationKindComment NotificationKind = "COMMENT"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindMention,
+	NotificationKindReply,
+	NotificationKindComment,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindMention, NotificationKindReply, NotificationKindComment:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReactionTargetType string
+
+const (
+	ReactionTargetTypePost    ReactionTargetType = "POST"
+	ReactionTargetTypeComment ReactionTargetType = "COMMENT"
+)
+
+var AllReactionTargetType = []ReactionTargetType{
+	ReactionTargetTypePost,
+	ReactionTargetTypeComment,
+}
+
+func (e ReactionTargetType) IsValid() bool {
+	switch e {
+	case ReactionTargetTypePost, ReactionTargetTypeComment:
+		return true
+	}
+	return false
+}
+
+func (e ReactionTargetType) String() string {
+	return string(e)
+}
+
+func (e *ReactionTargetType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReactionTargetType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReactionTargetType", str)
+	}
+	return nil
+}
+
+func (e ReactionTargetType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type VoteValue string
+
+const (
+	VoteValueUp   VoteValue = "UP"
+	VoteValueDown VoteValue = "DOWN"
+	VoteValueNone VoteValue = "NONE"
+)
+
+var AllVoteValue = []VoteValue{
+	VoteValueUp,
+	VoteValueDown,
+	VoteValueNone,
+}
+
+func (e VoteValue) IsValid() bool {
+	switch e {
+	case VoteValueUp, VoteValueDown, VoteValueNone:
+		return true
+	}
+	return false
+}
+
+func (e VoteValue) String() string {
+	return string(e)
+}
+
+func (e *VoteValue) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VoteValue(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VoteValue", str)
+	}
+	return nil
+}
+
+func (e VoteValue) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,7 @@
+package model
+
+// unread comments of the post for the authenticated user, both unread fields of the post are resolved from it
+type UnreadComments struct {
+	Count             int
+	FirstUnreadCursor *string
+}
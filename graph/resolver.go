@@ -11,18 +11,80 @@ import (
 type IService interface {
 	ValidatePost(input model.NewPost) (*entity.Post, error)
 	SavePost(ctx context.Context, post entity.Post) (*model.Post, error)
+	SavePosts(ctx context.Context, inputs []*model.BatchPost) ([]*model.CreatePostResult, error)
+	ValidateUpdatePost(input model.UpdatePost) (*entity.Edit, error)
+	UpdatePost(ctx context.Context, edit entity.Edit) (*model.Post, error)
+	PostRevisions(ctx context.Context, postID int64) ([]*model.Revision, error)
 	ValidateDisableCommentsRequest(input model.DisableCommentsRequest) (int64, int64, error)
 	DisableComments(ctx context.Context, userID int64, postID int64) error
 	PostById(ctx context.Context, ID int64) (*model.Post, error)
 	AllPosts(ctx context.Context) ([]*model.Post, error)
 	ValidateID(ID string) (int64, error)
+	Feed(ctx context.Context, algorithm model.FeedAlgorithm, first *int, after *string) (*model.PostConnection, error)
+	HomeFeed(ctx context.Context, first *int, after *string) (*model.PostConnection, error)
+	ValidateFollow(userID string) (*entity.Follow, error)
+	Follow(ctx context.Context, follow entity.Follow) (bool, error)
+	Unfollow(ctx context.Context, follow entity.Follow) (bool, error)
+	Followers(ctx context.Context, userID int64, first *int, after *string) (*model.UserConnection, error)
+	Following(ctx context.Context, userID int64, first *int, after *string) (*model.UserConnection, error)
+	AuthenticatedUserID(ctx context.Context) (int64, error)
+	Notifications(ctx context.Context, first *int, after *string, unreadOnly bool) (*model.NotificationConnection, error)
+	ValidateMarkNotificationsRead(ids []string) ([]int64, error)
+	MarkNotificationsRead(ctx context.Context, ids []int64) (int, error)
+	ValidateMarkPostRead(postID string, upToCommentID *string) (*entity.ReadMark, error)
+	MarkPostRead(ctx context.Context, mark entity.ReadMark) (*model.Post, error)
+	UnreadComments(ctx context.Context, postIDs []int64) (map[int64]*model.UnreadComments, error)
 
 	ValidateComment(input model.NewComment) (*entity.Comment, error)
 	SaveComment(ctx context.Context, comment entity.Comment) (*model.Comment, error)
-	AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*model.Comment, error)
+	SaveComments(ctx context.Context, inputs []*model.BatchComment) ([]*model.CreateCommentResult, error)
+	ValidateUpdateComment(input model.UpdateComment) (*entity.Edit, error)
+	UpdateComment(ctx context.Context, edit entity.Edit) (*model.Comment, error)
+	CommentRevisions(ctx context.Context, commentID int64) ([]*model.Revision, error)
+	ValidateRestoreRevision(input model.RestoreRevision) (*entity.Restore, error)
+	RestoreRevision(ctx context.Context, restore entity.Restore) (*model.RestoreRevisionResult, error)
+	AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort model.CommentSort, after *string) ([]*model.Comment, error)
+	CommentByID(ctx context.Context, id int64) (*model.Comment, error)
+	CommentContext(ctx context.Context, id int64, ancestors *int, descendants *int) (*model.CommentContext, error)
+	ValidateVote(commentID string, value model.VoteValue) (*entity.Vote, error)
+	Vote(ctx context.Context, vote entity.Vote) (*model.Comment, error)
+
+	ValidateReaction(targetID string, targetType model.ReactionTargetType, emoji string) (*entity.Reaction, error)
+	React(ctx context.Context, reaction entity.Reaction) (bool, error)
+	Unreact(ctx context.Context, reaction entity.Reaction) (bool, error)
+	ReactionSummaries(ctx context.Context, targetType model.ReactionTargetType, targetIDs []int64) (map[int64][]*model.ReactionSummary, error)
 }
 
 type Resolver struct {
 	Service       IService
 	Subscriptions *subscription.Subscription
 }
+
+// forwards updates of the keys to the subscriber until the client disconnects or the server stops
+func subscribe[T any](ctx context.Context, topic *subscription.Topic[T], keys []int64) <-chan T {
+	// subscription id, updates from server and server shutdown notification
+	subscriptionID, updates, closed := topic.Add(keys)
+	result := make(chan T)
+
+	go func() {
+		defer topic.Delete(subscriptionID)
+		// closed result chan completes the subscription for the client
+		defer close(result)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closed:
+				return
+			case update := <-updates:
+				select {
+				case result <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return result
+}
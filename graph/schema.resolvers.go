@@ -6,9 +6,30 @@ package graph
 
 import (
 	"context"
+
 	"github.com/dkrasnykh/graphql-app/graph/model"
 )
 
+// Revisions is the resolver for the revisions field.
+func (r *commentResolver) Revisions(ctx context.Context, obj *model.Comment) ([]*model.Revision, error) {
+	id, err := r.Service.ValidateID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.CommentRevisions(ctx, id)
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *commentResolver) Reactions(ctx context.Context, obj *model.Comment) ([]*model.ReactionSummary, error) {
+	id, err := r.Service.ValidateID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reactions(ctx, model.ReactionTargetTypeComment, id)
+}
+
 // CreatePost is the resolver for the createPost field.
 func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost) (*model.Post, error) {
 	post, err := r.Service.ValidatePost(input)
@@ -29,6 +50,46 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewCom
 	return r.Service.SaveComment(ctx, *comment)
 }
 
+// CreatePosts is the resolver for the createPosts field.
+func (r *mutationResolver) CreatePosts(ctx context.Context, inputs []*model.BatchPost) ([]*model.CreatePostResult, error) {
+	return r.Service.SavePosts(ctx, inputs)
+}
+
+// CreateComments is the resolver for the createComments field.
+func (r *mutationResolver) CreateComments(ctx context.Context, inputs []*model.BatchComment) ([]*model.CreateCommentResult, error) {
+	return r.Service.SaveComments(ctx, inputs)
+}
+
+// UpdatePost is the resolver for the updatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, input model.UpdatePost) (*model.Post, error) {
+	edit, err := r.Service.ValidateUpdatePost(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.UpdatePost(ctx, *edit)
+}
+
+// UpdateComment is the resolver for the updateComment field.
+func (r *mutationResolver) UpdateComment(ctx context.Context, input model.UpdateComment) (*model.Comment, error) {
+	edit, err := r.Service.ValidateUpdateComment(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.UpdateComment(ctx, *edit)
+}
+
+// RestoreRevision is the resolver for the restoreRevision field.
+func (r *mutationResolver) RestoreRevision(ctx context.Context, input model.RestoreRevision) (*model.RestoreRevisionResult, error) {
+	restore, err := r.Service.ValidateRestoreRevision(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.RestoreRevision(ctx, *restore)
+}
+
 // DisableComments is the resolver for the disableComments field.
 func (r *mutationResolver) DisableComments(ctx context.Context, input model.DisableCommentsRequest) (bool, error) {
 	userID, postID, err := r.Service.ValidateDisableCommentsRequest(input)
@@ -43,6 +104,124 @@ func (r *mutationResolver) DisableComments(ctx context.Context, input model.Disa
 	return true, nil
 }
 
+// React is the resolver for the react field.
+func (r *mutationResolver) React(ctx context.Context, targetID string, targetType model.ReactionTargetType, emoji string) (bool, error) {
+	reaction, err := r.Service.ValidateReaction(targetID, targetType, emoji)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Service.React(ctx, *reaction)
+}
+
+// Unreact is the resolver for the unreact field.
+func (r *mutationResolver) Unreact(ctx context.Context, targetID string, targetType model.ReactionTargetType, emoji string) (bool, error) {
+	reaction, err := r.Service.ValidateReaction(targetID, targetType, emoji)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Service.Unreact(ctx, *reaction)
+}
+
+// Vote is the resolver for the vote field.
+func (r *mutationResolver) Vote(ctx context.Context, commentID string, value model.VoteValue) (*model.Comment, error) {
+	vote, err := r.Service.ValidateVote(commentID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.Vote(ctx, *vote)
+}
+
+// Follow is the resolver for the follow field.
+func (r *mutationResolver) Follow(ctx context.Context, userID string) (bool, error) {
+	follow, err := r.Service.ValidateFollow(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Service.Follow(ctx, *follow)
+}
+
+// Unfollow is the resolver for the unfollow field.
+func (r *mutationResolver) Unfollow(ctx context.Context, userID string) (bool, error) {
+	follow, err := r.Service.ValidateFollow(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Service.Unfollow(ctx, *follow)
+}
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int, error) {
+	notificationIDs, err := r.Service.ValidateMarkNotificationsRead(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.Service.MarkNotificationsRead(ctx, notificationIDs)
+}
+
+// MarkPostRead is the resolver for the markPostRead field.
+func (r *mutationResolver) MarkPostRead(ctx context.Context, postID string, upToCommentID *string) (*model.Post, error) {
+	mark, err := r.Service.ValidateMarkPostRead(postID, upToCommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.MarkPostRead(ctx, *mark)
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *postResolver) Revisions(ctx context.Context, obj *model.Post) ([]*model.Revision, error) {
+	id, err := r.Service.ValidateID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.PostRevisions(ctx, id)
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *postResolver) Reactions(ctx context.Context, obj *model.Post) ([]*model.ReactionSummary, error) {
+	id, err := r.Service.ValidateID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reactions(ctx, model.ReactionTargetTypePost, id)
+}
+
+// UnreadCommentCount is the resolver for the unreadCommentCount field.
+func (r *postResolver) UnreadCommentCount(ctx context.Context, obj *model.Post) (*int, error) {
+	id, err := r.Service.ValidateID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := r.unreadComments(ctx, id)
+	if err != nil || unread == nil {
+		return nil, err
+	}
+	return &unread.Count, nil
+}
+
+// FirstUnreadCursor is the resolver for the firstUnreadCursor field.
+func (r *postResolver) FirstUnreadCursor(ctx context.Context, obj *model.Post) (*string, error) {
+	id, err := r.Service.ValidateID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := r.unreadComments(ctx, id)
+	if err != nil || unread == nil {
+		return nil, err
+	}
+	return unread.FirstUnreadCursor, nil
+}
+
 // Posts is the resolver for the posts field.
 func (r *queryResolver) Posts(ctx context.Context) ([]*model.Post, error) {
 	return r.Service.AllPosts(ctx)
@@ -59,13 +238,68 @@ func (r *queryResolver) Post(ctx context.Context, id string) (*model.Post, error
 }
 
 // Comments is the resolver for the comments field.
-func (r *queryResolver) Comments(ctx context.Context, postID string, limit *int, offset *int) ([]*model.Comment, error) {
+func (r *queryResolver) Comments(ctx context.Context, postID string, limit *int, offset *int, sort model.CommentSort, after *string) ([]*model.Comment, error) {
 	id, err := r.Service.ValidateID(postID)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Service.AllComments(ctx, id, limit, offset)
+	return r.Service.AllComments(ctx, id, limit, offset, sort, after)
+}
+
+// Comment is the resolver for the comment field.
+func (r *queryResolver) Comment(ctx context.Context, id string) (*model.Comment, error) {
+	commentID, err := r.Service.ValidateID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.CommentByID(ctx, commentID)
+}
+
+// CommentContext is the resolver for the commentContext field.
+func (r *queryResolver) CommentContext(ctx context.Context, id string, ancestors *int, descendants *int) (*model.CommentContext, error) {
+	commentID, err := r.Service.ValidateID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.CommentContext(ctx, commentID, ancestors, descendants)
+}
+
+// Feed is the resolver for the feed field.
+func (r *queryResolver) Feed(ctx context.Context, algorithm model.FeedAlgorithm, first *int, after *string) (*model.PostConnection, error) {
+	return r.Service.Feed(ctx, algorithm, first, after)
+}
+
+// HomeFeed is the resolver for the homeFeed field.
+func (r *queryResolver) HomeFeed(ctx context.Context, first *int, after *string) (*model.PostConnection, error) {
+	return r.Service.HomeFeed(ctx, first, after)
+}
+
+// Followers is the resolver for the followers field.
+func (r *queryResolver) Followers(ctx context.Context, userID string, first *int, after *string) (*model.UserConnection, error) {
+	id, err := r.Service.ValidateID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.Followers(ctx, id, first, after)
+}
+
+// Following is the resolver for the following field.
+func (r *queryResolver) Following(ctx context.Context, userID string, first *int, after *string) (*model.UserConnection, error) {
+	id, err := r.Service.ValidateID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Service.Following(ctx, id, first, after)
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *queryResolver) Notifications(ctx context.Context, first *int, after *string, unreadOnly *bool) (*model.NotificationConnection, error) {
+	return r.Service.Notifications(ctx, first, after, unreadOnly != nil && *unreadOnly)
 }
 
 // Comments is the resolver for the comments field.
@@ -79,35 +313,46 @@ func (r *subscriptionResolver) Comments(ctx context.Context, input model.PostsSu
 		}
 		posts = append(posts, postID)
 	}
-	// subscription id and updates from server
-	subscriptionID, updates := r.Subscriptions.Add(posts)
-	result := make(chan *model.Comment)
-
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				r.Subscriptions.Delete(subscriptionID)
-				return
-			case comment := <-updates:
-				result <- comment
-			default:
-			}
-		}
-	}()
+	return subscribe(ctx, r.Subscriptions.Topic, posts), nil
+}
+
+// NewPostsInFeed is the resolver for the newPostsInFeed field.
+func (r *subscriptionResolver) NewPostsInFeed(ctx context.Context) (<-chan *model.Post, error) {
+	userID, err := r.Service.AuthenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	return result, nil
+	return subscribe(ctx, r.Subscriptions.Feed, []int64{userID}), nil
 }
 
+// Notifications is the resolver for the notifications field.
+func (r *subscriptionResolver) Notifications(ctx context.Context) (<-chan *model.Notification, error) {
+	userID, err := r.Service.AuthenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscribe(ctx, r.Subscriptions.Notifications, []int64{userID}), nil
+}
+
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Post returns PostResolver implementation.
+func (r *Resolver) Post() PostResolver { return &postResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+type commentResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func (ts *ResolverTestSuite) TestCreatePosts_OK() {
+	inputs := []*model.BatchPost{
+		{TempID: strPtr("a"), Text: "awesome post 1", UserID: "1"},
+		{TempID: strPtr("b"), Text: "awesome post 2", UserID: "2"},
+	}
+	results, err := ts.mutation.CreatePosts(context.Background(), inputs)
+	ts.Require().NoError(err)
+	ts.Require().Len(results, 2)
+
+	for i, result := range results {
+		ts.Nil(result.Error)
+		ts.Equal(inputs[i].TempID, result.TempID)
+		ts.Equal(inputs[i].Text, result.Post.Text)
+		ts.Equal(strconv.Itoa(i+1), result.Post.ID)
+	}
+}
+
+func (ts *ResolverTestSuite) TestCreatePosts_InvalidItem() {
+	inputs := []*model.BatchPost{
+		{Text: "awesome post 1", UserID: "1"},
+		{Text: "", UserID: "1"},
+	}
+	results, err := ts.mutation.CreatePosts(context.Background(), inputs)
+	ts.Require().NoError(err)
+
+	ts.Nil(results[0].Post)
+	ts.Equal(service.KindAborted, results[0].Error.Code)
+	ts.Nil(results[1].Post)
+	ts.Equal(service.KindInvalidInput, results[1].Error.Code)
+
+	posts, err := ts.query.Posts(context.Background())
+	ts.Require().NoError(err)
+	ts.Empty(posts)
+}
+
+func (ts *ResolverTestSuite) TestCreatePosts_BatchTooLarge() {
+	inputs := make([]*model.BatchPost, service.MaxBatchSize+1)
+	for i := range inputs {
+		inputs[i] = &model.BatchPost{Text: "awesome post", UserID: "1"}
+	}
+	_, err := ts.mutation.CreatePosts(context.Background(), inputs)
+	ts.ErrorIs(err, service.ErrBatchTooLarge)
+}
+
+func (ts *ResolverTestSuite) TestCreateComments_OKTempIDs() {
+	post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	existing, err := ts.mutation.CreateComment(context.Background(), model.NewComment{Text: "comment 1", PostID: post.ID, UserID: "1"})
+	ts.Require().NoError(err)
+
+	inputs := []*model.BatchComment{
+		{TempID: strPtr("root"), Text: "comment 2", PostID: post.ID, UserID: "2"},
+		{TempID: strPtr("reply"), Text: "comment 3", ParentTempID: strPtr("root"), PostID: post.ID, UserID: "1"},
+		{Text: "comment 4", ParentCommentID: &existing.ID, PostID: post.ID, UserID: "2"},
+		{Text: "comment 5", ParentTempID: strPtr("reply"), PostID: post.ID, UserID: "2"},
+	}
+	results, err := ts.mutation.CreateComments(context.Background(), inputs)
+	ts.Require().NoError(err)
+
+	for _, result := range results {
+		ts.Require().Nil(result.Error)
+	}
+	ts.Nil(results[0].Comment.ParentCommentID)
+	ts.Equal(results[0].Comment.ID, *results[1].Comment.ParentCommentID)
+	ts.Equal(existing.ID, *results[2].Comment.ParentCommentID)
+	ts.Equal(results[1].Comment.ID, *results[3].Comment.ParentCommentID)
+
+	limit, offset := 10, 0
+	comments, err := ts.query.Comments(context.Background(), post.ID, &limit, &offset, model.CommentSortTree, nil)
+	ts.Require().NoError(err)
+	texts := make([]string, len(comments))
+	for i, c := range comments {
+		texts[i] = c.Text
+	}
+	ts.Equal([]string{"comment 1", "comment 4", "comment 2", "comment 3", "comment 5"}, texts)
+}
+
+func (ts *ResolverTestSuite) TestCreateComments_InvalidParentTempID() {
+	post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	inputs := []*model.BatchComment{
+		// parent must be declared earlier
+		{Text: "comment 1", ParentTempID: strPtr("later"), PostID: post.ID, UserID: "1"},
+		{TempID: strPtr("later"), Text: "comment 2", PostID: post.ID, UserID: "1"},
+		{TempID: strPtr("later"), Text: "comment 3", PostID: post.ID, UserID: "1"},
+	}
+	results, err := ts.mutation.CreateComments(context.Background(), inputs)
+	ts.Require().NoError(err)
+
+	ts.Equal(service.KindInvalidInput, results[0].Error.Code)
+	ts.Equal(service.KindAborted, results[1].Error.Code)
+	ts.Equal(service.KindInvalidInput, results[2].Error.Code)
+	ts.Contains(results[2].Error.Message, service.ErrDuplicateTempID.Error())
+}
+
+func (ts *ResolverTestSuite) TestCreateComments_StorageError() {
+	disabled := true
+	post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	closed, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "closed post", UserID: "1", CommentsOff: &disabled})
+	ts.Require().NoError(err)
+
+	inputs := []*model.BatchComment{
+		{Text: "comment 1", PostID: post.ID, UserID: "1"},
+		{Text: "comment 2", PostID: closed.ID, UserID: "1"},
+	}
+	results, err := ts.mutation.CreateComments(context.Background(), inputs)
+	ts.Require().NoError(err)
+
+	ts.Equal(service.KindAborted, results[0].Error.Code)
+	ts.Equal(service.KindFailedPrecondition, results[1].Error.Code)
+
+	limit, offset := 10, 0
+	comments, err := ts.query.Comments(context.Background(), post.ID, &limit, &offset, model.CommentSortTree, nil)
+	ts.Require().NoError(err)
+	ts.Empty(comments)
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) TestCreatePost_RetryWithClientMutationID() {
+	ctx := context.Background()
+	input := model.NewPost{Text: "awesome post", UserID: "1", ClientMutationID: strPtr("post-1")}
+
+	first, err := ts.mutation.CreatePost(ctx, input)
+	ts.Require().NoError(err)
+	retry, err := ts.mutation.CreatePost(ctx, input)
+	ts.Require().NoError(err)
+
+	ts.Equal(first, retry)
+	posts, err := ts.query.Posts(ctx)
+	ts.Require().NoError(err)
+	ts.Len(posts, 1)
+}
+
+func (ts *ResolverTestSuite) TestCreatePost_ClientMutationIDScopedByUser() {
+	ctx := context.Background()
+
+	first, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "post", UserID: "1", ClientMutationID: strPtr("key")})
+	ts.Require().NoError(err)
+	second, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "post", UserID: "2", ClientMutationID: strPtr("key")})
+	ts.Require().NoError(err)
+
+	ts.NotEqual(first.ID, second.ID)
+}
+
+func (ts *ResolverTestSuite) TestCreatePost_InvalidClientMutationID() {
+	ctx := context.Background()
+
+	_, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "post", UserID: "1", ClientMutationID: strPtr("")})
+	ts.ErrorIs(err, service.ErrInvalidClientMutationID)
+
+	long := strings.Repeat("k", 256)
+	_, err = ts.mutation.CreatePost(ctx, model.NewPost{Text: "post", UserID: "1", ClientMutationID: &long})
+	ts.ErrorIs(err, service.ErrInvalidClientMutationID)
+}
+
+func (ts *ResolverTestSuite) TestCreateComment_RetryWithClientMutationID() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	postID, err := strconv.ParseInt(post.ID, 10, 64)
+	ts.Require().NoError(err)
+
+	id, updates, _ := ts.subscriptions.Add([]int64{postID})
+	defer ts.subscriptions.Delete(id)
+
+	input := model.NewComment{Text: "comment", PostID: post.ID, UserID: "2", ClientMutationID: strPtr("comment-1")}
+	create := func() (<-chan *model.Comment, <-chan error) {
+		comments, errs := make(chan *model.Comment, 1), make(chan error, 1)
+		go func() {
+			comment, err := ts.mutation.CreateComment(ctx, input)
+			comments <- comment
+			errs <- err
+		}()
+		return comments, errs
+	}
+
+	comments, errs := create()
+	broadcasted := <-updates
+	first := <-comments
+	ts.Require().NoError(<-errs)
+	ts.Equal(first, broadcasted)
+
+	// retry is not broadcasted again, otherwise it blocks until the update is received
+	comments, errs = create()
+	select {
+	case comment := <-updates:
+		ts.Failf("retry is broadcasted", "comment %v", comment)
+	case retry := <-comments:
+		ts.Require().NoError(<-errs)
+		ts.Equal(first, retry)
+	case <-time.After(time.Second):
+		ts.Fail("retry is not finished")
+	}
+
+	limit, offset := 10, 0
+	all, err := ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, nil)
+	ts.Require().NoError(err)
+	ts.Len(all, 1)
+}
+
+func (ts *ResolverTestSuite) TestCreateComment_FailedRequestIsNotRemembered() {
+	ctx := context.Background()
+	input := model.NewComment{Text: "comment", PostID: "1", UserID: "2", ClientMutationID: strPtr("comment-1")}
+
+	_, err := ts.mutation.CreateComment(ctx, input)
+	ts.ErrorIs(err, service.ErrPostNotFound)
+
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	input.PostID = post.ID
+	comment, err := ts.mutation.CreateComment(ctx, input)
+	ts.Require().NoError(err)
+	ts.Equal(post.ID, comment.PostID)
+}
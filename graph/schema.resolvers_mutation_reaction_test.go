@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) TestReact_Summaries() {
+	post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	viewer, other := auth.WithUserID(context.Background(), 2), auth.WithUserID(context.Background(), 3)
+	for _, r := range []struct {
+		ctx   context.Context
+		emoji string
+	}{{viewer, "👍"}, {other, "👍"}, {other, "❤️"}} {
+		added, err := ts.mutation.React(r.ctx, post.ID, model.ReactionTargetTypePost, r.emoji)
+		ts.Require().NoError(err)
+		ts.True(added)
+	}
+	// the second reaction with the same emoji is ignored
+	added, err := ts.mutation.React(viewer, post.ID, model.ReactionTargetTypePost, "👍")
+	ts.Require().NoError(err)
+	ts.False(added)
+
+	summaries, err := ts.post.Reactions(viewer, post)
+	ts.Require().NoError(err)
+	ts.Equal([]*model.ReactionSummary{
+		{Emoji: "👍", Count: 2, ViewerHasReacted: true},
+		{Emoji: "❤️", Count: 1, ViewerHasReacted: false},
+	}, summaries)
+
+	removed, err := ts.mutation.Unreact(viewer, post.ID, model.ReactionTargetTypePost, "👍")
+	ts.Require().NoError(err)
+	ts.True(removed)
+
+	// anonymous viewer sees counts only
+	summaries, err = ts.post.Reactions(context.Background(), post)
+	ts.Require().NoError(err)
+	ts.Equal([]*model.ReactionSummary{
+		{Emoji: "❤️", Count: 1, ViewerHasReacted: false},
+		{Emoji: "👍", Count: 1, ViewerHasReacted: false},
+	}, summaries)
+}
+
+func (ts *ResolverTestSuite) TestReactions_Empty() {
+	post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	summaries, err := ts.post.Reactions(context.Background(), post)
+	ts.Require().NoError(err)
+	ts.NotNil(summaries)
+	ts.Empty(summaries)
+}
+
+func (ts *ResolverTestSuite) TestReact_Unauthenticated() {
+	post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.React(context.Background(), post.ID, model.ReactionTargetTypePost, "👍")
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.Unreact(context.Background(), post.ID, model.ReactionTargetTypePost, "👍")
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+}
+
+func (ts *ResolverTestSuite) TestReact_InvalidInput() {
+	ctx := auth.WithUserID(context.Background(), 2)
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	for _, emoji := range []string{"", "like", "👍 ", "<3"} {
+		_, err = ts.mutation.React(ctx, post.ID, model.ReactionTargetTypePost, emoji)
+		ts.ErrorIs(err, service.ErrInvalidEmoji, emoji)
+	}
+	// skin tone modifier and zero width joiner sequences are single emojis
+	for _, emoji := range []string{"👍🏽", "👩‍💻", "❤️"} {
+		_, err = ts.mutation.React(ctx, post.ID, model.ReactionTargetTypePost, emoji)
+		ts.NoError(err, emoji)
+	}
+
+	_, err = ts.mutation.React(ctx, "abc", model.ReactionTargetTypePost, "👍")
+	ts.ErrorIs(err, service.ErrInvalidID)
+}
+
+func (ts *ResolverTestSuite) TestReact_TargetNotFound() {
+	ctx := auth.WithUserID(context.Background(), 2)
+
+	_, err := ts.mutation.React(ctx, "100", model.ReactionTargetTypePost, "👍")
+	ts.ErrorIs(err, service.ErrPostNotFound)
+	_, err = ts.mutation.React(ctx, "100", model.ReactionTargetTypeComment, "👍")
+	ts.ErrorIs(err, service.ErrCommentNotFound)
+}
+
+func (ts *ResolverTestSuite) TestReact_CommentBroadcast() {
+	ctx := auth.WithUserID(context.Background(), 2)
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", PostID: post.ID, UserID: "1"})
+	ts.Require().NoError(err)
+	postID, err := strconv.ParseInt(post.ID, 10, 64)
+	ts.Require().NoError(err)
+
+	id, updates, _ := ts.subscriptions.Add([]int64{postID})
+	defer ts.subscriptions.Delete(id)
+
+	react := func() <-chan error {
+		errs := make(chan error, 1)
+		go func() {
+			_, err := ts.mutation.React(ctx, comment.ID, model.ReactionTargetTypeComment, "👍")
+			errs <- err
+		}()
+		return errs
+	}
+
+	errs := react()
+	select {
+	case got := <-updates:
+		ts.Equal(comment.ID, got.ID)
+	case <-time.After(time.Second):
+		ts.Fail("reaction on the comment is not pushed to subscribers")
+	}
+	ts.Require().NoError(<-errs)
+
+	// reaction already exists, nothing changed, otherwise it blocks until the update is received
+	errs = react()
+	select {
+	case <-updates:
+		ts.Fail("unchanged reactions are pushed to subscribers")
+	case err := <-errs:
+		ts.Require().NoError(err)
+	case <-time.After(time.Second):
+		ts.Fail("reaction is not finished")
+	}
+}
+
+// counts calls of the service, which load reactions
+type countingService struct {
+	IService
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *countingService) ReactionSummaries(ctx context.Context, targetType model.ReactionTargetType, targetIDs []int64) (map[int64][]*model.ReactionSummary, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	return s.IService.ReactionSummaries(ctx, targetType, targetIDs)
+}
+
+func (ts *ResolverTestSuite) TestReactions_LoadedInBatch() {
+	ctx := auth.WithUserID(context.Background(), 2)
+	srv := &countingService{IService: service.New(ts.storage, ts.subscriptions)}
+	resolver := &Resolver{Service: srv}
+
+	var posts []*model.Post
+	for i := 0; i < 5; i++ {
+		post, err := resolver.Mutation().CreatePost(ctx, model.NewPost{Text: "post", UserID: "1"})
+		ts.Require().NoError(err)
+		_, err = resolver.Mutation().React(ctx, post.ID, model.ReactionTargetTypePost, "👍")
+		ts.Require().NoError(err)
+		posts = append(posts, post)
+	}
+
+	// field resolvers of the list items are executed concurrently by gqlgen
+	Loaders{Service: srv}.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		var wg sync.WaitGroup
+		for _, post := range posts {
+			wg.Add(1)
+			go func(post *model.Post) {
+				defer wg.Done()
+				summaries, err := resolver.Post().Reactions(ctx, post)
+				ts.NoError(err)
+				ts.Equal([]*model.ReactionSummary{{Emoji: "👍", Count: 1, ViewerHasReacted: true}}, summaries)
+			}(post)
+		}
+		wg.Wait()
+		return nil
+	})
+
+	ts.Equal(1, srv.calls)
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) TestMarkPostRead() {
+	ctx := auth.WithUserID(context.Background(), 1)
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comments := make([]*model.Comment, 3)
+	for i := range comments {
+		comments[i], err = ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", UserID: "2", PostID: post.ID})
+		ts.Require().NoError(err)
+	}
+
+	unread := func(ctx context.Context, post *model.Post) (*int, *string) {
+		count, err := ts.post.UnreadCommentCount(ctx, post)
+		ts.Require().NoError(err)
+		cursor, err := ts.post.FirstUnreadCursor(ctx, post)
+		ts.Require().NoError(err)
+		return count, cursor
+	}
+	count, cursor := unread(ctx, post)
+	ts.Equal(3, *count)
+	ts.Require().NotNil(cursor)
+
+	marked, err := ts.mutation.MarkPostRead(ctx, post.ID, &comments[0].ID)
+	ts.Require().NoError(err)
+	ts.Equal(post.ID, marked.ID)
+	count, next := unread(ctx, marked)
+	ts.Equal(2, *count)
+	ts.NotEqual(*cursor, *next)
+
+	_, err = ts.mutation.MarkPostRead(ctx, post.ID, nil)
+	ts.Require().NoError(err)
+	count, cursor = unread(ctx, post)
+	ts.Equal(0, *count)
+	ts.Nil(cursor)
+
+	// anonymous request has no read marks
+	count, cursor = unread(context.Background(), post)
+	ts.Nil(count)
+	ts.Nil(cursor)
+}
+
+func (ts *ResolverTestSuite) TestFirstUnreadCursor() {
+	ctx := auth.WithUserID(context.Background(), 1)
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comments := make([]*model.Comment, 3)
+	for i := range comments {
+		comments[i], err = ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", UserID: "2", PostID: post.ID})
+		ts.Require().NoError(err)
+	}
+	limit, offset := 10, 0
+
+	// comments of the cursor start with the oldest unread one
+	cursor, err := ts.post.FirstUnreadCursor(ctx, post)
+	ts.Require().NoError(err)
+	ts.Require().NotNil(cursor)
+	unread, err := ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, cursor)
+	ts.Require().NoError(err)
+	ts.Equal(comments, unread)
+
+	_, err = ts.mutation.MarkPostRead(ctx, post.ID, &comments[0].ID)
+	ts.Require().NoError(err)
+	cursor, err = ts.post.FirstUnreadCursor(ctx, post)
+	ts.Require().NoError(err)
+	ts.Require().NotNil(cursor)
+	unread, err = ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, cursor)
+	ts.Require().NoError(err)
+	ts.Equal(comments[1:], unread)
+
+	// cursor of the comment continues after it
+	thread, err := ts.query.CommentContext(ctx, comments[1].ID, nil, nil)
+	ts.Require().NoError(err)
+	after, err := ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, &thread.Cursor)
+	ts.Require().NoError(err)
+	ts.Equal(comments[2:], after)
+}
+
+func (ts *ResolverTestSuite) TestMarkPostRead_Errors() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.MarkPostRead(ctx, post.ID, nil)
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	ctx = auth.WithUserID(ctx, 1)
+	invalid := "abc"
+	_, err = ts.mutation.MarkPostRead(ctx, "abc", &invalid)
+	ts.ErrorIs(err, service.ErrInvalidID)
+	_, err = ts.mutation.MarkPostRead(ctx, "100", nil)
+	ts.ErrorIs(err, service.ErrPostNotFound)
+	missing := "100"
+	_, err = ts.mutation.MarkPostRead(ctx, post.ID, &missing)
+	ts.ErrorIs(err, service.ErrCommentNotFound)
+}
@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) TestPostRevisions() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "text 1", UserID: "1"})
+	ts.Require().NoError(err)
+	post, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 1), model.UpdatePost{PostID: post.ID, Text: "text 2", ExpectedVersion: 1})
+	ts.Require().NoError(err)
+	post, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, moderatorID), model.UpdatePost{PostID: post.ID, Text: "text 3", ExpectedVersion: 2})
+	ts.Require().NoError(err)
+
+	revisions, err := ts.post.Revisions(ctx, post)
+	ts.Require().NoError(err)
+	ts.Require().Len(revisions, 3)
+	for i, text := range []string{"text 1", "text 2", "text 3"} {
+		ts.Equal(i+1, revisions[i].Version)
+		ts.Equal(text, revisions[i].Text)
+	}
+	ts.Equal("1", revisions[1].EditorID)
+	ts.Equal(strconv.Itoa(moderatorID), revisions[2].EditorID)
+}
+
+func (ts *ResolverTestSuite) TestRestoreRevision_Post() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "text 1", UserID: "1"})
+	ts.Require().NoError(err)
+	_, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 1), model.UpdatePost{PostID: post.ID, Text: "text 2", ExpectedVersion: 1})
+	ts.Require().NoError(err)
+
+	result, err := ts.mutation.RestoreRevision(auth.WithUserID(ctx, 1), model.RestoreRevision{PostID: &post.ID, Version: 1, ExpectedVersion: 2})
+	ts.Require().NoError(err)
+	ts.Nil(result.Comment)
+	ts.Equal("text 1", result.Post.Text)
+	ts.Equal(3, result.Post.Version)
+
+	// restore is a new revision, history is kept
+	revisions, err := ts.post.Revisions(ctx, result.Post)
+	ts.Require().NoError(err)
+	ts.Len(revisions, 3)
+	ts.Equal("text 1", revisions[2].Text)
+}
+
+func (ts *ResolverTestSuite) TestRestoreRevision_CommentByModerator() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "text 1", PostID: post.ID, UserID: "2"})
+	ts.Require().NoError(err)
+	_, err = ts.mutation.UpdateComment(auth.WithUserID(ctx, 2), model.UpdateComment{CommentID: comment.ID, Text: "text 2", ExpectedVersion: 1})
+	ts.Require().NoError(err)
+
+	result, err := ts.mutation.RestoreRevision(auth.WithUserID(ctx, moderatorID), model.RestoreRevision{CommentID: &comment.ID, Version: 1, ExpectedVersion: 2})
+	ts.Require().NoError(err)
+	ts.Nil(result.Post)
+	ts.Equal("text 1", result.Comment.Text)
+
+	revisions, err := ts.comment.Revisions(ctx, result.Comment)
+	ts.Require().NoError(err)
+	ts.Require().Len(revisions, 3)
+	ts.Equal(strconv.Itoa(moderatorID), revisions[2].EditorID)
+}
+
+func (ts *ResolverTestSuite) TestRestoreRevision_Access() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "text 1", UserID: "1"})
+	ts.Require().NoError(err)
+	_, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 1), model.UpdatePost{PostID: post.ID, Text: "text 2", ExpectedVersion: 1})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.RestoreRevision(ctx, model.RestoreRevision{PostID: &post.ID, Version: 1, ExpectedVersion: 2})
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.RestoreRevision(auth.WithUserID(ctx, 2), model.RestoreRevision{PostID: &post.ID, Version: 1, ExpectedVersion: 2})
+	ts.ErrorIs(err, service.ErrAccess)
+
+	_, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 2), model.UpdatePost{PostID: post.ID, Text: "text 3", ExpectedVersion: 2})
+	ts.ErrorIs(err, service.ErrAccess)
+}
+
+func (ts *ResolverTestSuite) TestRestoreRevision_Errors() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "text 1", UserID: "1"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.RestoreRevision(auth.WithUserID(ctx, 1), model.RestoreRevision{PostID: &post.ID, Version: 5, ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrRevisionNotFound)
+
+	_, err = ts.mutation.RestoreRevision(auth.WithUserID(ctx, 1), model.RestoreRevision{PostID: &post.ID, Version: 1, ExpectedVersion: 2})
+	ts.ErrorIs(err, service.ErrVersionConflict)
+
+	_, err = ts.mutation.RestoreRevision(auth.WithUserID(ctx, 1), model.RestoreRevision{Version: 1, ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrInvalidRestoreTarget)
+
+	_, err = ts.mutation.RestoreRevision(auth.WithUserID(ctx, 1), model.RestoreRevision{PostID: &post.ID, CommentID: &post.ID, Version: 1, ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrInvalidRestoreTarget)
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) TestUpdatePost_OK() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	ts.Equal(1, post.Version)
+
+	updated, err := ts.mutation.UpdatePost(auth.WithUserID(ctx, 1), model.UpdatePost{PostID: post.ID, Text: "edited post", ExpectedVersion: post.Version})
+	ts.Require().NoError(err)
+	ts.Equal("edited post", updated.Text)
+	ts.Equal(2, updated.Version)
+}
+
+func (ts *ResolverTestSuite) TestUpdatePost_StaleVersion() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	// two moderators load the same version, the second update is rejected
+	_, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 1), model.UpdatePost{PostID: post.ID, Text: "first edit", ExpectedVersion: post.Version})
+	ts.Require().NoError(err)
+	_, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 1), model.UpdatePost{PostID: post.ID, Text: "second edit", ExpectedVersion: post.Version})
+	ts.ErrorIs(err, service.ErrVersionConflict)
+	ts.Equal(service.KindConflict, service.ErrorKind(err))
+
+	saved, err := ts.query.Post(ctx, post.ID)
+	ts.Require().NoError(err)
+	ts.Equal("first edit", saved.Text)
+}
+
+func (ts *ResolverTestSuite) TestUpdatePost_InvalidInput() {
+	input := model.UpdatePost{PostID: "text", Text: "", ExpectedVersion: 1}
+	_, err := ts.mutation.UpdatePost(context.Background(), input)
+	ts.ErrorIs(err, service.ErrInvalidID)
+	ts.ErrorIs(err, service.ErrEmptyBody)
+}
+
+func (ts *ResolverTestSuite) TestUpdatePost_Editor() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment 1", PostID: post.ID, UserID: "2"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.UpdatePost(ctx, model.UpdatePost{PostID: post.ID, Text: "edited post", ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.UpdateComment(ctx, model.UpdateComment{CommentID: comment.ID, Text: "edited comment", ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+
+	// rights are checked for the authenticated user, not for the user claimed by the client
+	_, err = ts.mutation.UpdatePost(auth.WithUserID(ctx, 3), model.UpdatePost{PostID: post.ID, Text: "edited post", ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrAccess)
+	_, err = ts.mutation.UpdateComment(auth.WithUserID(ctx, 3), model.UpdateComment{CommentID: comment.ID, Text: "edited comment", ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrAccess)
+
+	updated, err := ts.mutation.UpdateComment(auth.WithUserID(ctx, moderatorID), model.UpdateComment{CommentID: comment.ID, Text: "edited comment", ExpectedVersion: 1})
+	ts.Require().NoError(err)
+	ts.Equal("edited comment", updated.Text)
+}
+
+func (ts *ResolverTestSuite) TestUpdateComment_OK() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment 1", PostID: post.ID, UserID: "2"})
+	ts.Require().NoError(err)
+	ts.Equal(1, comment.Version)
+
+	updated, err := ts.mutation.UpdateComment(auth.WithUserID(ctx, 2), model.UpdateComment{CommentID: comment.ID, Text: "edited comment", ExpectedVersion: comment.Version})
+	ts.Require().NoError(err)
+	ts.Equal("edited comment", updated.Text)
+	ts.Equal(2, updated.Version)
+
+	_, err = ts.mutation.UpdateComment(auth.WithUserID(ctx, 2), model.UpdateComment{CommentID: comment.ID, Text: "stale edit", ExpectedVersion: comment.Version})
+	ts.ErrorIs(err, service.ErrVersionConflict)
+}
+
+func (ts *ResolverTestSuite) TestUpdateComment_Errors() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment 1", PostID: post.ID, UserID: "2"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.UpdateComment(auth.WithUserID(ctx, 1), model.UpdateComment{CommentID: comment.ID, Text: "edited comment", ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrAccess)
+
+	_, err = ts.mutation.UpdateComment(auth.WithUserID(ctx, 2), model.UpdateComment{CommentID: "5", Text: "edited comment", ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrCommentNotFound)
+
+	_, err = ts.mutation.UpdateComment(auth.WithUserID(ctx, 2), model.UpdateComment{CommentID: comment.ID, Text: randString(2001), ExpectedVersion: 1})
+	ts.ErrorIs(err, service.ErrCommentBodyTooBig)
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) TestVote_Score() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", PostID: post.ID, UserID: "1"})
+	ts.Require().NoError(err)
+	ts.Equal(0, comment.Score)
+
+	for userID, value := range map[int64]model.VoteValue{2: model.VoteValueUp, 3: model.VoteValueUp, 4: model.VoteValueDown} {
+		_, err = ts.mutation.Vote(auth.WithUserID(ctx, userID), comment.ID, value)
+		ts.Require().NoError(err)
+	}
+	voted, err := ts.mutation.Vote(auth.WithUserID(ctx, 2), comment.ID, model.VoteValueNone)
+	ts.Require().NoError(err)
+
+	ts.Equal(0, voted.Score)
+	ts.Equal(1, voted.Upvotes)
+	ts.Equal(1, voted.Downvotes)
+	ts.Equal(comment.Version, voted.Version)
+}
+
+func (ts *ResolverTestSuite) TestVote_Errors() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", PostID: post.ID, UserID: "1"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.Vote(ctx, comment.ID, model.VoteValueUp)
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.Vote(auth.WithUserID(ctx, 2), "abc", model.VoteValueUp)
+	ts.ErrorIs(err, service.ErrInvalidID)
+	_, err = ts.mutation.Vote(auth.WithUserID(ctx, 2), "100", model.VoteValueUp)
+	ts.ErrorIs(err, service.ErrCommentNotFound)
+}
+
+func (ts *ResolverTestSuite) TestComments_SortTop() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	first, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "first", PostID: post.ID, UserID: "1"})
+	ts.Require().NoError(err)
+	reply, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "reply", PostID: post.ID, UserID: "1", ParentCommentID: &first.ID})
+	ts.Require().NoError(err)
+	second, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "second", PostID: post.ID, UserID: "1"})
+	ts.Require().NoError(err)
+
+	_, err = ts.mutation.Vote(auth.WithUserID(ctx, 2), second.ID, model.VoteValueUp)
+	ts.Require().NoError(err)
+
+	limit, offset := 10, 0
+	list, err := ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTop, nil)
+	ts.Require().NoError(err)
+	ts.Require().Len(list, 3)
+	ts.Equal([]string{second.ID, first.ID, reply.ID}, []string{list[0].ID, list[1].ID, list[2].ID})
+	ts.Equal(1, list[0].Score)
+}
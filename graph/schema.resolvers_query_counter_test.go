@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+)
+
+func (ts *ResolverTestSuite) TestCounters() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	ts.Equal(0, post.CommentCount)
+	ts.False(post.LastActivityAt.IsZero())
+
+	root, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "root", UserID: "2", PostID: post.ID})
+	ts.Require().NoError(err)
+	ts.Equal(0, root.ReplyCount)
+	rootTempID := "root"
+	_, err = ts.mutation.CreateComments(ctx, []*model.BatchComment{
+		{TempID: &rootTempID, Text: "batch root", UserID: "2", PostID: post.ID},
+		{Text: "batch reply", ParentTempID: &rootTempID, UserID: "3", PostID: post.ID},
+		{Text: "reply", ParentCommentID: &root.ID, UserID: "3", PostID: post.ID},
+	})
+	ts.Require().NoError(err)
+
+	updated, err := ts.query.Post(ctx, post.ID)
+	ts.Require().NoError(err)
+	ts.Equal(4, updated.CommentCount)
+	ts.False(updated.LastActivityAt.Before(post.LastActivityAt))
+
+	limit, offset := 10, 0
+	comments, err := ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, nil)
+	ts.Require().NoError(err)
+	replies := make(map[string]int)
+	for _, comment := range comments {
+		replies[comment.Text] = comment.ReplyCount
+	}
+	ts.Equal(map[string]int{"root": 1, "reply": 0, "batch root": 1, "batch reply": 0}, replies)
+}
+
+func (ts *ResolverTestSuite) TestCounters_CreatedPostActivity() {
+	ctx := context.Background()
+	key := "post-1"
+	single, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	withKey, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1", ClientMutationID: &key})
+	ts.Require().NoError(err)
+	results, err := ts.mutation.CreatePosts(ctx, []*model.BatchPost{{Text: "batch post", UserID: "1"}})
+	ts.Require().NoError(err)
+
+	// created posts have the stored last activity
+	for _, created := range []*model.Post{single, withKey, results[0].Post} {
+		stored, err := ts.query.Post(ctx, created.ID)
+		ts.Require().NoError(err)
+		ts.True(stored.LastActivityAt.Equal(created.LastActivityAt))
+	}
+}
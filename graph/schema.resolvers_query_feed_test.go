@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func (ts *ResolverTestSuite) createPosts(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		post, err := ts.mutation.CreatePost(context.Background(), model.NewPost{Text: "awesome post", UserID: "1"})
+		ts.Require().NoError(err)
+		ids[i] = post.ID
+	}
+	return ids
+}
+
+func feedIDs(connection *model.PostConnection) []string {
+	ids := make([]string, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		ids[i] = edge.Node.ID
+	}
+	return ids
+}
+
+func (ts *ResolverTestSuite) TestFeed_PagesAreStable() {
+	ctx := context.Background()
+	ids := ts.createPosts(5)
+	first := 2
+
+	page, err := ts.query.Feed(ctx, model.FeedAlgorithmHot, &first, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[4], ids[3]}, feedIDs(page))
+	ts.True(page.PageInfo.HasNextPage)
+	ts.Equal(page.Edges[1].Cursor, *page.PageInfo.EndCursor)
+
+	// new activity and new posts do not move posts between pages of the loaded feed
+	_, err = ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", PostID: ids[0], UserID: "2"})
+	ts.Require().NoError(err)
+	_, err = ts.mutation.React(auth.WithUserID(ctx, 2), ids[1], model.ReactionTargetTypePost, "🔥")
+	ts.Require().NoError(err)
+	ts.createPosts(1)
+
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmHot, &first, page.PageInfo.EndCursor)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[2], ids[1]}, feedIDs(page))
+	ts.True(page.PageInfo.HasNextPage)
+
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmHot, &first, page.PageInfo.EndCursor)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[0]}, feedIDs(page))
+	ts.False(page.PageInfo.HasNextPage)
+
+	// first pages of the snapshot interval share the snapshot
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmHot, &first, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[4], ids[3]}, feedIDs(page))
+}
+
+func (ts *ResolverTestSuite) TestFeed_SnapshotInterval() {
+	ctx := context.Background()
+	ids := ts.createPosts(2)
+
+	// every first page of the next interval ranks posts again
+	resolver := Resolver{Service: service.New(ts.storage, ts.subscriptions, service.WithFeed(service.DefaultFeedSnapshotSize, time.Hour, time.Nanosecond))}
+	page, err := resolver.Query().Feed(ctx, model.FeedAlgorithmHot, nil, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[1], ids[0]}, feedIDs(page))
+
+	_, err = ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", PostID: ids[0], UserID: "2"})
+	ts.Require().NoError(err)
+	time.Sleep(time.Millisecond)
+
+	page, err = resolver.Query().Feed(ctx, model.FeedAlgorithmHot, nil, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[0], ids[1]}, feedIDs(page))
+
+	// algorithms have own snapshots
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmHot, nil, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{ids[0], ids[1]}, feedIDs(page))
+	ts.createPosts(1)
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmNew, nil, nil)
+	ts.Require().NoError(err)
+	ts.Len(page.Edges, 3)
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmHot, nil, nil)
+	ts.Require().NoError(err)
+	ts.Len(page.Edges, 2)
+}
+
+func (ts *ResolverTestSuite) TestFeed_Algorithms() {
+	ctx := context.Background()
+	ids := ts.createPosts(3)
+	for _, id := range []string{ids[0], ids[0], ids[1]} {
+		_, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", PostID: id, UserID: "2"})
+		ts.Require().NoError(err)
+	}
+
+	for algorithm, expected := range map[model.FeedAlgorithm][]string{
+		model.FeedAlgorithmHot:     {ids[0], ids[1], ids[2]},
+		model.FeedAlgorithmTopDay:  {ids[0], ids[1], ids[2]},
+		model.FeedAlgorithmTopWeek: {ids[0], ids[1], ids[2]},
+		model.FeedAlgorithmNew:     {ids[2], ids[1], ids[0]},
+	} {
+		page, err := ts.query.Feed(ctx, algorithm, nil, nil)
+		ts.Require().NoError(err)
+		ts.Equal(expected, feedIDs(page), algorithm)
+		ts.False(page.PageInfo.HasNextPage)
+	}
+}
+
+func (ts *ResolverTestSuite) TestFeed_Errors() {
+	ctx := context.Background()
+	ts.createPosts(1)
+
+	for _, first := range []int{0, -1, service.MaxPageSize + 1} {
+		_, err := ts.query.Feed(ctx, model.FeedAlgorithmHot, &first, nil)
+		ts.ErrorIs(err, service.ErrInvalidPageSize)
+	}
+	for _, cursor := range []string{"not a cursor", "dW5rbm93bjox", ""} {
+		_, err := ts.query.Feed(ctx, model.FeedAlgorithmHot, nil, &cursor)
+		ts.ErrorIs(err, service.ErrInvalidCursor)
+	}
+
+	page, err := ts.query.Feed(ctx, model.FeedAlgorithmHot, nil, nil)
+	ts.Require().NoError(err)
+	ts.Require().Len(page.Edges, 1)
+	page, err = ts.query.Feed(ctx, model.FeedAlgorithmHot, nil, page.PageInfo.EndCursor)
+	ts.Require().NoError(err)
+	ts.Empty(page.Edges)
+	ts.Nil(page.PageInfo.EndCursor)
+	ts.False(page.PageInfo.HasNextPage)
+}
+
+func (ts *ResolverTestSuite) TestFeed_Empty() {
+	page, err := ts.query.Feed(context.Background(), model.FeedAlgorithmNew, nil, nil)
+	ts.Require().NoError(err)
+	ts.Empty(page.Edges)
+	ts.Nil(page.PageInfo.EndCursor)
+	ts.False(page.PageInfo.HasNextPage)
+}
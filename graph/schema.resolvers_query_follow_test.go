@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func userIDs(connection *model.UserConnection) []string {
+	ids := make([]string, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		ids[i] = edge.Node.ID
+	}
+	return ids
+}
+
+func (ts *ResolverTestSuite) TestFollow_Connections() {
+	ctx := context.Background()
+	for _, followerID := range []int64{2, 3, 4} {
+		followed, err := ts.mutation.Follow(auth.WithUserID(ctx, followerID), "1")
+		ts.Require().NoError(err)
+		ts.True(followed)
+	}
+	followed, err := ts.mutation.Follow(auth.WithUserID(ctx, 2), "1")
+	ts.Require().NoError(err)
+	ts.False(followed)
+
+	first := 2
+	page, err := ts.query.Followers(ctx, "1", &first, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{"4", "3"}, userIDs(page))
+	ts.True(page.PageInfo.HasNextPage)
+	ts.False(page.Edges[0].FollowedAt.IsZero())
+
+	page, err = ts.query.Followers(ctx, "1", &first, page.PageInfo.EndCursor)
+	ts.Require().NoError(err)
+	ts.Equal([]string{"2"}, userIDs(page))
+	ts.False(page.PageInfo.HasNextPage)
+
+	unfollowed, err := ts.mutation.Unfollow(auth.WithUserID(ctx, 3), "1")
+	ts.Require().NoError(err)
+	ts.True(unfollowed)
+	page, err = ts.query.Followers(ctx, "1", nil, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{"4", "2"}, userIDs(page))
+
+	page, err = ts.query.Following(ctx, "2", nil, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]string{"1"}, userIDs(page))
+}
+
+func (ts *ResolverTestSuite) TestFollow_Errors() {
+	ctx := context.Background()
+
+	_, err := ts.mutation.Follow(ctx, "1")
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.Follow(auth.WithUserID(ctx, 1), "abc")
+	ts.ErrorIs(err, service.ErrInvalidID)
+	_, err = ts.mutation.Follow(auth.WithUserID(ctx, 1), "1")
+	ts.ErrorIs(err, service.ErrFollowYourself)
+	_, err = ts.query.HomeFeed(ctx, nil, nil)
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	cursor := "not a cursor"
+	_, err = ts.query.Followers(ctx, "1", nil, &cursor)
+	ts.ErrorIs(err, service.ErrInvalidCursor)
+}
+
+func (ts *ResolverTestSuite) TestHomeFeed() {
+	for _, fanOut := range []string{entity.FanOutWrite, entity.FanOutRead} {
+		ts.Run(fanOut, func() {
+			ts.storage.Clear()
+			resolver := Resolver{Service: service.New(ts.storage, ts.subscriptions, service.WithHomeFanOut(fanOut))}
+			ctx := auth.WithUserID(context.Background(), 1)
+
+			create := func(userID string) string {
+				post, err := resolver.Mutation().CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: userID})
+				ts.Require().NoError(err)
+				return post.ID
+			}
+			old := create("2")
+			_, err := resolver.Mutation().Follow(ctx, "2")
+			ts.Require().NoError(err)
+			create("3")
+			post1, post2 := create("2"), create("2")
+
+			first := 2
+			page, err := resolver.Query().HomeFeed(ctx, &first, nil)
+			ts.Require().NoError(err)
+			ts.Equal([]string{post2, post1}, feedIDs(page))
+			ts.True(page.PageInfo.HasNextPage)
+
+			page, err = resolver.Query().HomeFeed(ctx, &first, page.PageInfo.EndCursor)
+			ts.Require().NoError(err)
+			ts.Equal([]string{old}, feedIDs(page))
+			ts.False(page.PageInfo.HasNextPage)
+		})
+	}
+}
+
+func (ts *ResolverTestSuite) TestNewPostsInFeed() {
+	resolver := Resolver{Service: service.New(ts.storage, ts.subscriptions), Subscriptions: ts.subscriptions}
+	ctx, cancel := context.WithCancel(auth.WithUserID(context.Background(), 1))
+	defer cancel()
+
+	_, err := resolver.Subscription().NewPostsInFeed(context.Background())
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+
+	_, err = resolver.Mutation().Follow(ctx, "2")
+	ts.Require().NoError(err)
+	updates, err := resolver.Subscription().NewPostsInFeed(ctx)
+	ts.Require().NoError(err)
+
+	create := func(userID string) <-chan error {
+		errs := make(chan error, 1)
+		go func() {
+			_, err := resolver.Mutation().CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: userID})
+			errs <- err
+		}()
+		return errs
+	}
+
+	// post of not followed user is not pushed, otherwise it blocks until the update is received
+	errs := create("3")
+	select {
+	case <-updates:
+		ts.Fail("post of not followed user is pushed")
+	case err := <-errs:
+		ts.Require().NoError(err)
+	case <-time.After(time.Second):
+		ts.Fail("post is not created")
+	}
+
+	errs = create("2")
+	select {
+	case post := <-updates:
+		ts.Equal("2", post.UserID)
+	case <-time.After(time.Second):
+		ts.Fail("post of followed user is not pushed")
+	}
+	ts.Require().NoError(<-errs)
+}
@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+type notified struct {
+	kind    model.NotificationKind
+	actorID string
+}
+
+func notifications(connection *model.NotificationConnection) []notified {
+	list := make([]notified, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		list[i] = notified{kind: edge.Node.Kind, actorID: edge.Node.ActorID}
+	}
+	return list
+}
+
+func (ts *ResolverTestSuite) TestNotifications_Kinds() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	parent, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "first", UserID: "2", PostID: post.ID})
+	ts.Require().NoError(err)
+	// user 2 is mentioned and replied, only mention is notified; email is not a mention; author is not notified
+	reply, err := ts.mutation.CreateComment(ctx, model.NewComment{
+		Text:            "@2 @3 @3 mail@4 @5",
+		UserID:          "5",
+		PostID:          post.ID,
+		ParentCommentID: &parent.ID,
+	})
+	ts.Require().NoError(err)
+
+	list := func(userID int64) []notified {
+		connection, err := ts.query.Notifications(auth.WithUserID(ctx, userID), nil, nil, nil)
+		ts.Require().NoError(err)
+		return notifications(connection)
+	}
+	ts.Equal([]notified{{model.NotificationKindComment, "5"}, {model.NotificationKindComment, "2"}}, list(1))
+	ts.Equal([]notified{{model.NotificationKindMention, "5"}}, list(2))
+	ts.Equal([]notified{{model.NotificationKindMention, "5"}}, list(3))
+	ts.Empty(list(4))
+	ts.Empty(list(5))
+
+	connection, err := ts.query.Notifications(auth.WithUserID(ctx, 2), nil, nil, nil)
+	ts.Require().NoError(err)
+	node := connection.Edges[0].Node
+	ts.Equal(post.ID, node.PostID)
+	ts.Equal(reply.ID, node.CommentID)
+	ts.False(node.Read)
+
+	// reply without mention
+	_, err = ts.mutation.CreateComment(ctx, model.NewComment{Text: "reply", UserID: "3", PostID: post.ID, ParentCommentID: &parent.ID})
+	ts.Require().NoError(err)
+	ts.Equal(notified{model.NotificationKindReply, "3"}, list(2)[0])
+}
+
+func (ts *ResolverTestSuite) TestNotifications_MarkRead() {
+	ctx := auth.WithUserID(context.Background(), 1)
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	for _, userID := range []string{"2", "3", "4"} {
+		_, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", UserID: userID, PostID: post.ID})
+		ts.Require().NoError(err)
+	}
+
+	first := 2
+	page, err := ts.query.Notifications(ctx, &first, nil, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]notified{{model.NotificationKindComment, "4"}, {model.NotificationKindComment, "3"}}, notifications(page))
+	ts.True(page.PageInfo.HasNextPage)
+
+	marked, err := ts.mutation.MarkNotificationsRead(ctx, []string{page.Edges[0].Node.ID})
+	ts.Require().NoError(err)
+	ts.Equal(1, marked)
+	// notifications of another user are not marked
+	marked, err = ts.mutation.MarkNotificationsRead(auth.WithUserID(ctx, 2), []string{page.Edges[1].Node.ID})
+	ts.Require().NoError(err)
+	ts.Equal(0, marked)
+
+	unreadOnly := true
+	unread, err := ts.query.Notifications(ctx, nil, nil, &unreadOnly)
+	ts.Require().NoError(err)
+	ts.Equal([]notified{{model.NotificationKindComment, "3"}, {model.NotificationKindComment, "2"}}, notifications(unread))
+
+	marked, err = ts.mutation.MarkNotificationsRead(ctx, nil)
+	ts.Require().NoError(err)
+	ts.Equal(2, marked)
+	unread, err = ts.query.Notifications(ctx, nil, nil, &unreadOnly)
+	ts.Require().NoError(err)
+	ts.Empty(unread.Edges)
+}
+
+func (ts *ResolverTestSuite) TestNotifications_Errors() {
+	ctx := context.Background()
+
+	_, err := ts.query.Notifications(ctx, nil, nil, nil)
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.MarkNotificationsRead(ctx, nil)
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+	_, err = ts.mutation.MarkNotificationsRead(auth.WithUserID(ctx, 1), []string{"abc"})
+	ts.ErrorIs(err, service.ErrInvalidID)
+	_, err = ts.mutation.MarkNotificationsRead(auth.WithUserID(ctx, 1), make([]string, service.MaxPageSize+1))
+	ts.ErrorIs(err, service.ErrInvalidNotificationIDs)
+}
+
+func (ts *ResolverTestSuite) TestNotifications_Subscription() {
+	resolver := Resolver{Service: service.New(ts.storage, ts.subscriptions), Subscriptions: ts.subscriptions}
+	ctx, cancel := context.WithCancel(auth.WithUserID(context.Background(), 1))
+	defer cancel()
+
+	_, err := resolver.Subscription().Notifications(context.Background())
+	ts.ErrorIs(err, service.ErrUnauthenticated)
+
+	post, err := resolver.Mutation().CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	updates, err := resolver.Subscription().Notifications(ctx)
+	ts.Require().NoError(err)
+
+	// broadcast blocks until the update is received
+	errs := make(chan error, 1)
+	go func() {
+		_, err := resolver.Mutation().CreateComment(ctx, model.NewComment{Text: "comment", UserID: "2", PostID: post.ID})
+		errs <- err
+	}()
+	select {
+	case notification := <-updates:
+		ts.Equal(model.NotificationKindComment, notification.Kind)
+		ts.Equal("2", notification.ActorID)
+	case <-time.After(time.Second):
+		ts.Fail("notification is not pushed")
+	}
+	ts.Require().NoError(<-errs)
+}
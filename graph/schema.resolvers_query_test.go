@@ -72,9 +72,13 @@ func (ts *ResolverTestSuite) TestComments_OK() {
 	inputComment7 := model.NewComment{Text: "comment 7", UserID: "1", PostID: post2.ID}
 	comment7, err := ts.mutation.CreateComment(ctx, inputComment7)
 	ts.NoError(err)
+	// created comments have no replies, the query returns current numbers of direct replies
+	comment1.ReplyCount = 2
+	comment3.ReplyCount = 1
+	comment4.ReplyCount = 1
 
 	limit, offset := 10, 0
-	list, err := ts.query.Comments(ctx, post1.ID, &limit, &offset)
+	list, err := ts.query.Comments(ctx, post1.ID, &limit, &offset, model.CommentSortTree, nil)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"]
 	ts.Equal(6, len(list))
@@ -85,13 +89,13 @@ func (ts *ResolverTestSuite) TestComments_OK() {
 	ts.Equal(comment6, list[4])
 	ts.Equal(comment2, list[5])
 	// ["comment 7"]
-	list, err = ts.query.Comments(ctx, post2.ID, &limit, &offset)
+	list, err = ts.query.Comments(ctx, post2.ID, &limit, &offset, model.CommentSortTree, nil)
 	ts.NoError(err)
 	ts.Equal(1, len(list))
 	ts.Equal(comment7, list[0])
 
 	limit, offset = 2, 1
-	list, err = ts.query.Comments(ctx, post1.ID, &limit, &offset)
+	list, err = ts.query.Comments(ctx, post1.ID, &limit, &offset, model.CommentSortTree, nil)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"] -> ["comment 3", "comment 5"]
 	ts.Equal(2, len(list))
@@ -99,7 +103,7 @@ func (ts *ResolverTestSuite) TestComments_OK() {
 	ts.Equal(comment5, list[1])
 
 	limit, offset = 10, 4
-	list, err = ts.query.Comments(ctx, post1.ID, &limit, &offset)
+	list, err = ts.query.Comments(ctx, post1.ID, &limit, &offset, model.CommentSortTree, nil)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"] -> ["comment 6", "comment 2"]
 	ts.Equal(2, len(list))
@@ -107,7 +111,7 @@ func (ts *ResolverTestSuite) TestComments_OK() {
 	ts.Equal(comment2, list[1])
 
 	limit, offset = 10, 10
-	list, err = ts.query.Comments(ctx, post1.ID, &limit, &offset)
+	list, err = ts.query.Comments(ctx, post1.ID, &limit, &offset, model.CommentSortTree, nil)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"] -> []
 	ts.Equal(0, len(list))
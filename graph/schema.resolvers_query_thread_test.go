@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+func ids(comments []*model.Comment) []string {
+	all := make([]string, len(comments))
+	for i, c := range comments {
+		all[i] = c.ID
+	}
+	return all
+}
+
+/*
+"awesome post"
+|-> root
+|	|-> a
+|	|	|-> a1
+|	|	|	|-> a11
+|	|	|	|-> a12
+|	|-> b
+|-> root2
+*/
+func (ts *ResolverTestSuite) TestCommentContext() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	save := func(parentID *string) string {
+		comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", UserID: "2", PostID: post.ID, ParentCommentID: parentID})
+		ts.Require().NoError(err)
+		return comment.ID
+	}
+	root := save(nil)
+	a := save(&root)
+	a1 := save(&a)
+	a11 := save(&a1)
+	a12 := save(&a1)
+	b := save(&root)
+	root2 := save(nil)
+
+	comment, err := ts.query.Comment(ctx, a1)
+	ts.Require().NoError(err)
+	ts.Equal(a1, comment.ID)
+	ts.Equal(2, comment.ReplyCount)
+
+	ancestors, descendants := 1, 1
+	thread, err := ts.query.CommentContext(ctx, a1, &ancestors, &descendants)
+	ts.Require().NoError(err)
+	ts.Equal(a1, thread.Comment.ID)
+	ts.Equal([]string{a}, ids(thread.Ancestors))
+	ts.True(thread.HasMoreAncestors)
+	ts.Equal([]string{a11}, ids(thread.Descendants))
+	ts.True(thread.HasMoreDescendants)
+
+	// the cursor continues the thread after the last returned descendant
+	limit, offset := 10, 0
+	list, err := ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, &thread.Cursor)
+	ts.Require().NoError(err)
+	ts.Equal([]string{a12, b, root2}, ids(list))
+
+	ancestors, descendants = 10, 10
+	thread, err = ts.query.CommentContext(ctx, a1, &ancestors, &descendants)
+	ts.Require().NoError(err)
+	ts.Equal([]string{root, a}, ids(thread.Ancestors))
+	ts.False(thread.HasMoreAncestors)
+	ts.Equal([]string{a11, a12}, ids(thread.Descendants))
+	ts.False(thread.HasMoreDescendants)
+
+	// without descendants the cursor is the cursor of the comment
+	ancestors, descendants = 0, 0
+	thread, err = ts.query.CommentContext(ctx, root, &ancestors, &descendants)
+	ts.Require().NoError(err)
+	ts.Empty(thread.Ancestors)
+	ts.False(thread.HasMoreAncestors)
+	ts.Empty(thread.Descendants)
+	ts.True(thread.HasMoreDescendants)
+	list, err = ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, &thread.Cursor)
+	ts.Require().NoError(err)
+	ts.Equal([]string{a, a1, a11, a12, b, root2}, ids(list))
+}
+
+func (ts *ResolverTestSuite) TestCommentContext_Errors() {
+	ctx := context.Background()
+	post, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "awesome post", UserID: "1"})
+	ts.Require().NoError(err)
+	comment, err := ts.mutation.CreateComment(ctx, model.NewComment{Text: "comment", UserID: "2", PostID: post.ID})
+	ts.Require().NoError(err)
+	other, err := ts.mutation.CreatePost(ctx, model.NewPost{Text: "another post", UserID: "1"})
+	ts.Require().NoError(err)
+
+	_, err = ts.query.Comment(ctx, "abc")
+	ts.ErrorIs(err, service.ErrInvalidID)
+	_, err = ts.query.Comment(ctx, "100")
+	ts.ErrorIs(err, service.ErrCommentNotFound)
+	_, err = ts.query.CommentContext(ctx, "100", nil, nil)
+	ts.ErrorIs(err, service.ErrCommentNotFound)
+	size := -1
+	_, err = ts.query.CommentContext(ctx, comment.ID, &size, nil)
+	ts.ErrorIs(err, service.ErrInvalidContextSize)
+	size = service.MaxPageSize + 1
+	_, err = ts.query.CommentContext(ctx, comment.ID, nil, &size)
+	ts.ErrorIs(err, service.ErrInvalidContextSize)
+
+	thread, err := ts.query.CommentContext(ctx, comment.ID, nil, nil)
+	ts.Require().NoError(err)
+	limit, offset := 10, 0
+	_, err = ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTop, &thread.Cursor)
+	ts.ErrorIs(err, service.ErrCursorSort)
+	_, err = ts.query.Comments(ctx, other.ID, &limit, &offset, model.CommentSortTree, &thread.Cursor)
+	ts.ErrorIs(err, service.ErrInvalidCursor)
+	invalid := "abc"
+	_, err = ts.query.Comments(ctx, post.ID, &limit, &offset, model.CommentSortTree, &invalid)
+	ts.ErrorIs(err, service.ErrInvalidCursor)
+}
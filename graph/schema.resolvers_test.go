@@ -12,18 +12,25 @@ import (
 
 type ResolverTestSuite struct {
 	suite.Suite
-	storage  service.Storager
-	mutation MutationResolver
-	query    QueryResolver
+	storage       service.Storager
+	subscriptions *subscription.Subscription
+	mutation      MutationResolver
+	query         QueryResolver
+	post          PostResolver
+	comment       CommentResolver
 }
 
+const moderatorID = 100
+
 func (ts *ResolverTestSuite) SetupSuite() {
 	ts.storage = memory.New()
-	s := subscription.New()
-	srv := service.New(ts.storage, s)
+	ts.subscriptions = subscription.New()
+	srv := service.New(ts.storage, ts.subscriptions, service.WithModerators([]int64{moderatorID}))
 	resolver := Resolver{Service: srv}
 	ts.mutation = resolver.Mutation()
 	ts.query = resolver.Query()
+	ts.post = resolver.Post()
+	ts.comment = resolver.Comment()
 }
 
 func TestResolver(t *testing.T) {
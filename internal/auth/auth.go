@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// authentication is performed by the API gateway, which passes id of the authenticated user in the header
+const UserIDHeader = "X-User-ID"
+
+type userIDKey struct{}
+
+// Middleware puts id of the authenticated user into request context.
+// The header is trusted only in requests from the trusted networks (API gateway), any other client can set it,
+// so its requests are anonymous as well as requests without (or with invalid) header.
+func Middleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !fromTrusted(r.RemoteAddr, trusted) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if userID, err := strconv.ParseInt(r.Header.Get(UserIDHeader), 10, 64); err == nil {
+				r = r.WithContext(WithUserID(r.Context(), userID))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func fromTrusted(remoteAddr string, trusted []netip.Prefix) bool {
+	addrPort, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	return contains(trusted, addrPort.Addr().Unmap())
+}
+
+func contains(networks []netip.Prefix, addr netip.Addr) bool {
+	for _, network := range networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns ip address of the client. Requests of the trusted networks (API gateway) are proxied,
+// so the client is the last address of X-Forwarded-For outside of the trusted networks (or X-Real-IP);
+// headers of any other client are ignored as well as X-User-ID.
+func ClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !fromTrusted(r.RemoteAddr, trusted) {
+		return peer
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+	// addresses are appended by every proxy, so only the ones added by the trusted proxies can be trusted
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		addr = addr.Unmap()
+		if i == 0 || !contains(trusted, addr) {
+			return addr.String()
+		}
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+	return peer
+}
+
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// returns id of the authenticated user, false for anonymous requests
+func UserID(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	return userID, ok
+}
@@ -1,31 +1,388 @@
 package config
 
 import (
-	"flag"
+	"bytes"
+	"errors"
 	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"gopkg.in/yaml.v3"
 )
 
+const (
+	DriverMemory   = "memory"
+	DriverPostgres = "postgres"
+
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	FanOutWrite = "write"
+	FanOutRead  = "read"
+)
+
+// every field can be overridden by environment variable, name is built from env-prefix of parent structs
+// and env tag of the field (e.g. STORAGE_POSTGRES_MAX_CONNS)
 type Config struct {
-	DatabaseURL  string        `yaml:"database_url" env-required:"true"`
-	QueryTimeout time.Duration `yaml:"query_timeout" env-default:"2s"`
-	Port         string        `yaml:"port" env-required:"true"`
-	IsMemoty     bool
+	// development or production, playground and introspection are turned on in development
+	Environment string `yaml:"environment" env:"ENVIRONMENT" env-default:"production"`
+	Port        string `yaml:"port" env:"PORT" env-default:"8080"`
+	// time for in-flight operations to finish on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"15s"`
+	HTTP            HTTP          `yaml:"http" env-prefix:"HTTP_"`
+	Auth            Auth          `yaml:"auth" env-prefix:"AUTH_"`
+	Limits          Limits        `yaml:"limits" env-prefix:"LIMITS_"`
+	Storage         Storage       `yaml:"storage" env-prefix:"STORAGE_"`
+	Idempotency     Idempotency   `yaml:"idempotency" env-prefix:"IDEMPOTENCY_"`
+	Moderation      Moderation    `yaml:"moderation" env-prefix:"MODERATION_"`
+	Feed            Feed          `yaml:"feed" env-prefix:"FEED_"`
+	GraphQL         GraphQL       `yaml:"graphql" env-prefix:"GRAPHQL_"`
+	// automatic persisted queries and allow-list (strict mode)
+	PersistedQueries PersistedQueries `yaml:"persisted_queries" env-prefix:"PERSISTED_QUERIES_"`
+	RateLimit        RateLimit        `yaml:"rate_limit" env-prefix:"RATE_LIMIT_"`
+	Tracing          Tracing          `yaml:"tracing" env-prefix:"TRACING_"`
+	Logging          Logging          `yaml:"logging" env-prefix:"LOGGING_"`
+}
+
+type HTTP struct {
+	// browser origins allowed for CORS requests and websocket connections, "*" allows any origin
+	// (CORS requests of origins, which are not listed, are allowed without credentials);
+	// requests without Origin header (non-browser clients) are not checked
+	AllowedOrigins []string      `yaml:"allowed_origins" env:"ALLOWED_ORIGINS"`
+	CORSMaxAge     time.Duration `yaml:"cors_max_age" env:"CORS_MAX_AGE" env-default:"10m"`
+	// max size of websocket message in bytes, 0 turns off the limit
+	MaxWebsocketMessageSize int64 `yaml:"max_websocket_message_size" env:"MAX_WEBSOCKET_MESSAGE_SIZE" env-default:"65536"`
+	TLS                     TLS   `yaml:"tls" env-prefix:"TLS_"`
+}
+
+// server terminates TLS (and serves HTTP/2) if certificate and key are set,
+// files are reloaded on SIGHUP
+type TLS struct {
+	CertFile string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"KEY_FILE"`
+	// CA bundle for verification of client certificates (mTLS)
+	ClientCAFile string `yaml:"client_ca_file" env:"CLIENT_CA_FILE"`
+	// none, request (verify certificate if client sends it) or require
+	ClientAuth string `yaml:"client_auth" env:"CLIENT_AUTH" env-default:"none"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// id of the authenticated user is passed by the API gateway in X-User-ID header
+type Auth struct {
+	// networks (CIDR) of the API gateway, the header of other clients is ignored, so their requests are anonymous
+	// and rate limited by client ip
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-default:"127.0.0.1/32,::1/128"`
+}
+
+// TrustedNetworks returns parsed trusted proxies, invalid values are reported by Validate
+func (a Auth) TrustedNetworks() []netip.Prefix {
+	networks := make([]netip.Prefix, 0, len(a.TrustedProxies))
+	for _, cidr := range a.TrustedProxies {
+		if network, err := netip.ParsePrefix(cidr); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// limits of incoming requests, 0 turns off the limit
+type Limits struct {
+	// deadline of queries and mutations
+	OperationTimeout time.Duration `yaml:"operation_timeout" env:"OPERATION_TIMEOUT" env-default:"5s"`
+	// deadlines of resolver fields calling storage, "Type.field": timeout (env: Query.comments:1s,Query.posts:1s)
+	FieldTimeouts map[string]time.Duration `yaml:"field_timeouts" env:"FIELD_TIMEOUTS"`
+	// max size of POST request body in bytes
+	MaxBodySize int64 `yaml:"max_body_size" env:"MAX_BODY_SIZE" env-default:"1048576"`
+	// max size of operation variables (encoded as json) in bytes
+	MaxVariablesSize int `yaml:"max_variables_size" env:"MAX_VARIABLES_SIZE" env-default:"65536"`
+}
+
+type Storage struct {
+	// memory or postgres
+	Driver   string   `yaml:"driver" env:"DRIVER" env-default:"postgres"`
+	Postgres Postgres `yaml:"postgres" env-prefix:"POSTGRES_"`
+}
+
+type Postgres struct {
+	URL string `yaml:"url" env:"URL"`
+	// timeout of a single storage call
+	QueryTimeout   time.Duration `yaml:"query_timeout" env:"QUERY_TIMEOUT" env-default:"2s"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"CONNECT_TIMEOUT" env-default:"5s"`
+	// connection pool sizing, 0 keeps pgxpool defaults
+	MaxConns        int32         `yaml:"max_conns" env:"MAX_CONNS" env-default:"10"`
+	MinConns        int32         `yaml:"min_conns" env:"MIN_CONNS"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"MAX_CONN_LIFETIME" env-default:"1h"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" env:"MAX_CONN_IDLE_TIME" env-default:"30m"`
 }
 
-func Load() (*Config, error) {
-	var isMemory bool
-	flag.BoolVar(&isMemory, "mem", false, "use memory storage")
-	flag.Parse()
+// retried createPost and createComment with the same clientMutationID return the original result
+type Idempotency struct {
+	// time during which keys are kept, 0 turns off idempotency keys
+	Window time.Duration `yaml:"window" env:"WINDOW" env-default:"24h"`
+}
+
+type Moderation struct {
+	// ids of users, who can edit and restore revisions of posts and comments of other users
+	Moderators []int64 `yaml:"moderators" env:"MODERATORS"`
+}
+
+// ranked feed is paginated over a snapshot of the ranking made for the first page,
+// so changes of scores do not move posts between pages
+type Feed struct {
+	// max number of posts in the snapshot (depth of the feed)
+	SnapshotSize int `yaml:"snapshot_size" env:"SNAPSHOT_SIZE" env-default:"1000"`
+	// time during which next pages of the feed can be loaded
+	SnapshotTTL time.Duration `yaml:"snapshot_ttl" env:"SNAPSHOT_TTL" env-default:"1h"`
+	// first pages of the same algorithm during the interval share one snapshot, expired snapshots are deleted every interval
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" env:"SNAPSHOT_INTERVAL" env-default:"1m"`
+	// strategy of the home feed: write (posts are copied into timelines of followers) or read
+	HomeFanOut string `yaml:"home_fan_out" env:"HOME_FAN_OUT" env-default:"write"`
+}
+
+// limits of incoming GraphQL operations
+type GraphQL struct {
+	ComplexityLimit int `yaml:"complexity_limit" env:"COMPLEXITY_LIMIT" env-default:"1000"`
+	DepthLimit      int `yaml:"depth_limit" env:"DEPTH_LIMIT" env-default:"10"`
+	// cost of the list fields without limit argument (posts)
+	DefaultListSize int `yaml:"default_list_size" env:"DEFAULT_LIST_SIZE" env-default:"100"`
+	// always turned on in development
+	Playground    bool `yaml:"playground" env:"PLAYGROUND"`
+	Introspection bool `yaml:"introspection" env:"INTROSPECTION"`
+}
+
+type PersistedQueries struct {
+	// number of cached queries, 0 turns off automatic persisted queries
+	CacheSize int `yaml:"cache_size" env:"CACHE_SIZE" env-default:"1000"`
+	// path to the manifest of allowed operations (apollo persisted query manifest format);
+	// if set, only operations from the manifest are accepted
+	Manifest string `yaml:"manifest" env:"MANIFEST"`
+}
+
+// token bucket limits per authenticated user (or client ip for anonymous requests)
+type RateLimit struct {
+	CreatePost    Bucket `yaml:"create_post" env-prefix:"CREATE_POST_"`
+	CreateComment Bucket `yaml:"create_comment" env-prefix:"CREATE_COMMENT_"`
+	Subscribe     Bucket `yaml:"subscribe" env-prefix:"SUBSCRIBE_"`
+	Follow        Bucket `yaml:"follow" env-prefix:"FOLLOW_"`
+}
 
-	path := "./config/config.yml"
+type Bucket struct {
+	// tokens per second, 0 turns off the limit
+	Rate  float64 `yaml:"rate" env:"RATE"`
+	Burst int     `yaml:"burst" env:"BURST"`
+}
+
+// OpenTelemetry traces export
+type Tracing struct {
+	// none, otlp (OTLP over http) or file
+	Exporter string `yaml:"exporter" env:"EXPORTER" env-default:"none"`
+	// OTLP collector host:port
+	Endpoint string `yaml:"endpoint" env:"ENDPOINT" env-default:"localhost:4318"`
+	Insecure bool   `yaml:"insecure" env:"INSECURE"`
+	// output file of the file exporter
+	File        string  `yaml:"file" env:"FILE" env-default:"traces.json"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"`
+}
+
+type Logging struct {
+	// debug, info, warn or error
+	Level string `yaml:"level" env:"LEVEL" env-default:"info"`
+	// json or text
+	Format string `yaml:"format" env:"FORMAT" env-default:"json"`
+	// operations longer than threshold are logged with the full query, 0 turns off slow operations log
+	SlowOperationThreshold time.Duration `yaml:"slow_operation_threshold" env:"SLOW_OPERATION_THRESHOLD" env-default:"1s"`
+	// variables containing any of these names (case-insensitive) are redacted in logs
+	RedactVariables []string `yaml:"redact_variables" env:"REDACT_VARIABLES" env-default:"password,token,secret"`
+}
+
+// Load reads config files in order (values of the next file override the previous ones),
+// then environment variables and defaults
+func Load(paths ...string) (*Config, error) {
 	var cfg Config
-	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config %s", path)
+	// keys set in the files, defaults are applied only to missing keys
+	keys := make(map[string]any)
+	for _, path := range paths {
+		if err := parseFile(path, &cfg, keys); err != nil {
+			return nil, err
+		}
+	}
+	files := cfg
+
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to read config from environment: %w", err)
 	}
+	// cleanenv sets env-default into every zero field, so zero values set in the files are restored
+	restoreZeros(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(files), keys, "")
 
-	cfg.IsMemoty = isMemory
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
+
+func parseFile(path string, cfg *Config, keys map[string]any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config %s: %w", path, err)
+	}
+
+	if err = cleanenv.ParseYAML(bytes.NewReader(data), cfg); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	var fileKeys map[string]any
+	if err = yaml.Unmarshal(data, &fileKeys); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	mergeKeys(keys, fileKeys)
+	return nil
+}
+
+func mergeKeys(dst, src map[string]any) {
+	for key, value := range src {
+		nested, ok := value.(map[string]any)
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		if prev, ok := dst[key].(map[string]any); ok {
+			mergeKeys(prev, nested)
+		} else {
+			dst[key] = nested
+		}
+	}
+}
+
+// sets fields of cfg, which are zero in files and set by keys, to zero unless environment variable of the field is set
+func restoreZeros(cfg, files reflect.Value, keys map[string]any, envPrefix string) {
+	t := cfg.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		value, ok := keys[name]
+		if !ok {
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok && field.Type.Kind() == reflect.Struct {
+			restoreZeros(cfg.Field(i), files.Field(i), nested, envPrefix+field.Tag.Get("env-prefix"))
+			continue
+		}
+		if env := field.Tag.Get("env"); env != "" {
+			if _, ok := os.LookupEnv(envPrefix + env); ok {
+				continue
+			}
+		}
+		if files.Field(i).IsZero() {
+			cfg.Field(i).Set(files.Field(i))
+		}
+	}
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Environment != EnvDevelopment && c.Environment != EnvProduction {
+		errs = append(errs, fmt.Errorf("unknown environment %q, expected development or production", c.Environment))
+	}
+
+	if c.Port == "" {
+		errs = append(errs, errors.New("port is required"))
+	}
+
+	if tls := c.HTTP.TLS; tls.Enabled() {
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			errs = append(errs, errors.New("http.tls.cert_file and http.tls.key_file must be set together"))
+		}
+		switch tls.ClientAuth {
+		case "", "none":
+		case "request", "require":
+			if tls.ClientCAFile == "" {
+				errs = append(errs, errors.New("http.tls.client_ca_file is required for client auth"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown http.tls.client_auth %q, expected none, request or require", tls.ClientAuth))
+		}
+	}
+
+	for _, cidr := range c.Auth.TrustedProxies {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid auth.trusted_proxies network %q, expected CIDR", cidr))
+		}
+	}
+
+	switch c.Storage.Driver {
+	case DriverMemory:
+	case DriverPostgres:
+		if c.Storage.Postgres.URL == "" {
+			errs = append(errs, errors.New("storage.postgres.url is required for postgres driver"))
+		}
+		if c.Storage.Postgres.QueryTimeout <= 0 {
+			errs = append(errs, errors.New("storage.postgres.query_timeout must be positive"))
+		}
+		if c.Storage.Postgres.MaxConns < 0 || c.Storage.Postgres.MinConns < 0 {
+			errs = append(errs, errors.New("storage.postgres pool size must not be negative"))
+		}
+		if c.Storage.Postgres.MaxConns > 0 && c.Storage.Postgres.MinConns > c.Storage.Postgres.MaxConns {
+			errs = append(errs, errors.New("storage.postgres.min_conns is greater than max_conns"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown storage.driver %q, expected memory or postgres", c.Storage.Driver))
+	}
+
+	if c.Idempotency.Window < 0 {
+		errs = append(errs, errors.New("idempotency.window must not be negative"))
+	}
+
+	if c.Feed.SnapshotSize <= 0 || c.Feed.SnapshotTTL <= 0 {
+		errs = append(errs, errors.New("feed.snapshot_size and feed.snapshot_ttl must be positive"))
+	}
+	if c.Feed.SnapshotInterval <= 0 || c.Feed.SnapshotInterval >= c.Feed.SnapshotTTL {
+		errs = append(errs, errors.New("feed.snapshot_interval must be positive and less than feed.snapshot_ttl"))
+	}
+	switch c.Feed.HomeFanOut {
+	case FanOutWrite, FanOutRead:
+	default:
+		errs = append(errs, fmt.Errorf("unknown feed.home_fan_out %q", c.Feed.HomeFanOut))
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "none", "otlp", "file":
+	default:
+		errs = append(errs, fmt.Errorf("unknown tracing.exporter %q", c.Tracing.Exporter))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+func (c *Config) PlaygroundEnabled() bool {
+	return c.GraphQL.Playground || c.Environment == EnvDevelopment
+}
+
+func (c *Config) IntrospectionEnabled() bool {
+	return c.GraphQL.Introspection || c.Environment == EnvDevelopment
+}
+
+// Redacted returns copy of the config without secrets (for printing)
+func (c Config) Redacted() Config {
+	c.Storage.Postgres.URL = redactURL(c.Storage.Postgres.URL)
+	return c
+}
+
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		// do not print unparseable value, it can contain password
+		return "xxxxx"
+	}
+	return u.Redacted()
+}
@@ -1,11 +1,58 @@
 package entity
 
+import "time"
+
 type Comment struct {
 	ID              int64
 	Text            string
 	ParentCommentID *int64
 	PostID          int64
 	UserID          int64
+	// increased by every change of the comment
+	Version int64
+	// number of votes, votes do not change the version
+	Upvotes   int64
+	Downvotes int64
+	// number of direct replies, kept by storage
+	ReplyCount int64
+	// idempotency key of the create mutation, empty if not set
+	ClientMutationID string
+}
+
+// order of sibling comments
+const (
+	SortTree          = "tree"
+	SortTop           = "top"
+	SortNew           = "new"
+	SortControversial = "controversial"
+)
+
+// comment with the nearest ancestors (the farthest one first) and the first comments of its subtree in the TREE order
+type CommentContext struct {
+	Comment     Comment
+	Ancestors   []*Comment
+	Descendants []*Comment
+}
+
+// vote values, VoteNone removes the vote
+const (
+	VoteUp   = 1
+	VoteDown = -1
+	VoteNone = 0
+)
+
+// vote of the user for the comment
+type Vote struct {
+	CommentID int64
+	UserID    int64
+	Value     int
+}
+
+// comment of the batch, parent can be a comment saved earlier in the same batch
+type BatchComment struct {
+	Comment
+	// index of the parent comment in the batch (instead of ParentCommentID)
+	ParentIndex *int
 }
 
 type Post struct {
@@ -13,4 +60,158 @@ type Post struct {
 	Text        string
 	User        int64
 	CommentsOFF bool
+	// increased by every change of the post
+	Version int64
+	// number of comments and time of the newest comment (creation time of the post without comments), kept by storage
+	CommentCount   int64
+	LastActivityAt time.Time
+	// idempotency key of the create mutation, empty if not set
+	ClientMutationID string
+}
+
+// version of the created post (comment)
+const FirstVersion = 1
+
+// new text of the post (comment) by the author or a moderator, applied only if the current version equals ExpectedVersion
+type Edit struct {
+	ID              int64
+	UserID          int64
+	Text            string
+	ExpectedVersion int64
+	// moderators can edit posts and comments of other users
+	Moderator bool
+}
+
+// text of the post (comment) set by the editor, Version is the version of the post (comment) after the change
+type Revision struct {
+	Version   int64
+	Text      string
+	EditorID  int64
+	CreatedAt time.Time
+}
+
+// restore of the revision of the post (PostID is set) or the comment (CommentID is set)
+type Restore struct {
+	PostID          int64
+	CommentID       int64
+	UserID          int64
+	Version         int64
+	ExpectedVersion int64
+}
+
+// types of reaction targets
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+)
+
+// emoji added by the user to the post or the comment
+type Reaction struct {
+	TargetType string
+	TargetID   int64
+	UserID     int64
+	Emoji      string
+}
+
+// number of reactions with the emoji on the target
+type ReactionSummary struct {
+	Emoji            string
+	Count            int
+	ViewerHasReacted bool
+}
+
+// ranking algorithms of the feed
+const (
+	// recent activity (comments and reactions) first
+	FeedHot = "hot"
+	// the most active posts created after Since first
+	FeedTop = "top"
+	// the newest posts first
+	FeedNew = "new"
+)
+
+type FeedQuery struct {
+	Algorithm string
+	// FeedTop ranks posts created after Since
+	Since time.Time
+	Limit int
+}
+
+// ranking of the feed made for the first page, next pages are read from the snapshot
+type FeedSnapshot struct {
+	ID        string
+	PostIDs   []int64
+	ExpiresAt time.Time
+}
+
+// the follower gets posts of the followee in the home feed
+type Follow struct {
+	ID         int64
+	FollowerID int64
+	FolloweeID int64
+	CreatedAt  time.Time
+}
+
+// strategies of the home feed
+const (
+	// new post is copied into timelines of followers of the author
+	FanOutWrite = "write"
+	// home feed is read from posts of the followed users
+	FanOutRead = "read"
+)
+
+// keyset pagination: items are ordered by id (the newest first), AfterID is 0 for the first page
+type Page struct {
+	AfterID int64
+	Limit   int
+}
+
+// reasons of the notification about the new comment
+const (
+	// the comment mentions the user (@id)
+	NotificationMention = "mention"
+	// the comment is a reply to the comment of the user
+	NotificationReply = "reply"
+	// the comment is added to the post of the user
+	NotificationComment = "comment"
+)
+
+// notification of the user about the new comment
+type Notification struct {
+	ID     int64
+	UserID int64
+	Kind   string
+	// author of the comment
+	ActorID   int64
+	PostID    int64
+	CommentID int64
+	Read      bool
+	CreatedAt time.Time
+}
+
+// the user has read comments of the post up to CommentID (comment ids grow with time),
+// CommentID is 0 to read all comments
+type ReadMark struct {
+	UserID    int64
+	PostID    int64
+	CommentID int64
+}
+
+// comments of the post created after the read mark of the user, FirstCommentID is 0 if there are no unread comments
+type Unread struct {
+	Count          int
+	FirstCommentID int64
+}
+
+// number of posts and comments with counters fixed by the reconciliation
+type Reconciled struct {
+	Posts    int64
+	Comments int64
+}
+
+// number of timeline entries added (posts of the followed users, which were not pushed) and removed
+// (posts of the users, who are not followed) by the reconciliation
+type ReconciledTimelines struct {
+	Added   int64
+	Removed int64
 }
@@ -0,0 +1,79 @@
+// Package loader batches loads of the same kind made by concurrently executed resolvers into one call.
+package loader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// default time to collect keys of the batch
+const DefaultWait = time.Millisecond
+
+// Fetch returns values of the keys, missing keys get zero value
+type Fetch[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects keys requested during wait and loads them with one fetch.
+// Values are not cached between batches, so a long-lived operation (subscription) always gets current values.
+type Loader[K comparable, V any] struct {
+	// batches are fetched with the context of the operation, so a deadline (cancel) of the field,
+	// which starts the batch, does not fail loads of the other fields
+	ctx   context.Context
+	fetch Fetch[K, V]
+	wait  time.Duration
+
+	mu    sync.Mutex
+	batch *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	seen   map[K]bool
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+// New returns loader of the operation, ctx is the context of the operation
+func New[K comparable, V any](ctx context.Context, fetch Fetch[K, V], wait time.Duration) *Loader[K, V] {
+	return &Loader[K, V]{ctx: ctx, fetch: fetch, wait: wait}
+}
+
+// Load adds the key to the current batch and waits for the batch result or until ctx of the field is done
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{seen: make(map[K]bool), done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+	l.mu.Unlock()
+
+	var zero V
+	select {
+	case <-b.done:
+		if b.err != nil {
+			return zero, b.err
+		}
+		return b.values[key], nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	// new loads start the next batch
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	b.values, b.err = l.fetch(l.ctx, b.keys)
+	close(b.done)
+}
@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Batch(t *testing.T) {
+	var mu sync.Mutex
+	var calls [][]int
+	l := New(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		mu.Unlock()
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key%2 == 0 {
+				values[key] = "even"
+			}
+		}
+		return values, nil
+	}, 10*time.Millisecond)
+
+	keys := []int{1, 2, 3, 2}
+	values := make([]string, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i, key int) {
+			defer wg.Done()
+			value, err := l.Load(context.Background(), key)
+			require.NoError(t, err)
+			values[i] = value
+		}(i, key)
+	}
+	wg.Wait()
+
+	require.Equal(t, []string{"", "even", "", "even"}, values)
+	require.Len(t, calls, 1)
+	require.ElementsMatch(t, []int{1, 2, 3}, calls[0])
+
+	// values are not cached, the next load is a new batch
+	_, err := l.Load(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+}
+
+func TestLoader_Error(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	l := New(context.Background(), func(ctx context.Context, keys []int) (map[int]int, error) {
+		return nil, errFetch
+	}, time.Millisecond)
+
+	_, err := l.Load(context.Background(), 1)
+	require.ErrorIs(t, err, errFetch)
+}
+
+func TestLoader_ContextCanceled(t *testing.T) {
+	l := New(context.Background(), func(ctx context.Context, keys []int) (map[int]int, error) {
+		return map[int]int{}, nil
+	}, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := l.Load(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoader_OperationContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "operation")
+	l := New(ctx, func(ctx context.Context, keys []int) (map[int]string, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		value, _ := ctx.Value(key{}).(string)
+		return map[int]string{keys[0]: value}, nil
+	}, 10*time.Millisecond)
+
+	// the field, which starts the batch, is canceled before the fetch, but the other field of the batch gets the value
+	fieldCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := l.Load(fieldCtx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+
+	value, err := l.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, "operation", value)
+}
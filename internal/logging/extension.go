@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+const redacted = "[REDACTED]"
+
+// Extension logs every operation with its variables (sensitive values are redacted), duration and error codes.
+// Operations longer than SlowThreshold are logged once more with the full query.
+type Extension struct {
+	Logger        *slog.Logger
+	SlowThreshold time.Duration
+	// names of the variables (case-insensitive substrings) to redact
+	Redact []string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+func (e Extension) ExtensionName() string {
+	return "Logging"
+}
+
+func (e Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	rc := graphql.GetOperationContext(ctx)
+
+	resp := next(ctx)
+
+	name, opType := rc.OperationName, "unknown"
+	if rc.Operation != nil {
+		name, opType = rc.Operation.Name, string(rc.Operation.Operation)
+	}
+	if name == "" {
+		name = "anonymous"
+	}
+	duration := time.Since(rc.Stats.OperationStart)
+
+	attrs := []slog.Attr{
+		slog.String("operation", name),
+		slog.String("type", opType),
+		slog.Any("variables", e.redact(rc.Variables)),
+		slog.Duration("duration", duration),
+	}
+	level := slog.LevelInfo
+	if resp != nil && len(resp.Errors) > 0 {
+		codes := errorCodes(resp)
+		attrs = append(attrs, slog.String("error_code", strings.Join(codes, ",")))
+		level = slog.LevelWarn
+		for _, code := range codes {
+			if code == "INTERNAL" || code == "UNKNOWN" {
+				level = slog.LevelError
+			}
+		}
+	}
+
+	// subscription responses are events of the long living operation
+	if opType == "subscription" {
+		e.Logger.LogAttrs(ctx, slog.LevelDebug, "subscription event", attrs...)
+		return resp
+	}
+
+	e.Logger.LogAttrs(ctx, level, "operation", attrs...)
+	if e.SlowThreshold > 0 && duration >= e.SlowThreshold {
+		e.Logger.LogAttrs(ctx, slog.LevelWarn, "slow operation", append(attrs, slog.String("query", rc.RawQuery))...)
+	}
+
+	return resp
+}
+
+func errorCodes(resp *graphql.Response) []string {
+	codes := make([]string, 0, len(resp.Errors))
+	seen := make(map[string]bool)
+	for _, err := range resp.Errors {
+		code, ok := err.Extensions["code"].(string)
+		if !ok {
+			code = "UNKNOWN"
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+func (e Extension) redact(variables map[string]any) map[string]any {
+	if variables == nil {
+		return nil
+	}
+	result := make(map[string]any, len(variables))
+	for k, v := range variables {
+		result[k] = e.redactValue(k, v)
+	}
+	return result
+}
+
+func (e Extension) redactValue(name string, value any) any {
+	lower := strings.ToLower(name)
+	for _, r := range e.Redact {
+		if r != "" && strings.Contains(lower, strings.ToLower(r)) {
+			return redacted
+		}
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return e.redact(v)
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = e.redactValue(name, item)
+		}
+		return list
+	default:
+		return v
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/config"
+)
+
+func TestNew_ContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, config.Logging{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	ctx := auth.WithUserID(WithRequestID(context.Background(), "42"), 7)
+	logger.InfoContext(ctx, "message")
+	logger.DebugContext(ctx, "debug message")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "message", record["msg"])
+	assert.Equal(t, "42", record["request_id"])
+	assert.Equal(t, float64(7), record["user_id"])
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, config.Logging{Level: "verbose", Format: "json"})
+	assert.Error(t, err)
+	_, err = New(&bytes.Buffer{}, config.Logging{Level: "info", Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var requestID string
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID, _ = RequestID(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/query", nil)
+	r.Header.Set(RequestIDHeader, "request-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "request-1", requestID)
+	assert.Equal(t, "request-1", w.Header().Get(RequestIDHeader))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/query", nil))
+	assert.Len(t, requestID, 32)
+	assert.Equal(t, requestID, w.Header().Get(RequestIDHeader))
+}
+
+func TestExtension_Redact(t *testing.T) {
+	e := Extension{Logger: slog.Default(), Redact: []string{"password", "token"}}
+
+	variables := map[string]any{
+		"text":        "awesome post",
+		"newPassword": "qwerty",
+		"input": map[string]any{
+			"userID":    "1",
+			"authToken": "secret",
+		},
+		"tokens": []any{"a", "b"},
+	}
+
+	assert.Equal(t, map[string]any{
+		"text":        "awesome post",
+		"newPassword": redacted,
+		"input": map[string]any{
+			"userID":    "1",
+			"authToken": redacted,
+		},
+		"tokens": redacted,
+	}, e.redact(variables))
+}
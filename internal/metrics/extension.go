@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+const (
+	anonymousOperation = "anonymous"
+	unknownKind        = "UNKNOWN"
+)
+
+// Extension returns gqlgen handler extension which collects operation and resolver metrics
+func (m *Metrics) Extension() graphql.HandlerExtension {
+	return extension{m: m}
+}
+
+type extension struct {
+	m *Metrics
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = extension{}
+
+func (e extension) ExtensionName() string {
+	return "Metrics"
+}
+
+func (e extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	name, opType := operationLabels(graphql.GetOperationContext(ctx))
+	e.m.operations.WithLabelValues(name, opType).Inc()
+
+	return next(ctx)
+}
+
+// subscription responses are events of the long living operation, so their duration is not observed
+func (e extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	rc := graphql.GetOperationContext(ctx)
+	name, opType := operationLabels(rc)
+
+	resp := next(ctx)
+
+	if opType != "subscription" {
+		e.m.operationDuration.WithLabelValues(name, opType).Observe(time.Since(rc.Stats.OperationStart).Seconds())
+	}
+
+	return resp
+}
+
+func (e extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	if err != nil {
+		fc := graphql.GetFieldContext(ctx)
+		e.m.resolverErrors.WithLabelValues(fc.Object+"."+fc.Field.Name, errorKind(err)).Inc()
+	}
+
+	return res, err
+}
+
+func operationLabels(rc *graphql.OperationContext) (string, string) {
+	name, opType := rc.OperationName, "unknown"
+	if rc.Operation != nil {
+		name, opType = rc.Operation.Name, string(rc.Operation.Operation)
+	}
+	if name == "" {
+		name = anonymousOperation
+	}
+	return name, opType
+}
+
+// kind of the service error, or code of the GraphQL error (e.g. RATE_LIMITED)
+func errorKind(err error) string {
+	if kind := service.ErrorKind(err); kind != "" {
+		return kind
+	}
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		if code, ok := gqlErr.Extensions["code"].(string); ok {
+			return code
+		}
+	}
+	return unknownKind
+}
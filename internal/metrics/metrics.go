@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/dkrasnykh/graphql-app/internal/subscription"
+)
+
+const namespace = "graphql_app"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	operations        *prometheus.CounterVec
+	operationDuration *prometheus.HistogramVec
+	resolverErrors    *prometheus.CounterVec
+	storageDuration   *prometheus.HistogramVec
+}
+
+func New(subscriptions *subscription.Subscription) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "Number of GraphQL operations.",
+		}, []string{"operation", "type"}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of GraphQL queries and mutations.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "type"}),
+		resolverErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resolver_errors_total",
+			Help:      "Number of resolver errors grouped by service error kind.",
+		}, []string{"field", "kind"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_call_duration_seconds",
+			Help:      "Duration of storage calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "status"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.operations,
+		m.operationDuration,
+		m.resolverErrors,
+		m.storageDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "subscriptions_active",
+			Help:      "Number of active comment subscriptions.",
+		}, func() float64 {
+			return float64(subscriptions.Stats().Subscriptions)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "subscription_observed_posts",
+			Help:      "Number of posts with at least one subscriber.",
+		}, func() float64 {
+			return float64(subscriptions.Stats().ObservedPosts)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "subscription_subscribers",
+			Help:      "Number of post subscribers (every subscription is counted for each of its posts).",
+		}, func() float64 {
+			return float64(subscriptions.Stats().Subscribers)
+		}),
+	)
+
+	return m
+}
+
+// MustRegister registers additional collectors (e.g. connection pool statistics)
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// Handler returns http handler for /metrics endpoint
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,217 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+// Storager wraps storage and observes duration of the storage calls
+func (m *Metrics) Storager(s service.Storager) service.Storager {
+	return &storager{Storager: s, m: m}
+}
+
+type storager struct {
+	service.Storager
+	m *Metrics
+}
+
+func (s *storager) observe(method string, start time.Time, err *error) {
+	status := "ok"
+	if *err != nil {
+		status = "error"
+	}
+	s.m.storageDuration.WithLabelValues(method, status).Observe(time.Since(start).Seconds())
+}
+
+func (s *storager) SavePost(ctx context.Context, post entity.Post) (saved entity.Post, err error) {
+	defer s.observe("SavePost", time.Now(), &err)
+	return s.Storager.SavePost(ctx, post)
+}
+
+func (s *storager) SavePostWithKey(ctx context.Context, post entity.Post, window time.Duration) (saved entity.Post, created bool, err error) {
+	defer s.observe("SavePostWithKey", time.Now(), &err)
+	return s.Storager.SavePostWithKey(ctx, post, window)
+}
+
+func (s *storager) SavePosts(ctx context.Context, posts []entity.Post) (saved []entity.Post, err error) {
+	defer s.observe("SavePosts", time.Now(), &err)
+	return s.Storager.SavePosts(ctx, posts)
+}
+
+func (s *storager) PostByID(ctx context.Context, id int64) (post *entity.Post, err error) {
+	defer s.observe("PostByID", time.Now(), &err)
+	return s.Storager.PostByID(ctx, id)
+}
+
+func (s *storager) AllPosts(ctx context.Context) (posts []*entity.Post, err error) {
+	defer s.observe("AllPosts", time.Now(), &err)
+	return s.Storager.AllPosts(ctx)
+}
+
+func (s *storager) UpdatePost(ctx context.Context, edit entity.Edit) (post *entity.Post, err error) {
+	defer s.observe("UpdatePost", time.Now(), &err)
+	return s.Storager.UpdatePost(ctx, edit)
+}
+
+func (s *storager) PostRevisions(ctx context.Context, postID int64) (revisions []entity.Revision, err error) {
+	defer s.observe("PostRevisions", time.Now(), &err)
+	return s.Storager.PostRevisions(ctx, postID)
+}
+
+func (s *storager) DisableComments(ctx context.Context, userID int64, postID int64) (err error) {
+	defer s.observe("DisableComments", time.Now(), &err)
+	return s.Storager.DisableComments(ctx, userID, postID)
+}
+
+func (s *storager) PostsByIDs(ctx context.Context, ids []int64) (posts []*entity.Post, err error) {
+	defer s.observe("PostsByIDs", time.Now(), &err)
+	return s.Storager.PostsByIDs(ctx, ids)
+}
+
+func (s *storager) RankedPostIDs(ctx context.Context, query entity.FeedQuery) (ids []int64, err error) {
+	defer s.observe("RankedPostIDs", time.Now(), &err)
+	return s.Storager.RankedPostIDs(ctx, query)
+}
+
+func (s *storager) SaveFeedSnapshot(ctx context.Context, snapshot entity.FeedSnapshot) (saved *entity.FeedSnapshot, err error) {
+	defer s.observe("SaveFeedSnapshot", time.Now(), &err)
+	return s.Storager.SaveFeedSnapshot(ctx, snapshot)
+}
+
+func (s *storager) FeedSnapshot(ctx context.Context, id string) (snapshot *entity.FeedSnapshot, err error) {
+	defer s.observe("FeedSnapshot", time.Now(), &err)
+	return s.Storager.FeedSnapshot(ctx, id)
+}
+
+func (s *storager) DeleteExpiredFeedSnapshots(ctx context.Context) (err error) {
+	defer s.observe("DeleteExpiredFeedSnapshots", time.Now(), &err)
+	return s.Storager.DeleteExpiredFeedSnapshots(ctx)
+}
+
+func (s *storager) Follow(ctx context.Context, follow entity.Follow, fanOut string) (followed bool, err error) {
+	defer s.observe("Follow", time.Now(), &err)
+	return s.Storager.Follow(ctx, follow, fanOut)
+}
+
+func (s *storager) Unfollow(ctx context.Context, follow entity.Follow, fanOut string) (unfollowed bool, err error) {
+	defer s.observe("Unfollow", time.Now(), &err)
+	return s.Storager.Unfollow(ctx, follow, fanOut)
+}
+
+func (s *storager) Followers(ctx context.Context, userID int64, page entity.Page) (follows []entity.Follow, err error) {
+	defer s.observe("Followers", time.Now(), &err)
+	return s.Storager.Followers(ctx, userID, page)
+}
+
+func (s *storager) Following(ctx context.Context, userID int64, page entity.Page) (follows []entity.Follow, err error) {
+	defer s.observe("Following", time.Now(), &err)
+	return s.Storager.Following(ctx, userID, page)
+}
+
+func (s *storager) FollowerIDs(ctx context.Context, userID int64) (ids []int64, err error) {
+	defer s.observe("FollowerIDs", time.Now(), &err)
+	return s.Storager.FollowerIDs(ctx, userID)
+}
+
+func (s *storager) PushToTimelines(ctx context.Context, post entity.Post) (err error) {
+	defer s.observe("PushToTimelines", time.Now(), &err)
+	return s.Storager.PushToTimelines(ctx, post)
+}
+
+func (s *storager) HomeFeed(ctx context.Context, userID int64, page entity.Page, fanOut string) (posts []*entity.Post, err error) {
+	defer s.observe("HomeFeed", time.Now(), &err)
+	return s.Storager.HomeFeed(ctx, userID, page, fanOut)
+}
+
+func (s *storager) SaveNotifications(ctx context.Context, notifications []entity.Notification) (saved []entity.Notification, err error) {
+	defer s.observe("SaveNotifications", time.Now(), &err)
+	return s.Storager.SaveNotifications(ctx, notifications)
+}
+
+func (s *storager) Notifications(ctx context.Context, userID int64, page entity.Page, unreadOnly bool) (notifications []entity.Notification, err error) {
+	defer s.observe("Notifications", time.Now(), &err)
+	return s.Storager.Notifications(ctx, userID, page, unreadOnly)
+}
+
+func (s *storager) MarkNotificationsRead(ctx context.Context, userID int64, ids []int64) (marked int, err error) {
+	defer s.observe("MarkNotificationsRead", time.Now(), &err)
+	return s.Storager.MarkNotificationsRead(ctx, userID, ids)
+}
+
+func (s *storager) MarkPostRead(ctx context.Context, mark entity.ReadMark) (err error) {
+	defer s.observe("MarkPostRead", time.Now(), &err)
+	return s.Storager.MarkPostRead(ctx, mark)
+}
+
+func (s *storager) UnreadComments(ctx context.Context, userID int64, postIDs []int64) (unread map[int64]entity.Unread, err error) {
+	defer s.observe("UnreadComments", time.Now(), &err)
+	return s.Storager.UnreadComments(ctx, userID, postIDs)
+}
+
+func (s *storager) SaveComment(ctx context.Context, comment entity.Comment) (id int64, err error) {
+	defer s.observe("SaveComment", time.Now(), &err)
+	return s.Storager.SaveComment(ctx, comment)
+}
+
+func (s *storager) SaveCommentWithKey(ctx context.Context, comment entity.Comment, window time.Duration) (saved entity.Comment, created bool, err error) {
+	defer s.observe("SaveCommentWithKey", time.Now(), &err)
+	return s.Storager.SaveCommentWithKey(ctx, comment, window)
+}
+
+func (s *storager) SaveComments(ctx context.Context, comments []entity.BatchComment) (ids []int64, err error) {
+	defer s.observe("SaveComments", time.Now(), &err)
+	return s.Storager.SaveComments(ctx, comments)
+}
+
+func (s *storager) AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort string) (comments []*entity.Comment, err error) {
+	defer s.observe("AllComments", time.Now(), &err)
+	return s.Storager.AllComments(ctx, postID, limit, offset, sort)
+}
+
+func (s *storager) CommentsAfter(ctx context.Context, postID int64, afterID int64, inclusive bool, limit *int, offset *int) (comments []*entity.Comment, err error) {
+	defer s.observe("CommentsAfter", time.Now(), &err)
+	return s.Storager.CommentsAfter(ctx, postID, afterID, inclusive, limit, offset)
+}
+
+func (s *storager) CommentContext(ctx context.Context, id int64, ancestors int, descendants int) (thread *entity.CommentContext, err error) {
+	defer s.observe("CommentContext", time.Now(), &err)
+	return s.Storager.CommentContext(ctx, id, ancestors, descendants)
+}
+
+func (s *storager) Vote(ctx context.Context, vote entity.Vote) (comment *entity.Comment, err error) {
+	defer s.observe("Vote", time.Now(), &err)
+	return s.Storager.Vote(ctx, vote)
+}
+
+func (s *storager) UpdateComment(ctx context.Context, edit entity.Edit) (comment *entity.Comment, err error) {
+	defer s.observe("UpdateComment", time.Now(), &err)
+	return s.Storager.UpdateComment(ctx, edit)
+}
+
+func (s *storager) CommentRevisions(ctx context.Context, commentID int64) (revisions []entity.Revision, err error) {
+	defer s.observe("CommentRevisions", time.Now(), &err)
+	return s.Storager.CommentRevisions(ctx, commentID)
+}
+
+func (s *storager) CommentByID(ctx context.Context, id int64) (comment *entity.Comment, err error) {
+	defer s.observe("CommentByID", time.Now(), &err)
+	return s.Storager.CommentByID(ctx, id)
+}
+
+func (s *storager) AddReaction(ctx context.Context, reaction entity.Reaction) (added bool, err error) {
+	defer s.observe("AddReaction", time.Now(), &err)
+	return s.Storager.AddReaction(ctx, reaction)
+}
+
+func (s *storager) RemoveReaction(ctx context.Context, reaction entity.Reaction) (removed bool, err error) {
+	defer s.observe("RemoveReaction", time.Now(), &err)
+	return s.Storager.RemoveReaction(ctx, reaction)
+}
+
+func (s *storager) ReactionSummaries(ctx context.Context, targetType string, targetIDs []int64, viewerID *int64) (summaries map[int64][]entity.ReactionSummary, err error) {
+	defer s.observe("ReactionSummaries", time.Now(), &err)
+	return s.Storager.ReactionSummaries(ctx, targetType, targetIDs, viewerID)
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"reflect"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+)
+
+const ErrRateLimited = "RATE_LIMITED"
+
+type clientIPKey struct{}
+
+// Middleware puts client ip address into request context,
+// for requests of the trusted networks (API gateway) it is taken from the forwarding headers
+func Middleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := auth.ClientIP(r, trusted)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// Extension limits calls of the root fields separately for every authenticated user (or client ip for anonymous requests).
+type Extension struct {
+	// map[Type.field]rule, e.g. "Mutation.createComment"
+	Rules map[string]Rule
+}
+
+// Rule takes one token of the limiter for every call of the field or, with CountArg,
+// one token for every item of the list argument (batch mutations share the limiter with single ones)
+type Rule struct {
+	Limiter  *Limiter
+	CountArg string
+}
+
+var _ interface {
+	graphql.FieldInterceptor
+	graphql.HandlerExtension
+} = Extension{}
+
+func (e Extension) ExtensionName() string {
+	return "RateLimit"
+}
+
+func (e Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil {
+		return next(ctx)
+	}
+	rule, ok := e.Rules[fc.Object+"."+fc.Field.Name]
+	if !ok {
+		return next(ctx)
+	}
+
+	n := rule.count(fc)
+	if n > rule.Limiter.Burst() {
+		return nil, &gqlerror.Error{
+			Message:    fmt.Sprintf("%s of %d items exceeds the limit of %d items, split it into smaller batches", fc.Field.Name, n, rule.Limiter.Burst()),
+			Path:       fc.Path(),
+			Extensions: map[string]any{"code": ErrRateLimited},
+		}
+	}
+	allowed, retryAfter := rule.Limiter.AllowN(clientKey(ctx), n)
+	if !allowed {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		return nil, &gqlerror.Error{
+			Message: fmt.Sprintf("too many %s requests, retry after %d seconds", fc.Field.Name, seconds),
+			Path:    fc.Path(),
+			Extensions: map[string]any{
+				"code":       ErrRateLimited,
+				"retryAfter": seconds,
+			},
+		}
+	}
+
+	return next(ctx)
+}
+
+// number of tokens of the call
+func (r Rule) count(fc *graphql.FieldContext) int {
+	if r.CountArg == "" {
+		return 1
+	}
+	list := reflect.ValueOf(fc.Args[r.CountArg])
+	if list.Kind() != reflect.Slice {
+		return 1
+	}
+	return max(list.Len(), 1)
+}
+
+func clientKey(ctx context.Context) string {
+	if userID, ok := auth.UserID(ctx); ok {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return "ip:" + ip
+}
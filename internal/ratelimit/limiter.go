@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idle buckets are refilled up to burst, so they can be removed without changing limiter behavior
+const cleanupInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is in memory token bucket limiter with a separate bucket for every key.
+// Bucket is refilled with rate tokens per second up to burst tokens.
+type Limiter struct {
+	mu sync.Mutex
+
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	cleaned time.Time
+	now     func() time.Time
+}
+
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		mu:      sync.Mutex{},
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*bucket),
+		cleaned: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// Allow takes one token from the key bucket.
+// If the bucket is empty, returns false and the time until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	return l.AllowN(key, 1)
+}
+
+// AllowN takes n tokens from the key bucket, nothing is taken if the bucket has fewer tokens.
+// n greater than burst is never allowed (retry after is 0).
+func (l *Limiter) AllowN(key string, n int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if float64(n) > l.burst {
+		return false, 0
+	}
+
+	now := l.now()
+	l.cleanup(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+
+	if b.tokens < float64(n) {
+		retryAfter := time.Duration(math.Ceil((float64(n) - b.tokens) / l.rate * float64(time.Second)))
+		return false, retryAfter
+	}
+	b.tokens -= float64(n)
+
+	return true, 0
+}
+
+// Burst returns max number of tokens taken at once
+func (l *Limiter) Burst() int {
+	return int(l.burst)
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+}
+
+// removes full buckets
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.cleaned) < cleanupInterval {
+		return
+	}
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.cleaned = now
+}
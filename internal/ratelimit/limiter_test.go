@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("user:1")
+		assert.True(t, ok)
+	}
+
+	ok, retryAfter := l.Allow("user:1")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// other keys have own buckets
+	ok, _ = l.Allow("user:2")
+	assert.True(t, ok)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("user:1")
+	assert.True(t, ok)
+	ok, _ = l.Allow("user:1")
+	assert.False(t, ok)
+}
+
+func TestLimiter_AllowN(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(1, 5)
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.AllowN("user:1", 4)
+	assert.True(t, ok)
+	// nothing is taken if the bucket has fewer tokens
+	ok, retryAfter := l.AllowN("user:1", 3)
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+	ok, _ = l.AllowN("user:1", 1)
+	assert.True(t, ok)
+
+	// more than burst is never allowed
+	ok, retryAfter = l.AllowN("user:2", 6)
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(0), retryAfter)
+}
+
+func TestLimiter_Cleanup(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(1, 1)
+	l.now = func() time.Time { return now }
+	l.cleaned = now
+
+	l.Allow("user:1")
+	l.Allow("user:2")
+	assert.Len(t, l.buckets, 2)
+
+	now = now.Add(cleanupInterval)
+	l.Allow("user:3")
+	assert.Len(t, l.buckets, 1)
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errOperationNotAllowed    = "OPERATION_NOT_ALLOWED"
+	errPersistedQueryNotFound = "PERSISTED_QUERY_NOT_FOUND"
+)
+
+func init() {
+	errcode.RegisterErrorType(errOperationNotAllowed, errcode.KindProtocol)
+}
+
+// manifest of allowed operations (apollo persisted query manifest format)
+type manifest struct {
+	Operations []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Body string `json:"body"`
+	} `json:"operations"`
+}
+
+// AllowList accepts only operations from the manifest loaded at startup (strict mode).
+// Clients can send either the full query text or only its sha256 hash (persisted query extension).
+type AllowList struct {
+	// map[sha256 hash]query
+	operations map[string]string
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = &AllowList{}
+
+func LoadAllowList(path string) (*AllowList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operations manifest %s: %w", path, err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse operations manifest %s: %w", path, err)
+	}
+
+	operations := make(map[string]string, len(m.Operations))
+	for _, op := range m.Operations {
+		hash := queryHash(op.Body)
+		if op.ID != "" && op.ID != hash {
+			return nil, fmt.Errorf("operations manifest %s: id of operation %s does not match sha256 hash of the body", path, op.Name)
+		}
+		operations[hash] = op.Body
+	}
+
+	return &AllowList{operations: operations}, nil
+}
+
+func (a *AllowList) ExtensionName() string {
+	return "AllowList"
+}
+
+func (a *AllowList) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (a *AllowList) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if rawParams.Query != "" {
+		if _, ok := a.operations[queryHash(rawParams.Query)]; !ok {
+			err := gqlerror.Errorf("operation is not in the allow-list")
+			errcode.Set(err, errOperationNotAllowed)
+			return err
+		}
+		return nil
+	}
+
+	// client sent only hash of the query
+	persistedQuery, _ := rawParams.Extensions["persistedQuery"].(map[string]any)
+	hash, _ := persistedQuery["sha256Hash"].(string)
+	query, ok := a.operations[hash]
+	if !ok {
+		err := gqlerror.Errorf("PersistedQueryNotFound")
+		errcode.Set(err, errPersistedQueryNotFound)
+		return err
+	}
+	rawParams.Query = query
+
+	return nil
+}
+
+func queryHash(query string) string {
+	b := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(b[:])
+}
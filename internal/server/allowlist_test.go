@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func decodeResponse(t *testing.T, data json.RawMessage) testResponse {
+	t.Helper()
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+	return response
+}
+
+func persistedQuery(hash string) map[string]any {
+	return map[string]any{"persistedQuery": map[string]any{"version": 1, "sha256Hash": hash}}
+}
+
+func TestAutomaticPersistedQuery(t *testing.T) {
+	ts, _ := newTestServer(t, testConfig())
+
+	query := `query { posts { id } }`
+	hash := queryHash(query)
+
+	// the server does not know the hash yet
+	response := decodeResponse(t, postParams(t, ts.URL, map[string]any{"extensions": persistedQuery(hash)}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, errPersistedQueryNotFound, response.Errors[0].Extensions["code"])
+
+	// register query
+	response = decodeResponse(t, postParams(t, ts.URL, map[string]any{"query": query, "extensions": persistedQuery(hash)}))
+	require.Empty(t, response.Errors)
+
+	response = decodeResponse(t, postParams(t, ts.URL, map[string]any{"extensions": persistedQuery(hash)}))
+	require.Empty(t, response.Errors)
+	require.JSONEq(t, `{"posts": []}`, string(response.Data))
+}
+
+func TestAllowList(t *testing.T) {
+	cfg := testConfig()
+	cfg.PersistedQueries.Manifest = filepath.Join("testdata", "manifest.json")
+	ts, _ := newTestServer(t, cfg)
+
+	// operations from the manifest are accepted with full text
+	createPost := `mutation CreatePost($text: String!) { createPost(input: {text: $text, userID: "1"}) { id } }`
+	response := decodeResponse(t, postQuery(t, ts.URL, createPost, "", map[string]any{"text": "awesome post"}))
+	require.Empty(t, response.Errors)
+	require.JSONEq(t, `{"createPost": {"id": "1"}}`, string(response.Data))
+
+	// and with hash only
+	postHash := "1e8912a9f5df4445dd6b974f66afbf8ba8967af3d5019b06a735d40b577be1f6"
+	response = decodeResponse(t, postParams(t, ts.URL, map[string]any{
+		"variables":  map[string]any{"id": "1"},
+		"extensions": persistedQuery(postHash),
+	}))
+	require.Empty(t, response.Errors)
+	require.JSONEq(t, `{"post": {"id": "1", "text": "awesome post"}}`, string(response.Data))
+
+	// other operations are rejected
+	response = decodeResponse(t, postQuery(t, ts.URL, `query { posts { id } }`, "", nil))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, errOperationNotAllowed, response.Errors[0].Extensions["code"])
+
+	// hash of unknown operation can not be registered
+	query := `query { posts { id } }`
+	response = decodeResponse(t, postParams(t, ts.URL, map[string]any{"query": query, "extensions": persistedQuery(queryHash(query))}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, errOperationNotAllowed, response.Errors[0].Extensions["code"])
+
+	response = decodeResponse(t, postParams(t, ts.URL, map[string]any{"extensions": persistedQuery(queryHash(query))}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, errPersistedQueryNotFound, response.Errors[0].Extensions["code"])
+}
+
+func TestLoadAllowList_Error(t *testing.T) {
+	_, err := LoadAllowList(filepath.Join("testdata", "missing.json"))
+	require.Error(t, err)
+}
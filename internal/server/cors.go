@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	corsAllowMethods = "GET, POST, OPTIONS"
+	corsAllowHeaders = "Content-Type, Authorization, X-Request-ID"
+)
+
+// origins is allow-list of browser origins, "*" allows any origin without credentials
+type origins []string
+
+func (o origins) allowed(origin string) bool {
+	listed, wildcard := o.match(origin)
+	return listed || wildcard
+}
+
+// listed is true if the origin is in the list, wildcard is true if the list contains "*"
+func (o origins) match(origin string) (listed bool, wildcard bool) {
+	for _, allowed := range o {
+		if allowed == "*" {
+			wildcard = true
+		} else if strings.EqualFold(allowed, origin) {
+			listed = true
+		}
+	}
+	return listed, wildcard
+}
+
+// checkOrigin is websocket.Upgrader.CheckOrigin,
+// connections without Origin header are not made by browsers and are accepted
+func (o origins) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || o.allowed(origin)
+}
+
+// cors handles preflight requests and sets CORS headers for allowed origins
+func cors(allowed origins, maxAge time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		listed, wildcard := allowed.match(origin)
+		if !listed && !wildcard {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			// browser does not expose the response without CORS headers
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if listed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			// credentialed requests are allowed only for listed origins
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		if !preflight {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+		w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+		if maxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
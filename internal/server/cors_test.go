@@ -0,0 +1,168 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/config"
+)
+
+func corsConfig() *config.Config {
+	cfg := testConfig()
+	cfg.HTTP = config.HTTP{
+		AllowedOrigins:          []string{"https://app.example.com"},
+		CORSMaxAge:              time.Minute,
+		MaxWebsocketMessageSize: 1024,
+	}
+	return cfg
+}
+
+func preflight(t *testing.T, url string, origin string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodOptions, url+"/query", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	ts, _ := newTestServer(t, corsConfig())
+
+	resp := preflight(t, ts.URL, "https://app.example.com")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, corsAllowMethods, resp.Header.Get("Access-Control-Allow-Methods"))
+	require.Equal(t, corsAllowHeaders, resp.Header.Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "60", resp.Header.Get("Access-Control-Max-Age"))
+
+	resp = preflight(t, ts.URL, "https://evil.example.com")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_Request(t *testing.T) {
+	ts, _ := newTestServer(t, corsConfig())
+
+	post := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/query", strings.NewReader(`{"query": "{ posts { id } }"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := post("https://app.example.com")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = post("https://evil.example.com")
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_Wildcard(t *testing.T) {
+	cfg := corsConfig()
+	cfg.HTTP.AllowedOrigins = []string{"https://app.example.com", "*"}
+	ts, _ := newTestServer(t, cfg)
+
+	resp := preflight(t, ts.URL, "https://evil.example.com")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+
+	// listed origin keeps credentials
+	resp = preflight(t, ts.URL, "https://app.example.com")
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+}
+
+func TestWebsocket_Origin(t *testing.T) {
+	ts, _ := newTestServer(t, corsConfig())
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/query"
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+
+	conn, _, err := dialer.Dial(url, http.Header{"Origin": []string{"https://app.example.com"}})
+	require.NoError(t, err)
+	conn.Close()
+
+	_, resp, err := dialer.Dial(url, http.Header{"Origin": []string{"https://evil.example.com"}})
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestWebsocket_MessageTooBig(t *testing.T) {
+	ts, _ := newTestServer(t, corsConfig())
+	conn := dialWebsocket(t, ts.URL)
+
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["1"]}) { id `+strings.Repeat("text ", 300)+`} }`)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		require.True(t, errors.As(err, &closeErr), err)
+		require.Equal(t, websocket.CloseMessageTooBig, closeErr.Code)
+		break
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	f := frameReader{limit: 10}
+
+	// masked text frame of 5 bytes, not final
+	require.NoError(t, f.feed([]byte{0x01, 0x85, 1, 2, 3, 4, 'h', 'e', 'l', 'l', 'o'}))
+	// ping between fragments is not counted
+	require.NoError(t, f.feed([]byte{0x89, 0x84, 1, 2, 3, 4, 'p', 'i', 'n', 'g'}))
+	// final continuation frame of 5 bytes, header is split between reads
+	require.NoError(t, f.feed([]byte{0x80}))
+	require.NoError(t, f.feed([]byte{0x85, 1, 2}))
+	require.NoError(t, f.feed([]byte{3, 4, 'w', 'o', 'r', 'l', 'd'}))
+
+	// unmasked frame with 16-bit payload length
+	require.ErrorIs(t, f.feed([]byte{0x81, 0x7e, 0x00, 0x0b}), errMessageTooBig)
+}
+
+func TestPlaygroundAndIntrospection(t *testing.T) {
+	introspection := `query { __schema { queryType { name } } }`
+
+	// turned off by default
+	ts, _ := newTestServer(t, testConfig())
+	resp, err := http.Get(ts.URL + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	response := decodeResponse(t, postQuery(t, ts.URL, introspection, "", nil))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "introspection disabled", response.Errors[0].Message)
+
+	// turned on in development
+	cfg := testConfig()
+	cfg.Environment = config.EnvDevelopment
+	ts, _ = newTestServer(t, cfg)
+	resp, err = http.Get(ts.URL + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	response = decodeResponse(t, postQuery(t, ts.URL, introspection, "", nil))
+	require.Empty(t, response.Errors)
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepthLimit(t *testing.T) {
+	cfg := testConfig()
+	cfg.GraphQL.DepthLimit = 1
+	cfg.GraphQL.Introspection = true
+	ts, _ := newTestServer(t, cfg)
+
+	response := decodeResponse(t, postQuery(t, ts.URL, `query { posts { id } }`, "", nil))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "operation has depth 2, which exceeds the limit of 1", response.Errors[0].Message)
+	require.Equal(t, errDepthLimit, response.Errors[0].Extensions["code"])
+
+	// introspection fields are not limited
+	response = decodeResponse(t, postQuery(t, ts.URL, `query { __schema { types { name fields { name type { name } } } } }`, "", nil))
+	require.Empty(t, response.Errors)
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/dkrasnykh/graphql-app/internal/service"
+)
+
+// errorPresenter adds kind of the service error as error code into extensions
+func errorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	kind := service.ErrorKind(err)
+	if kind == "" {
+		return gqlErr
+	}
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = make(map[string]any)
+	}
+	if _, ok := gqlErr.Extensions["code"]; !ok {
+		gqlErr.Extensions["code"] = kind
+	}
+
+	return gqlErr
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/subscription"
+)
+
+// how often shutdown checks that all subscriptions are completed
+const drainPollInterval = 10 * time.Millisecond
+
+// Server is http server with graceful shutdown
+type Server struct {
+	http          *http.Server
+	subscriptions *subscription.Subscription
+	timeout       time.Duration
+	probes        *probes
+	// cancels contexts of all connections, websocket connections are closed with close frame
+	closeConns context.CancelFunc
+}
+
+// New creates server, /healthz, /readyz and /version are served without api middlewares
+func New(addr string, h http.Handler, subscriptions *subscription.Subscription, storage HealthChecker, shutdownTimeout time.Duration) *Server {
+	connCtx, cancel := context.WithCancel(context.Background())
+
+	p := &probes{storage: storage}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", p.healthz)
+	mux.HandleFunc("/readyz", p.readyz)
+	mux.HandleFunc("/version", p.version)
+	mux.Handle("/", h)
+
+	return &Server{
+		http: &http.Server{
+			Addr:    addr,
+			Handler: mux,
+			BaseContext: func(net.Listener) context.Context {
+				return connCtx
+			},
+		},
+		subscriptions: subscriptions,
+		timeout:       shutdownTimeout,
+		closeConns:    cancel,
+		probes:        p,
+	}
+}
+
+// UseTLS turns on https (and HTTP/2)
+func (s *Server) UseTLS(cfg *tls.Config) {
+	s.http.TLSConfig = cfg
+}
+
+// Run serves requests until ctx is done and then shuts the server down
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if s.http.TLSConfig != nil {
+			errCh <- s.http.ServeTLS(ln, "", "")
+			return
+		}
+		errCh <- s.http.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	return s.Shutdown()
+}
+
+// Shutdown stops accepting new requests, waits for in-flight operations (within shutdown timeout),
+// completes all subscriptions and closes websocket connections
+func (s *Server) Shutdown() error {
+	s.probes.shuttingDown.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	err := s.http.Shutdown(ctx)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	// resolvers complete subscriptions, when updates are stopped
+	s.subscriptions.Close()
+	for s.subscriptions.Count() > 0 && ctx.Err() == nil {
+		time.Sleep(drainPollInterval)
+	}
+
+	s.closeConns()
+
+	return err
+}
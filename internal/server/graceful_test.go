@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/graph"
+	"github.com/dkrasnykh/graphql-app/internal/metrics"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+	"github.com/dkrasnykh/graphql-app/internal/storage/memory"
+	"github.com/dkrasnykh/graphql-app/internal/subscription"
+)
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	subscriptions := subscription.New()
+	m := metrics.New(subscriptions)
+	h, err := NewHandler(testConfig(), &graph.Resolver{
+		Service:       service.New(memory.New(), subscriptions),
+		Subscriptions: subscriptions,
+	}, m)
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + ln.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := New(ln.Addr().String(), h, subscriptions, memory.New(), 5*time.Second)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- srv.Serve(ctx, ln)
+	}()
+
+	postQuery(t, url, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+
+	conn := dialWebsocket(t, url)
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["1"]}) { id } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	cancel()
+
+	// subscription is completed and connection is closed with close frame
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var types []string
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			require.True(t, errors.As(err, &closeErr), err)
+			require.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+			break
+		}
+		var msg wsMessage
+		require.NoError(t, json.Unmarshal(data, &msg))
+		if msg.Type != "ping" && msg.Type != "ka" {
+			types = append(types, msg.Type)
+		}
+	}
+	require.Equal(t, []string{"complete"}, types)
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped")
+	}
+	require.Equal(t, 0, subscriptions.Count())
+
+	// new requests are not accepted
+	_, err = http.Post(url+"/query", "application/json", strings.NewReader(`{"query": "{ posts { id } }"}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/version"
+)
+
+// readiness check should not hang longer than orchestrator probe timeout
+const readinessTimeout = 2 * time.Second
+
+// HealthChecker is implemented by storages (service.Storager)
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+type probes struct {
+	storage HealthChecker
+	// set when graceful shutdown is started
+	shuttingDown atomic.Bool
+}
+
+type probeResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// process is alive
+func (p *probes) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+// storage is reachable and server is not shutting down
+func (p *probes) readyz(w http.ResponseWriter, r *http.Request) {
+	if p.shuttingDown.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, probeResponse{Status: "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := p.storage.Health(ctx); err != nil {
+		slog.WarnContext(ctx, "storage is not ready", slog.Any("error", err))
+		writeJSON(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, probeResponse{Status: "ready"})
+}
+
+func (p *probes) version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, version.Get())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/storage/memory"
+	"github.com/dkrasnykh/graphql-app/internal/subscription"
+	"github.com/dkrasnykh/graphql-app/internal/version"
+)
+
+type healthFunc func(ctx context.Context) error
+
+func (f healthFunc) Health(ctx context.Context) error {
+	return f(ctx)
+}
+
+func probe(t *testing.T, srv *Server, path string) (int, map[string]any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	srv.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestProbes_Healthz(t *testing.T) {
+	srv := New(":0", http.NotFoundHandler(), subscription.New(), memory.New(), time.Second)
+
+	code, body := probe(t, srv, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", body["status"])
+}
+
+func TestProbes_Readyz(t *testing.T) {
+	srv := New(":0", http.NotFoundHandler(), subscription.New(), memory.New(), time.Second)
+
+	code, body := probe(t, srv, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ready", body["status"])
+}
+
+func TestProbes_ReadyzStorageUnavailable(t *testing.T) {
+	storage := healthFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	srv := New(":0", http.NotFoundHandler(), subscription.New(), storage, time.Second)
+
+	code, body := probe(t, srv, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "unavailable", body["status"])
+	require.Equal(t, "connection refused", body["error"])
+
+	// liveness does not depend on storage
+	code, _ = probe(t, srv, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestProbes_ReadyzShutdown(t *testing.T) {
+	srv := New(":0", http.NotFoundHandler(), subscription.New(), memory.New(), time.Second)
+	require.NoError(t, srv.Shutdown())
+
+	code, body := probe(t, srv, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "shutting down", body["status"])
+
+	code, _ = probe(t, srv, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestProbes_Version(t *testing.T) {
+	old := version.Version
+	version.Version = "v1.2.3"
+	t.Cleanup(func() { version.Version = old })
+
+	srv := New(":0", http.NotFoundHandler(), subscription.New(), memory.New(), time.Second)
+
+	code, body := probe(t, srv, "/version")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "v1.2.3", body["version"])
+	require.NotEmpty(t, body["goVersion"])
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errRequestTooLarge   = "REQUEST_TOO_LARGE"
+	errVariablesTooLarge = "VARIABLES_TOO_LARGE"
+	errOperationTimeout  = "OPERATION_TIMEOUT"
+	errFieldTimeout      = "FIELD_TIMEOUT"
+)
+
+func init() {
+	errcode.RegisterErrorType(errVariablesTooLarge, errcode.KindProtocol)
+}
+
+// maxBodySize rejects requests with body larger than limit bytes with 413 status code
+func maxBodySize(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tooLarge := r.ContentLength > limit
+		if !tooLarge {
+			body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+			if err != nil {
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			tooLarge = int64(len(body)) > limit
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		if tooLarge {
+			err := gqlerror.Errorf("request body exceeds the limit of %d bytes", limit)
+			errcode.Set(err, errRequestTooLarge)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_ = json.NewEncoder(w).Encode(graphql.Response{Errors: gqlerror.List{err}})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// VariablesLimit rejects operations with variables larger than Limit bytes (encoded as json)
+type VariablesLimit struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = VariablesLimit{}
+
+func (v VariablesLimit) ExtensionName() string {
+	return "VariablesLimit"
+}
+
+func (v VariablesLimit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (v VariablesLimit) MutateOperationParameters(ctx context.Context, params *graphql.RawParams) *gqlerror.Error {
+	if len(params.Variables) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(params.Variables)
+	if err != nil {
+		return gqlerror.Errorf("invalid variables: %s", err)
+	}
+	if len(data) > v.Limit {
+		err := gqlerror.Errorf("variables exceed the limit of %d bytes", v.Limit)
+		errcode.Set(err, errVariablesTooLarge)
+		return err
+	}
+	return nil
+}
+
+// Timeouts sets deadline of queries and mutations (subscriptions are not limited)
+// and deadlines of the resolver fields ("Type.field"), errors caused by deadlines get typed error codes
+type Timeouts struct {
+	Operation time.Duration
+	Fields    map[string]time.Duration
+}
+
+var _ interface {
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+	graphql.HandlerExtension
+} = Timeouts{}
+
+func (t Timeouts) ExtensionName() string {
+	return "Timeouts"
+}
+
+func (t Timeouts) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (t Timeouts) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if t.Operation <= 0 || isSubscription(ctx) {
+		return next(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.Operation)
+	defer cancel()
+	return next(ctx)
+}
+
+func (t Timeouts) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if !fc.IsResolver || fc.Object == "Subscription" {
+		return next(ctx)
+	}
+
+	parent := ctx
+	field := fc.Object + "." + fc.Field.Name
+	timeout := t.Fields[field]
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res, err := next(ctx)
+	if err == nil {
+		return res, nil
+	}
+
+	// storages do not wrap context errors, so the deadline is checked on the context
+	switch {
+	case errors.Is(parent.Err(), context.DeadlineExceeded):
+		gqlErr := gqlerror.Errorf("operation exceeded the deadline of %s", t.Operation)
+		errcode.Set(gqlErr, errOperationTimeout)
+		return res, gqlErr
+	case timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		gqlErr := gqlerror.Errorf("field %s exceeded the deadline of %s", field, timeout)
+		errcode.Set(gqlErr, errFieldTimeout)
+		return res, gqlErr
+	}
+	return res, err
+}
+
+func isSubscription(ctx context.Context) bool {
+	if !graphql.HasOperationContext(ctx) {
+		return false
+	}
+	op := graphql.GetOperationContext(ctx).Operation
+	return op != nil && op.Operation == ast.Subscription
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+	"github.com/dkrasnykh/graphql-app/internal/storage/memory"
+)
+
+// slowStorage waits for the context deadline and returns error as postgres storage does
+type slowStorage struct {
+	*memory.StorageMemory
+}
+
+func (s slowStorage) AllPosts(ctx context.Context) ([]*entity.Post, error) {
+	<-ctx.Done()
+	return nil, storage.ErrInternal
+}
+
+func TestMaxBodySize(t *testing.T) {
+	cfg := testConfig()
+	cfg.Limits.MaxBodySize = 100
+	ts, _ := newTestServer(t, cfg)
+
+	body := `{"query": "{ posts { id } }", "variables": {"text": "` + strings.Repeat("a", 100) + `"}}`
+	resp, err := http.Post(ts.URL+"/query", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	var response json.RawMessage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	decoded := decodeResponse(t, response)
+	require.Len(t, decoded.Errors, 1)
+	require.Equal(t, errRequestTooLarge, decoded.Errors[0].Extensions["code"])
+
+	// chunked body without content length
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/query", struct{ *strings.Reader }{strings.NewReader(body)})
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	response = postQuery(t, ts.URL, `{ posts { id } }`, "", nil)
+	require.Empty(t, decodeResponse(t, response).Errors)
+}
+
+func TestVariablesLimit(t *testing.T) {
+	cfg := testConfig()
+	cfg.Limits.MaxVariablesSize = 50
+	ts, _ := newTestServer(t, cfg)
+
+	query := `mutation CreatePost($text: String!) { createPost(input: {text: $text, userID: "1"}) { id } }`
+
+	response := decodeResponse(t, postQuery(t, ts.URL, query, "CreatePost", map[string]any{"text": strings.Repeat("a", 50)}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "variables exceed the limit of 50 bytes", response.Errors[0].Message)
+	require.Equal(t, errVariablesTooLarge, response.Errors[0].Extensions["code"])
+
+	response = decodeResponse(t, postQuery(t, ts.URL, query, "CreatePost", map[string]any{"text": "awesome post"}))
+	require.Empty(t, response.Errors)
+}
+
+func TestOperationTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.Limits.OperationTimeout = 50 * time.Millisecond
+	ts, _ := newTestServerWithStorage(t, cfg, slowStorage{memory.New()})
+
+	response := decodeResponse(t, postQuery(t, ts.URL, `{ posts { id } }`, "", nil))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "operation exceeded the deadline of 50ms", response.Errors[0].Message)
+	require.Equal(t, errOperationTimeout, response.Errors[0].Extensions["code"])
+}
+
+func TestFieldTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.Limits.OperationTimeout = 5 * time.Second
+	cfg.Limits.FieldTimeouts = map[string]time.Duration{"Query.posts": 50 * time.Millisecond}
+	ts, _ := newTestServerWithStorage(t, cfg, slowStorage{memory.New()})
+
+	start := time.Now()
+	response := decodeResponse(t, postQuery(t, ts.URL, `{ posts { id } }`, "", nil))
+	require.Less(t, time.Since(start), time.Second)
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "field Query.posts exceeded the deadline of 50ms", response.Errors[0].Message)
+	require.Equal(t, errFieldTimeout, response.Errors[0].Extensions["code"])
+
+	// other fields are limited only by operation timeout
+	response = decodeResponse(t, postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil))
+	require.Empty(t, response.Errors)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/logging"
+)
+
+// replaces default logger (handler extension logs with default logger) and returns log records
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, testConfig().Logging)
+	require.NoError(t, err)
+
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	return func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestLogging_Operation(t *testing.T) {
+	records := captureLogs(t)
+	cfg := testConfig()
+	cfg.Logging.SlowOperationThreshold = time.Nanosecond
+	cfg.Logging.RedactVariables = []string{"text"}
+	ts, _ := newTestServer(t, cfg)
+
+	query := `mutation CreatePost($text: String!, $userID: ID!) { createPost(input: {text: $text, userID: $userID}) { id } }`
+	postWithHeader(t, ts.URL, map[string]any{
+		"query":     query,
+		"variables": map[string]any{"text": "awesome post", "userID": "1"},
+	}, http.Header{
+		logging.RequestIDHeader: []string{"request-1"},
+		auth.UserIDHeader:       []string{"1"},
+	})
+	postQuery(t, ts.URL, `query Post { post(id: "2") { id } }`, "", nil)
+
+	logs := records()
+	require.Len(t, logs, 4)
+
+	operation := logs[0]
+	require.Equal(t, "operation", operation["msg"])
+	require.Equal(t, "INFO", operation["level"])
+	require.Equal(t, "CreatePost", operation["operation"])
+	require.Equal(t, "mutation", operation["type"])
+	require.Equal(t, "request-1", operation["request_id"])
+	require.Equal(t, float64(1), operation["user_id"])
+	require.Equal(t, map[string]any{"text": "[REDACTED]", "userID": "1"}, operation["variables"])
+	require.Contains(t, operation, "duration")
+
+	slow := logs[1]
+	require.Equal(t, "slow operation", slow["msg"])
+	require.Equal(t, query, slow["query"])
+
+	notFound := logs[2]
+	require.Equal(t, "operation", notFound["msg"])
+	require.Equal(t, "WARN", notFound["level"])
+	require.Equal(t, "Post", notFound["operation"])
+	require.Equal(t, "NOT_FOUND", notFound["error_code"])
+	require.NotEmpty(t, notFound["request_id"])
+	require.NotContains(t, notFound, "user_id")
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ts, subscriptions := newTestServer(t, testConfig())
+
+	postQuery(t, ts.URL, `mutation CreatePost { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "CreatePost", nil)
+	postQuery(t, ts.URL, `query { post(id: "2") { id } }`, "", nil)
+
+	conn := dialWebsocket(t, ts.URL)
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["1", "2"]}) { id } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	body := string(data)
+
+	require.Contains(t, body, `graphql_app_operations_total{operation="CreatePost",type="mutation"} 1`)
+	require.Contains(t, body, `graphql_app_operations_total{operation="anonymous",type="query"} 1`)
+	require.Contains(t, body, `graphql_app_operations_total{operation="anonymous",type="subscription"} 1`)
+	require.Contains(t, body, `graphql_app_operation_duration_seconds_count{operation="CreatePost",type="mutation"} 1`)
+	require.Contains(t, body, `graphql_app_resolver_errors_total{field="Query.post",kind="NOT_FOUND"} 1`)
+	require.Contains(t, body, `graphql_app_storage_call_duration_seconds_count{method="SavePost",status="ok"} 1`)
+	require.Contains(t, body, `graphql_app_storage_call_duration_seconds_count{method="PostByID",status="error"} 1`)
+	require.Contains(t, body, `graphql_app_subscriptions_active 1`)
+	require.Contains(t, body, `graphql_app_subscription_observed_posts 2`)
+	require.Contains(t, body, `graphql_app_subscription_subscribers 2`)
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/config"
+	"github.com/dkrasnykh/graphql-app/internal/ratelimit"
+)
+
+func TestRateLimit_CreateComment(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.CreateComment = config.Bucket{Rate: 0.001, Burst: 2}
+	ts, _ := newTestServer(t, cfg)
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+
+	createComment := map[string]any{"query": `mutation { createComment(input: {text: "comment", postID: "1", userID: "1"}) { id } }`}
+	user1 := http.Header{auth.UserIDHeader: []string{"1"}}
+	user2 := http.Header{auth.UserIDHeader: []string{"2"}}
+
+	for i := 0; i < 2; i++ {
+		response := decodeResponse(t, postWithHeader(t, ts.URL, createComment, user1))
+		require.Empty(t, response.Errors)
+	}
+
+	response := decodeResponse(t, postWithHeader(t, ts.URL, createComment, user1))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+	require.Greater(t, response.Errors[0].Extensions["retryAfter"], float64(0))
+
+	// another user has own budget
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, user2))
+	require.Empty(t, response.Errors)
+
+	// budgets of the mutations are separate
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { createPost(input: {text: "post", userID: "1"}) { id } }`}, user1))
+	require.Empty(t, response.Errors)
+}
+
+func TestRateLimit_Follow(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Follow = config.Bucket{Rate: 0.001, Burst: 2}
+	ts, _ := newTestServer(t, cfg)
+	user1 := http.Header{auth.UserIDHeader: []string{"1"}}
+
+	// follow and unfollow share the budget
+	response := decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { follow(userID: "2") }`}, user1))
+	require.Empty(t, response.Errors)
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { unfollow(userID: "2") }`}, user1))
+	require.Empty(t, response.Errors)
+
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { follow(userID: "3") }`}, user1))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+}
+
+func TestRateLimit_Batch(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.CreatePost = config.Bucket{Rate: 0.001, Burst: 3}
+	ts, _ := newTestServer(t, cfg)
+	user1 := http.Header{auth.UserIDHeader: []string{"1"}}
+
+	// every item of the batch takes a token of createPost
+	createPosts := map[string]any{"query": `mutation { createPosts(inputs: [{text: "a", userID: "1"}, {text: "b", userID: "1"}]) { post { id } } }`}
+	response := decodeResponse(t, postWithHeader(t, ts.URL, createPosts, user1))
+	require.Empty(t, response.Errors)
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createPosts, user1))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { createPost(input: {text: "post", userID: "1"}) { id } }`}, user1))
+	require.Empty(t, response.Errors)
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { createPost(input: {text: "post", userID: "1"}) { id } }`}, user1))
+	require.Len(t, response.Errors, 1)
+
+	// batch larger than burst is rejected for a fresh bucket too
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `mutation { createPosts(inputs: [{text: "a", userID: "2"}, {text: "b", userID: "2"}, {text: "c", userID: "2"}, {text: "d", userID: "2"}]) { post { id } } }`},
+		http.Header{auth.UserIDHeader: []string{"2"}}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+	require.Contains(t, response.Errors[0].Message, "exceeds the limit of 3 items")
+}
+
+func TestRateLimit_UntrustedUserHeader(t *testing.T) {
+	cfg := testConfig()
+	cfg.Auth.TrustedProxies = []string{"10.0.0.0/8"}
+	cfg.RateLimit.CreateComment = config.Bucket{Rate: 0.001, Burst: 2}
+	ts, _ := newTestServer(t, cfg)
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+
+	// header of the client outside of the gateway network is ignored, so a new user id does not give a new budget
+	createComment := map[string]any{"query": `mutation { createComment(input: {text: "comment", postID: "1", userID: "1"}) { id } }`}
+	for i := 0; i < 2; i++ {
+		response := decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{auth.UserIDHeader: []string{strconv.Itoa(i + 1)}}))
+		require.Empty(t, response.Errors)
+	}
+	response := decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{auth.UserIDHeader: []string{"3"}}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+
+	// and the request is anonymous
+	response = decodeResponse(t, postWithHeader(t, ts.URL, map[string]any{"query": `{ homeFeed { pageInfo { hasNextPage } } }`},
+		http.Header{auth.UserIDHeader: []string{"1"}}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "UNAUTHENTICATED", response.Errors[0].Extensions["code"])
+}
+
+func TestRateLimit_Subscribe(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Subscribe = config.Bucket{Rate: 0.001, Burst: 1}
+	ts, subscriptions := newTestServer(t, cfg)
+
+	conn := dialWebsocket(t, ts.URL)
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["1"]}) { id } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	subscribe(t, conn, "2", `subscription { comments(input: {postIDs: ["1"]}) { id } }`)
+	var msg wsMessage
+	require.NoError(t, json.Unmarshal(readMessage(t, conn), &msg))
+	require.Equal(t, "2", msg.ID)
+	response := decodeResponse(t, msg.Payload)
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+	require.Equal(t, 1, subscriptions.Count())
+}
+
+func TestRateLimit_NewPostsInFeed(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Subscribe = config.Bucket{Rate: 0.001, Burst: 1}
+	ts, subscriptions := newTestServer(t, cfg)
+
+	// subscriptions share the budget
+	conn := dialWebsocketWithHeader(t, ts.URL, http.Header{auth.UserIDHeader: []string{"1"}})
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["1"]}) { id } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	subscribe(t, conn, "2", `subscription { newPostsInFeed { id } }`)
+	var msg wsMessage
+	require.NoError(t, json.Unmarshal(readMessage(t, conn), &msg))
+	require.Equal(t, "2", msg.ID)
+	response := decodeResponse(t, msg.Payload)
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+}
+
+func TestRateLimit_Notifications(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Subscribe = config.Bucket{Rate: 0.001, Burst: 1}
+	ts, subscriptions := newTestServer(t, cfg)
+
+	conn := dialWebsocketWithHeader(t, ts.URL, http.Header{auth.UserIDHeader: []string{"1"}})
+	subscribe(t, conn, "1", `subscription { notifications { id } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	subscribe(t, conn, "2", `subscription { notifications { id } }`)
+	var msg wsMessage
+	require.NoError(t, json.Unmarshal(readMessage(t, conn), &msg))
+	require.Equal(t, "2", msg.ID)
+	response := decodeResponse(t, msg.Payload)
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+}
+
+func TestRateLimit_ForwardedClientIP(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.CreateComment = config.Bucket{Rate: 0.001, Burst: 1}
+	ts, _ := newTestServer(t, cfg)
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+	createComment := map[string]any{"query": `mutation { createComment(input: {text: "comment", postID: "1", userID: "1"}) { id } }`}
+
+	// anonymous clients behind the gateway have own budgets
+	response := decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"203.0.113.1"}}))
+	require.Empty(t, response.Errors)
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"203.0.113.1"}}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Real-IP": []string{"203.0.113.2"}}))
+	require.Empty(t, response.Errors)
+
+	// the address set by the client itself is ignored, the last one is added by the gateway
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"198.51.100.1, 203.0.113.1"}}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+
+	// addresses of the trusted proxies are skipped
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"203.0.113.3, 127.0.0.1"}}))
+	require.Empty(t, response.Errors)
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"203.0.113.3"}}))
+	require.Len(t, response.Errors, 1)
+}
+
+func TestRateLimit_UntrustedForwardedFor(t *testing.T) {
+	cfg := testConfig()
+	cfg.Auth.TrustedProxies = []string{"10.0.0.0/8"}
+	cfg.RateLimit.CreateComment = config.Bucket{Rate: 0.001, Burst: 1}
+	ts, _ := newTestServer(t, cfg)
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+	createComment := map[string]any{"query": `mutation { createComment(input: {text: "comment", postID: "1", userID: "1"}) { id } }`}
+
+	// forwarding headers of the client outside of the gateway network are ignored, the budget is of its own address
+	response := decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"203.0.113.1"}}))
+	require.Empty(t, response.Errors)
+	response = decodeResponse(t, postWithHeader(t, ts.URL, createComment, http.Header{"X-Forwarded-For": []string{"203.0.113.2"}, "X-Real-IP": []string{"203.0.113.3"}}))
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, ratelimit.ErrRateLimited, response.Errors[0].Extensions["code"])
+}
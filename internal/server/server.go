@@ -0,0 +1,118 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gorilla/websocket"
+
+	"github.com/dkrasnykh/graphql-app/graph"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/config"
+	"github.com/dkrasnykh/graphql-app/internal/logging"
+	"github.com/dkrasnykh/graphql-app/internal/metrics"
+	"github.com/dkrasnykh/graphql-app/internal/ratelimit"
+	"github.com/dkrasnykh/graphql-app/internal/tracing"
+)
+
+// NewHandler returns the http handler of the application:
+// GraphQL playground on "/" (if enabled), GraphQL endpoint (POST and websocket transports) on "/query"
+// and prometheus metrics on "/metrics"
+func NewHandler(cfg *config.Config, resolver *graph.Resolver, m *metrics.Metrics) (http.Handler, error) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  resolver,
+		Complexity: graph.NewComplexity(cfg.GraphQL.DefaultListSize),
+	}))
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: origins(cfg.HTTP.AllowedOrigins).checkOrigin,
+		},
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New(1000))
+	srv.SetErrorPresenter(errorPresenter)
+
+	srv.Use(graph.Loaders{Service: resolver.Service})
+	srv.Use(tracing.Extension{})
+	srv.Use(logging.Extension{
+		Logger:        slog.Default(),
+		SlowThreshold: cfg.Logging.SlowOperationThreshold,
+		Redact:        cfg.Logging.RedactVariables,
+	})
+	srv.Use(m.Extension())
+	if cfg.IntrospectionEnabled() {
+		srv.Use(extension.Introspection{})
+	}
+	// strict mode: only operations from the manifest are accepted,
+	// otherwise clients can register any query with automatic persisted queries
+	if cfg.PersistedQueries.Manifest != "" {
+		allowList, err := LoadAllowList(cfg.PersistedQueries.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load allow-list: %w", err)
+		}
+		srv.Use(allowList)
+	} else if cfg.PersistedQueries.CacheSize > 0 {
+		srv.Use(extension.AutomaticPersistedQuery{
+			Cache: lru.New(cfg.PersistedQueries.CacheSize),
+		})
+	}
+	srv.Use(extension.FixedComplexityLimit(cfg.GraphQL.ComplexityLimit))
+	srv.Use(DepthLimit{Limit: cfg.GraphQL.DepthLimit})
+	srv.Use(newRateLimit(cfg.RateLimit))
+	if cfg.Limits.MaxVariablesSize > 0 {
+		srv.Use(VariablesLimit{Limit: cfg.Limits.MaxVariablesSize})
+	}
+	srv.Use(Timeouts{
+		Operation: cfg.Limits.OperationTimeout,
+		Fields:    cfg.Limits.FieldTimeouts,
+	})
+
+	mux := http.NewServeMux()
+	if cfg.PlaygroundEnabled() {
+		mux.Handle("/", playground.Handler("GraphQL playground", "/query"))
+	}
+	query := wsReadLimit(cfg.HTTP.MaxWebsocketMessageSize, srv)
+	query = maxBodySize(cfg.Limits.MaxBodySize, query)
+	mux.Handle("/query", cors(cfg.HTTP.AllowedOrigins, cfg.HTTP.CORSMaxAge, query))
+	mux.Handle("/metrics", m.Handler())
+
+	trusted := cfg.Auth.TrustedNetworks()
+	return logging.RequestIDMiddleware(auth.Middleware(trusted)(ratelimit.Middleware(trusted)(mux))), nil
+}
+
+// batch mutations take one token for every item from the bucket of the single mutation
+func newRateLimit(cfg config.RateLimit) ratelimit.Extension {
+	buckets := []struct {
+		bucket config.Bucket
+		fields map[string]string // field: counted list argument
+	}{
+		{cfg.CreatePost, map[string]string{"Mutation.createPost": "", "Mutation.createPosts": "inputs"}},
+		{cfg.CreateComment, map[string]string{"Mutation.createComment": "", "Mutation.createComments": "inputs"}},
+		{cfg.Subscribe, map[string]string{"Subscription.comments": "", "Subscription.newPostsInFeed": "", "Subscription.notifications": ""}},
+		{cfg.Follow, map[string]string{"Mutation.follow": "", "Mutation.unfollow": ""}},
+	}
+	rules := make(map[string]ratelimit.Rule)
+	for _, b := range buckets {
+		if b.bucket.Rate <= 0 {
+			continue
+		}
+		limiter := ratelimit.NewLimiter(b.bucket.Rate, b.bucket.Burst)
+		for field, countArg := range b.fields {
+			rules[field] = ratelimit.Rule{Limiter: limiter, CountArg: countArg}
+		}
+	}
+	return ratelimit.Extension{Rules: rules}
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+
+	"github.com/dkrasnykh/graphql-app/graph"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/config"
+	"github.com/dkrasnykh/graphql-app/internal/metrics"
+	"github.com/dkrasnykh/graphql-app/internal/service"
+	"github.com/dkrasnykh/graphql-app/internal/storage/memory"
+	"github.com/dkrasnykh/graphql-app/internal/subscription"
+)
+
+// go test ./internal/server -update rewrites golden files with actual responses
+var update = flag.Bool("update", false, "update golden files")
+
+/*
+Every testdata/operations/<name>.graphql file is a test case, executed against a new server with empty memory storage.
+Operations of the file are sent one by one (in the order of declaration) as POST requests to /query.
+Optional <name>.variables.json file stores variables for operations: {"operationName": {...}}.
+Optional <name>.headers.json file stores request headers for operations: {"operationName": {"X-User-ID": "1"}}.
+Responses are compared with <name>.golden.json file.
+*/
+func TestOperations_Golden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "operations", "*.graphql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".graphql")
+		t.Run(name, func(t *testing.T) {
+			ts, _ := newTestServer(t, testConfig())
+
+			operations := readOperations(t, file)
+			variables := readVariables(t, strings.TrimSuffix(file, ".graphql")+".variables.json")
+			headers := readHeaders(t, strings.TrimSuffix(file, ".graphql")+".headers.json")
+
+			results := make([]goldenResult, 0, len(operations))
+			for _, op := range operations {
+				response := postWithHeader(t, ts.URL, map[string]any{
+					"query":         op.query,
+					"operationName": op.name,
+					"variables":     variables[op.name],
+				}, headers[op.name])
+				results = append(results, goldenResult{Operation: op.name, Response: response})
+			}
+
+			compareGolden(t, strings.TrimSuffix(file, ".graphql")+".golden.json", results)
+		})
+	}
+}
+
+func TestSubscriptionComments(t *testing.T) {
+	ts, subscriptions := newTestServer(t, testConfig())
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+
+	conn := dialWebsocket(t, ts.URL)
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["1"]}) { id text parentCommentID postID userID } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	postQuery(t, ts.URL, `mutation { createComment(input: {text: "comment 1", postID: "1", userID: "2"}) { id } }`, "", nil)
+	postQuery(t, ts.URL, `mutation { createComment(input: {text: "comment 2", parentCommentID: "1", postID: "1", userID: "1"}) { id } }`, "", nil)
+
+	messages := []json.RawMessage{readMessage(t, conn), readMessage(t, conn)}
+
+	compareGolden(t, filepath.Join("testdata", "subscriptions", "comments.golden.json"), messages)
+}
+
+func TestSubscriptionComments_InvalidPostID(t *testing.T) {
+	ts, _ := newTestServer(t, testConfig())
+
+	conn := dialWebsocket(t, ts.URL)
+	subscribe(t, conn, "1", `subscription { comments(input: {postIDs: ["abc"]}) { id } }`)
+
+	messages := []json.RawMessage{readMessage(t, conn)}
+
+	compareGolden(t, filepath.Join("testdata", "subscriptions", "invalid_post_id.golden.json"), messages)
+}
+
+func TestSubscriptionNewPostsInFeed(t *testing.T) {
+	ts, subscriptions := newTestServer(t, testConfig())
+	user2 := http.Header{auth.UserIDHeader: []string{"2"}}
+
+	postWithHeader(t, ts.URL, map[string]any{"query": `mutation { follow(userID: "1") }`}, user2)
+
+	conn := dialWebsocketWithHeader(t, ts.URL, user2)
+	subscribe(t, conn, "1", `subscription { newPostsInFeed { id text userID } }`)
+	waitSubscriptions(t, subscriptions, 1)
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "not followed", userID: "3"}) { id } }`, "", nil)
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "followed", userID: "1"}) { id } }`, "", nil)
+
+	messages := []json.RawMessage{readMessage(t, conn)}
+
+	compareGolden(t, filepath.Join("testdata", "subscriptions", "new_posts_in_feed.golden.json"), messages)
+}
+
+type operation struct {
+	name  string
+	query string
+}
+
+type goldenResult struct {
+	Operation string          `json:"operation"`
+	Response  json.RawMessage `json:"response"`
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Storage: config.Storage{
+			Driver: config.DriverMemory,
+		},
+		Auth: config.Auth{
+			TrustedProxies: []string{"127.0.0.1/32", "::1/128"},
+		},
+		GraphQL: config.GraphQL{
+			ComplexityLimit: 100,
+			DepthLimit:      5,
+			DefaultListSize: 10,
+		},
+		PersistedQueries: config.PersistedQueries{
+			CacheSize: 10,
+		},
+		Logging: config.Logging{
+			Level:           "info",
+			Format:          "json",
+			RedactVariables: []string{"password", "token"},
+		},
+	}
+}
+
+func newTestServer(t *testing.T, cfg *config.Config) (*httptest.Server, *subscription.Subscription) {
+	t.Helper()
+
+	return newTestServerWithStorage(t, cfg, memory.New())
+}
+
+func newTestServerWithStorage(t *testing.T, cfg *config.Config, storage service.Storager) (*httptest.Server, *subscription.Subscription) {
+	t.Helper()
+
+	subscriptions := subscription.New()
+	m := metrics.New(subscriptions)
+	serv := service.New(m.Storager(storage), subscriptions)
+	h, err := NewHandler(cfg, &graph.Resolver{
+		Service:       serv,
+		Subscriptions: subscriptions,
+	}, m)
+	require.NoError(t, err)
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+
+	return ts, subscriptions
+}
+
+// splits file into separate operations, so invalid operation does not fail validation of the others
+func readOperations(t *testing.T, file string) []operation {
+	t.Helper()
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	doc, gqlErr := parser.ParseQuery(&ast.Source{Name: file, Input: string(data)})
+	require.Nil(t, gqlErr)
+	require.Empty(t, doc.Fragments, "fragments are not supported in %s", file)
+
+	// positions of the parser are counted in runes
+	source := []rune(string(data))
+	operations := make([]operation, 0, len(doc.Operations))
+	for i, op := range doc.Operations {
+		require.NotEmpty(t, op.Name, "operations in %s must be named", file)
+		end := len(source)
+		if i+1 < len(doc.Operations) {
+			end = doc.Operations[i+1].Position.Start
+		}
+		query := strings.TrimSpace(string(source[op.Position.Start:end]))
+		operations = append(operations, operation{name: op.Name, query: query})
+	}
+	return operations
+}
+
+func readVariables(t *testing.T, file string) map[string]map[string]any {
+	t.Helper()
+
+	variables := make(map[string]map[string]any)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return variables
+	}
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &variables))
+	return variables
+}
+
+func readHeaders(t *testing.T, file string) map[string]http.Header {
+	t.Helper()
+
+	headers := make(map[string]http.Header)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return headers
+	}
+	require.NoError(t, err)
+
+	var values map[string]map[string]string
+	require.NoError(t, json.Unmarshal(data, &values))
+	for name, list := range values {
+		headers[name] = http.Header{}
+		for key, value := range list {
+			headers[name].Set(key, value)
+		}
+	}
+	return headers
+}
+
+func postQuery(t *testing.T, url string, query string, operationName string, variables map[string]any) json.RawMessage {
+	t.Helper()
+
+	return postParams(t, url, map[string]any{
+		"query":         query,
+		"operationName": operationName,
+		"variables":     variables,
+	})
+}
+
+func postParams(t *testing.T, url string, params map[string]any) json.RawMessage {
+	t.Helper()
+
+	return postWithHeader(t, url, params, nil)
+}
+
+func postWithHeader(t *testing.T, url string, params map[string]any, header http.Header) json.RawMessage {
+	t.Helper()
+
+	body, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, url+"/query", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response json.RawMessage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response
+}
+
+func dialWebsocket(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+
+	return dialWebsocketWithHeader(t, url, nil)
+}
+
+func dialWebsocketWithHeader(t *testing.T, url string, header http.Header) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/query", header)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: "connection_init"}))
+	var ack wsMessage
+	require.NoError(t, conn.ReadJSON(&ack))
+	require.Equal(t, "connection_ack", ack.Type)
+
+	return conn
+}
+
+func subscribe(t *testing.T, conn *websocket.Conn, id string, query string) {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]any{"query": query})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(wsMessage{ID: id, Type: "subscribe", Payload: payload}))
+}
+
+// subscribe message has no acknowledgement, so wait until resolver registers subscription
+func waitSubscriptions(t *testing.T, subscriptions *subscription.Subscription, count int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		return subscriptions.Count() == count
+	}, time.Second, 10*time.Millisecond)
+}
+
+// reads next message skipping keep alive messages
+func readMessage(t *testing.T, conn *websocket.Conn) json.RawMessage {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		var msg wsMessage
+		require.NoError(t, json.Unmarshal(data, &msg))
+		if msg.Type == "ping" || msg.Type == "pong" || msg.Type == "ka" {
+			continue
+		}
+		return data
+	}
+}
+
+func compareGolden(t *testing.T, file string, actual any) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(actual, "", "  ")
+	require.NoError(t, err)
+	data = append(data, '\n')
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(t, os.WriteFile(file, data, 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(file)
+	require.NoError(t, err, "golden file is missing, run tests with -update flag")
+	require.Equal(t, string(expected), string(data))
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dkrasnykh/graphql-app/internal/config"
+	"github.com/dkrasnykh/graphql-app/internal/storage/memory"
+	"github.com/dkrasnykh/graphql-app/internal/subscription"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// generates certificate signed by parent (self-signed if parent is nil)
+func generateCert(t *testing.T, serial int64, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "graphql-app test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *testCert) write(t *testing.T, dir string, name string) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, c.certPEM(), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// starts https server, returns its url
+func startTLSServer(t *testing.T, cfg config.TLS) (string, *CertReloader) {
+	t.Helper()
+
+	certs, err := NewCertReloader(cfg)
+	require.NoError(t, err)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := New("127.0.0.1:0", h, subscription.New(), memory.New(), time.Second)
+	srv.UseTLS(certs.TLSConfig())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- srv.Serve(ctx, ln)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-stopped)
+	})
+
+	return "https://" + ln.Addr().String(), certs
+}
+
+func tlsClient(ca *testCert, clientCert *testCert) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	tlsCfg := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		tlsCfg.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+	}
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   tlsCfg,
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+func TestTLS_HTTP2(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, 1, nil, true)
+	certFile, keyFile := generateCert(t, 2, ca, false).write(t, dir, "server")
+
+	url, _ := startTLSServer(t, config.TLS{CertFile: certFile, KeyFile: keyFile})
+
+	resp, err := tlsClient(ca, nil).Get(url + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 2, resp.ProtoMajor)
+	require.Equal(t, big.NewInt(2), resp.TLS.PeerCertificates[0].SerialNumber)
+}
+
+func TestTLS_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, 1, nil, true)
+	certFile, keyFile := generateCert(t, 2, ca, false).write(t, dir, "server")
+
+	url, certs := startTLSServer(t, config.TLS{CertFile: certFile, KeyFile: keyFile})
+
+	generateCert(t, 3, ca, false).write(t, dir, "server")
+	require.NoError(t, certs.Reload())
+
+	// new client, so the handshake is not resumed from previous connection
+	resp, err := tlsClient(ca, nil).Get(url + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, big.NewInt(3), resp.TLS.PeerCertificates[0].SerialNumber)
+
+	// invalid files do not replace loaded certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o600))
+	require.Error(t, certs.Reload())
+
+	resp, err = tlsClient(ca, nil).Get(url + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, big.NewInt(3), resp.TLS.PeerCertificates[0].SerialNumber)
+}
+
+func TestTLS_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, 1, nil, true)
+	certFile, keyFile := generateCert(t, 2, ca, false).write(t, dir, "server")
+	caFile, _ := ca.write(t, dir, "ca")
+
+	url, _ := startTLSServer(t, config.TLS{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: caFile,
+		ClientAuth:   "require",
+	})
+
+	// client without certificate is rejected
+	_, err := tlsClient(ca, nil).Get(url + "/healthz")
+	require.Error(t, err)
+
+	// certificate signed by unknown CA is rejected
+	otherCA := generateCert(t, 10, nil, true)
+	_, err = tlsClient(ca, generateCert(t, 11, otherCA, false)).Get(url + "/healthz")
+	require.Error(t, err)
+
+	resp, err := tlsClient(ca, generateCert(t, 4, ca, false)).Get(url + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
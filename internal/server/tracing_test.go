@@ -0,0 +1,69 @@
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// global tracer provider can be installed only once, tracers of the packages are bound to the first one
+var spanExporter = sync.OnceValue(func() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	return exporter
+})
+
+func TestTracing_CreateComment(t *testing.T) {
+	exporter := spanExporter()
+	ts, _ := newTestServer(t, testConfig())
+
+	postQuery(t, ts.URL, `mutation { createPost(input: {text: "awesome post", userID: "1"}) { id } }`, "", nil)
+	exporter.Reset()
+
+	postQuery(t, ts.URL, `mutation CreateComment { createComment(input: {text: "comment", postID: "1", userID: "1"}) { id text } }`, "CreateComment", nil)
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	require.Len(t, byName, len(spans), "span names are unique")
+
+	operation, ok := byName["mutation CreateComment"]
+	require.True(t, ok)
+	field, ok := byName["Mutation.createComment"]
+	require.True(t, ok)
+	serviceSpan, ok := byName["Service.SaveComment"]
+	require.True(t, ok)
+	broadcast, ok := byName["Subscription.Broadcast"]
+	require.True(t, ok)
+
+	// operation -> field -> service -> broadcast
+	require.False(t, operation.Parent.IsValid())
+	require.Equal(t, operation.SpanContext.SpanID(), field.Parent.SpanID())
+	require.Equal(t, field.SpanContext.SpanID(), serviceSpan.Parent.SpanID())
+	require.Equal(t, serviceSpan.SpanContext.SpanID(), broadcast.Parent.SpanID())
+	for _, span := range spans {
+		require.Equal(t, operation.SpanContext.TraceID(), span.SpanContext.TraceID())
+	}
+	// fields without resolvers have no spans
+	require.Len(t, spans, 4)
+}
+
+func TestTracing_ServiceError(t *testing.T) {
+	exporter := spanExporter()
+	ts, _ := newTestServer(t, testConfig())
+	exporter.Reset()
+
+	postQuery(t, ts.URL, `query { post(id: "1") { id } }`, "", nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		require.Equal(t, "Error", span.Status.Code.String(), span.Name)
+	}
+}
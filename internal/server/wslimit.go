@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+var errMessageTooBig = errors.New("websocket message is too big")
+
+// close frame with status 1009 (message too big), server frames are not masked
+var closeMessageTooBig = []byte{0x88, 0x02, 0x03, 0xf1}
+
+// wsReadLimit closes websocket connections receiving messages larger than limit bytes.
+// gqlgen websocket transport does not expose websocket.Conn.SetReadLimit,
+// so frames are inspected on the hijacked connection
+func wsReadLimit(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&limitedHijacker{ResponseWriter: w, limit: limit}, r)
+	})
+}
+
+type limitedHijacker struct {
+	http.ResponseWriter
+	limit int64
+}
+
+func (h *limitedHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := h.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not implement http.Hijacker")
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	// upgrader resets buffers of brw to the returned connection
+	return &limitedConn{Conn: conn, frames: frameReader{limit: h.limit}}, brw, nil
+}
+
+type limitedConn struct {
+	net.Conn
+	frames frameReader
+	close  sync.Once
+}
+
+func (c *limitedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if limitErr := c.frames.feed(p[:n]); limitErr != nil {
+		c.close.Do(func() {
+			_, _ = c.Conn.Write(closeMessageTooBig)
+			_ = c.Conn.Close()
+		})
+		return 0, limitErr
+	}
+	return n, err
+}
+
+// frameReader follows frame headers of the incoming websocket stream and counts size of the current message
+// (sum of payloads of data frame and its continuation frames)
+type frameReader struct {
+	limit     int64
+	header    []byte
+	remaining uint64
+	message   uint64
+}
+
+func (f *frameReader) feed(b []byte) error {
+	for len(b) > 0 {
+		if f.remaining > 0 {
+			n := uint64(len(b))
+			if n > f.remaining {
+				n = f.remaining
+			}
+			f.remaining -= n
+			b = b[n:]
+			continue
+		}
+
+		f.header = append(f.header, b[0])
+		b = b[1:]
+		size, fin, opcode, ok := parseFrameHeader(f.header)
+		if !ok {
+			continue
+		}
+		f.header = f.header[:0]
+		f.remaining = size
+
+		// control frames (close, ping, pong) are not part of messages
+		if opcode >= 0x8 {
+			continue
+		}
+		f.message += size
+		if f.message > uint64(f.limit) {
+			return errMessageTooBig
+		}
+		if fin {
+			f.message = 0
+		}
+	}
+	return nil
+}
+
+// returns payload size, fin bit and opcode when the header is complete
+func parseFrameHeader(h []byte) (size uint64, fin bool, opcode byte, ok bool) {
+	if len(h) < 2 {
+		return 0, false, 0, false
+	}
+
+	headerLen := 2
+	switch h[1] & 0x7f {
+	case 126:
+		headerLen += 2
+	case 127:
+		headerLen += 8
+	}
+	if h[1]&0x80 != 0 {
+		headerLen += 4 // masking key
+	}
+	if len(h) < headerLen {
+		return 0, false, 0, false
+	}
+
+	switch h[1] & 0x7f {
+	case 126:
+		size = uint64(binary.BigEndian.Uint16(h[2:4]))
+	case 127:
+		size = binary.BigEndian.Uint64(h[2:10])
+	default:
+		size = uint64(h[1] & 0x7f)
+	}
+
+	return size, h[0]&0x80 != 0, h[0] & 0x0f, true
+}
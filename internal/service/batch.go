@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// SavePosts saves all posts in one transaction; if any item is invalid, nothing is saved
+// and the error of each item is returned in its result
+func (s *Service) SavePosts(ctx context.Context, inputs []*model.BatchPost) (_ []*model.CreatePostResult, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SavePosts")
+	defer func() { endSpan(span, err) }()
+
+	if len(inputs) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]*model.CreatePostResult, len(inputs))
+	posts := make([]entity.Post, len(inputs))
+	tempIDs := make(map[string]struct{})
+	failed := false
+	for i, input := range inputs {
+		results[i] = &model.CreatePostResult{TempID: input.TempID}
+
+		post, err := s.ValidatePost(model.NewPost{
+			Text:        input.Text,
+			UserID:      input.UserID,
+			CommentsOff: input.CommentsOff,
+		})
+		if err == nil && input.TempID != nil {
+			if _, ok := tempIDs[*input.TempID]; ok {
+				err = fmt.Errorf("%w; temp id: %s", ErrDuplicateTempID, *input.TempID)
+			}
+			tempIDs[*input.TempID] = struct{}{}
+		}
+		if err != nil {
+			results[i].Error = itemError(err)
+			failed = true
+			continue
+		}
+		posts[i] = *post
+	}
+	if failed {
+		return abortBatch(results, func(r *model.CreatePostResult) **model.BatchItemError { return &r.Error }), nil
+	}
+
+	saved, err := s.storage.SavePosts(ctx, posts)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	for i, post := range saved {
+		results[i].Post = convertPostEntityIntoModel(post)
+		s.publishPost(ctx, post)
+	}
+	return results, nil
+}
+
+// SaveComments saves all comments in one transaction; if any item is invalid, nothing is saved
+// and the error of each item is returned in its result. Subscribers get saved comments after commit.
+func (s *Service) SaveComments(ctx context.Context, inputs []*model.BatchComment) (_ []*model.CreateCommentResult, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SaveComments")
+	defer func() { endSpan(span, err) }()
+
+	if len(inputs) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]*model.CreateCommentResult, len(inputs))
+	comments := make([]entity.BatchComment, len(inputs))
+	// index of the batch item by temp id
+	tempIDs := make(map[string]int)
+	failed := false
+	for i, input := range inputs {
+		results[i] = &model.CreateCommentResult{TempID: input.TempID}
+
+		comment, err := s.validateBatchComment(*input, tempIDs)
+		// temp id is registered even for invalid item, so that its replies get ABORTED error instead of invalid parent
+		if input.TempID != nil {
+			if _, ok := tempIDs[*input.TempID]; ok && err == nil {
+				err = fmt.Errorf("%w; temp id: %s", ErrDuplicateTempID, *input.TempID)
+			} else if !ok {
+				tempIDs[*input.TempID] = i
+			}
+		}
+		if err != nil {
+			results[i].Error = itemError(err)
+			failed = true
+			continue
+		}
+		comments[i] = *comment
+	}
+	if failed {
+		return abortBatch(results, func(r *model.CreateCommentResult) **model.BatchItemError { return &r.Error }), nil
+	}
+
+	ids, err := s.storage.SaveComments(ctx, comments)
+	if err != nil {
+		var batchErr *storage.BatchError
+		if !errors.As(err, &batchErr) || batchErr.Index < 0 || batchErr.Index >= len(results) {
+			return nil, ErrInternal
+		}
+		results[batchErr.Index].Error = itemError(commentError(batchErr.Err, comments[batchErr.Index].Comment))
+		return abortBatch(results, func(r *model.CreateCommentResult) **model.BatchItemError { return &r.Error }), nil
+	}
+
+	_, broadcastSpan := tracer.Start(ctx, "Subscription.Broadcast")
+	defer broadcastSpan.End()
+	for i, comment := range comments {
+		comment.ID = ids[i]
+		comment.Version = entity.FirstVersion
+		if comment.ParentIndex != nil {
+			comment.ParentCommentID = &ids[*comment.ParentIndex]
+		}
+		results[i].Comment = convertCommentEntityIntoModel(comment.Comment)
+		s.subscriptions.Broadcast(comment.PostID, results[i].Comment)
+		s.notify(ctx, comment.Comment)
+	}
+	return results, nil
+}
+
+func (s *Service) validateBatchComment(input model.BatchComment, tempIDs map[string]int) (*entity.BatchComment, error) {
+	comment, err := s.ValidateComment(model.NewComment{
+		Text:            input.Text,
+		ParentCommentID: input.ParentCommentID,
+		PostID:          input.PostID,
+		UserID:          input.UserID,
+	})
+	if input.ParentTempID == nil {
+		if err != nil {
+			return nil, err
+		}
+		return &entity.BatchComment{Comment: *comment}, nil
+	}
+
+	var parentErr error
+	parentIndex, ok := tempIDs[*input.ParentTempID]
+	switch {
+	case input.ParentCommentID != nil:
+		parentErr = fmt.Errorf("%w; parentCommentID is also set", ErrInvalidParentTempID)
+	case !ok:
+		parentErr = fmt.Errorf("%w; parent temp id: %s", ErrInvalidParentTempID, *input.ParentTempID)
+	}
+	if err = errors.Join(err, parentErr); err != nil {
+		return nil, err
+	}
+	return &entity.BatchComment{Comment: *comment, ParentIndex: &parentIndex}, nil
+}
+
+// items without their own errors get ABORTED error
+func abortBatch[T any](results []T, errField func(T) **model.BatchItemError) []T {
+	for _, result := range results {
+		if field := errField(result); *field == nil {
+			*field = itemError(ErrBatchAborted)
+		}
+	}
+	return results
+}
+
+func itemError(err error) *model.BatchItemError {
+	kind := ErrorKind(err)
+	if kind == "" {
+		kind = KindInternal
+	}
+	return &model.BatchItemError{Code: kind, Message: err.Error()}
+}
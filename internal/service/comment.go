@@ -31,6 +31,9 @@ func (s *Service) ValidateComment(input model.NewComment) (*entity.Comment, erro
 			errList = append(errList, fmt.Errorf("%w, parent comment id: %s", ErrInvalidID, *input.ParentCommentID))
 		}
 	}
+	if err := validateClientMutationID(input.ClientMutationID); err != nil {
+		errList = append(errList, err)
+	}
 	if len(errList) > 0 {
 		return nil, errors.Join(errList...)
 	}
@@ -38,32 +41,141 @@ func (s *Service) ValidateComment(input model.NewComment) (*entity.Comment, erro
 	return convertNewCommentModelIntoEntity(input), nil
 }
 
-func (s *Service) SaveComment(ctx context.Context, comment entity.Comment) (*model.Comment, error) {
+func (s *Service) SaveComment(ctx context.Context, comment entity.Comment) (_ *model.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SaveComment")
+	defer func() { endSpan(span, err) }()
+
+	// retried mutation returns the original comment, subscribers got it with the first request
+	if comment.ClientMutationID != "" && s.idempotencyWindow > 0 {
+		saved, created, err := s.storage.SaveCommentWithKey(ctx, comment, s.idempotencyWindow)
+		if err != nil {
+			return nil, commentError(err, comment)
+		}
+		target := convertCommentEntityIntoModel(saved)
+		if created {
+			s.broadcast(ctx, saved.PostID, target)
+			s.notify(ctx, saved)
+		}
+		return target, nil
+	}
+
 	id, err := s.storage.SaveComment(ctx, comment)
 	if err != nil {
-		switch {
-		case errors.Is(err, storage.ErrPostNotFound):
-			return nil, fmt.Errorf("%w; post id: %d", ErrPostNotFound, comment.PostID)
-		case errors.Is(err, storage.ErrPostCommentsDisabled):
-			return nil, fmt.Errorf("%w; post id: %d", ErrPostCommentsDisabled, comment.PostID)
-		case errors.Is(err, storage.ErrInvalidParentCommentID):
-			return nil, fmt.Errorf("%w; post id: %d; parent comment id: %d", ErrInvalidParentCommentID, comment.PostID, *comment.ParentCommentID)
-		case errors.Is(err, storage.ErrParentCommentBelongAnotherPost):
-			return nil, fmt.Errorf("%w; current post id: %d", ErrParentCommentBelongAnotherPost, comment.PostID)
-		default:
-			return nil, ErrInternal
-		}
+		return nil, commentError(err, comment)
 	}
 	comment.ID = id
+	comment.Version = entity.FirstVersion
 	target := convertCommentEntityIntoModel(comment)
-	s.subscriptions.Broadcast(comment.PostID, target)
+
+	s.broadcast(ctx, comment.PostID, target)
+	s.notify(ctx, comment)
+
 	return target, nil
 }
 
-func (s *Service) AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*model.Comment, error) {
-	list, err := s.storage.AllComments(ctx, postID, limit, offset)
+func (s *Service) broadcast(ctx context.Context, postID int64, comment *model.Comment) {
+	_, span := tracer.Start(ctx, "Subscription.Broadcast")
+	s.subscriptions.Broadcast(postID, comment)
+	span.End()
+}
+
+// converts storage error into service error
+func commentError(err error, comment entity.Comment) error {
+	switch {
+	case errors.Is(err, storage.ErrPostNotFound):
+		return fmt.Errorf("%w; post id: %d", ErrPostNotFound, comment.PostID)
+	case errors.Is(err, storage.ErrPostCommentsDisabled):
+		return fmt.Errorf("%w; post id: %d", ErrPostCommentsDisabled, comment.PostID)
+	case errors.Is(err, storage.ErrInvalidParentCommentID):
+		if comment.ParentCommentID == nil {
+			return fmt.Errorf("%w; post id: %d", ErrInvalidParentCommentID, comment.PostID)
+		}
+		return fmt.Errorf("%w; post id: %d; parent comment id: %d", ErrInvalidParentCommentID, comment.PostID, *comment.ParentCommentID)
+	case errors.Is(err, storage.ErrParentCommentBelongAnotherPost):
+		return fmt.Errorf("%w; current post id: %d", ErrParentCommentBelongAnotherPost, comment.PostID)
+	default:
+		return ErrInternal
+	}
+}
+
+func (s *Service) ValidateUpdateComment(input model.UpdateComment) (*entity.Edit, error) {
+	var errList []error
+	var edit entity.Edit
+	var err error
+	if len(input.Text) == 0 {
+		errList = append(errList, ErrEmptyBody)
+	}
+	if len([]rune(input.Text)) > 2000 {
+		errList = append(errList, ErrCommentBodyTooBig)
+	}
+	if edit.ID, err = strconv.ParseInt(input.CommentID, 10, 64); err != nil {
+		errList = append(errList, fmt.Errorf("%w, comment id: %s", ErrInvalidID, input.CommentID))
+	}
+	if len(errList) > 0 {
+		return nil, errors.Join(errList...)
+	}
+
+	edit.Text = input.Text
+	edit.ExpectedVersion = int64(input.ExpectedVersion)
+	return &edit, nil
+}
+
+// UpdateComment changes the text by the authenticated user
+func (s *Service) UpdateComment(ctx context.Context, edit entity.Edit) (_ *model.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateComment")
+	defer func() { endSpan(span, err) }()
+
+	if edit.UserID, err = s.AuthenticatedUserID(ctx); err != nil {
+		return nil, err
+	}
+	return s.updateComment(ctx, edit)
+}
+
+// checks rights of edit.UserID and applies the edit
+func (s *Service) updateComment(ctx context.Context, edit entity.Edit) (*model.Comment, error) {
+	edit.Moderator = s.moderators[edit.UserID]
+	comment, err := s.storage.UpdateComment(ctx, edit)
 	if err != nil {
-		return nil, ErrInternal
+		switch {
+		case errors.Is(err, storage.ErrCommentNotFound):
+			return nil, fmt.Errorf("%w; comment id: %d", ErrCommentNotFound, edit.ID)
+		case errors.Is(err, storage.ErrAccess):
+			return nil, fmt.Errorf("%w; userID: %d; commentID: %d", ErrAccess, edit.UserID, edit.ID)
+		case errors.Is(err, storage.ErrVersionConflict):
+			return nil, fmt.Errorf("%w; comment id: %d; expected version: %d", ErrVersionConflict, edit.ID, edit.ExpectedVersion)
+		default:
+			return nil, ErrInternal
+		}
+	}
+	return convertCommentEntityIntoModel(*comment), nil
+}
+
+// after continues the TREE order after the comment of the cursor (from the comment for firstUnreadCursor)
+func (s *Service) AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort model.CommentSort, after *string) (_ []*model.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AllComments")
+	defer func() { endSpan(span, err) }()
+
+	var list []*entity.Comment
+	if after != nil {
+		if sort != model.CommentSortTree {
+			return nil, ErrCursorSort
+		}
+		afterID, inclusive, err := decodeCommentCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		list, err = s.storage.CommentsAfter(ctx, postID, afterID, inclusive, limit, offset)
+		if err != nil {
+			if errors.Is(err, storage.ErrCommentNotFound) {
+				return nil, fmt.Errorf("%w; comment of the cursor does not belong to the post %d", ErrInvalidCursor, postID)
+			}
+			return nil, ErrInternal
+		}
+	} else {
+		list, err = s.storage.AllComments(ctx, postID, limit, offset, convertCommentSort(sort))
+		if err != nil {
+			return nil, ErrInternal
+		}
 	}
 
 	all := make([]*model.Comment, len(list))
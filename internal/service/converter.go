@@ -14,19 +14,27 @@ func convertNewPostModelIntoEntity(newPost model.NewPost) *entity.Post {
 	}
 	//parse error already checked (Validate method)
 	userID, _ := strconv.ParseInt(newPost.UserID, 10, 64)
+	var clientMutationID string
+	if newPost.ClientMutationID != nil {
+		clientMutationID = *newPost.ClientMutationID
+	}
 	return &entity.Post{
-		Text:        newPost.Text,
-		User:        userID,
-		CommentsOFF: disabled,
+		Text:             newPost.Text,
+		User:             userID,
+		CommentsOFF:      disabled,
+		ClientMutationID: clientMutationID,
 	}
 }
 
 func convertPostEntityIntoModel(post entity.Post) *model.Post {
 	return &model.Post{
-		ID:          strconv.FormatInt(post.ID, 10),
-		Text:        post.Text,
-		UserID:      strconv.FormatInt(post.User, 10),
-		CommentsOff: post.CommentsOFF,
+		ID:             strconv.FormatInt(post.ID, 10),
+		Text:           post.Text,
+		UserID:         strconv.FormatInt(post.User, 10),
+		CommentsOff:    post.CommentsOFF,
+		Version:        int(post.Version),
+		CommentCount:   int(post.CommentCount),
+		LastActivityAt: post.LastActivityAt,
 	}
 }
 
@@ -42,6 +50,9 @@ func convertNewCommentModelIntoEntity(newComment model.NewComment) *entity.Comme
 	//parse error already checked (Validate method)
 	comment.PostID, _ = strconv.ParseInt(newComment.PostID, 10, 64)
 	comment.Text = newComment.Text
+	if newComment.ClientMutationID != nil {
+		comment.ClientMutationID = *newComment.ClientMutationID
+	}
 	return &comment
 }
 
@@ -57,5 +68,59 @@ func convertCommentEntityIntoModel(comment entity.Comment) *model.Comment {
 		ParentCommentID: parentCommentID,
 		PostID:          strconv.FormatInt(comment.PostID, 10),
 		UserID:          strconv.FormatInt(comment.UserID, 10),
+		Version:         int(comment.Version),
+		Score:           int(comment.Upvotes - comment.Downvotes),
+		Upvotes:         int(comment.Upvotes),
+		Downvotes:       int(comment.Downvotes),
+		ReplyCount:      int(comment.ReplyCount),
+	}
+}
+
+func convertRevisionEntityIntoModel(revision entity.Revision) *model.Revision {
+	return &model.Revision{
+		Version:   int(revision.Version),
+		Text:      revision.Text,
+		EditorID:  strconv.FormatInt(revision.EditorID, 10),
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func convertReactionSummaryEntityIntoModel(summary entity.ReactionSummary) *model.ReactionSummary {
+	return &model.ReactionSummary{
+		Emoji:            summary.Emoji,
+		Count:            summary.Count,
+		ViewerHasReacted: summary.ViewerHasReacted,
+	}
+}
+
+func convertCommentSort(sort model.CommentSort) string {
+	switch sort {
+	case model.CommentSortTop:
+		return entity.SortTop
+	case model.CommentSortNew:
+		return entity.SortNew
+	case model.CommentSortControversial:
+		return entity.SortControversial
+	default:
+		return entity.SortTree
+	}
+}
+
+func convertNotificationEntityIntoModel(notification entity.Notification) *model.Notification {
+	kind := model.NotificationKindComment
+	switch notification.Kind {
+	case entity.NotificationMention:
+		kind = model.NotificationKindMention
+	case entity.NotificationReply:
+		kind = model.NotificationKindReply
+	}
+	return &model.Notification{
+		ID:        strconv.FormatInt(notification.ID, 10),
+		Kind:      kind,
+		ActorID:   strconv.FormatInt(notification.ActorID, 10),
+		PostID:    strconv.FormatInt(notification.PostID, 10),
+		CommentID: strconv.FormatInt(notification.CommentID, 10),
+		Read:      notification.Read,
+		CreatedAt: notification.CreatedAt,
 	}
 }
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// max value of first argument of the connections
+const MaxPageSize = 100
+
+const defaultPageSize = 10
+
+// Feed returns a page of posts ranked by the algorithm.
+// The first page saves the ranking into a snapshot, cursors point into the snapshot,
+// so next pages are not shifted by new comments and reactions.
+// First pages of the same algorithm share the snapshot during the snapshot interval.
+func (s *Service) Feed(ctx context.Context, algorithm model.FeedAlgorithm, first *int, after *string) (_ *model.PostConnection, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Feed")
+	defer func() { endSpan(span, err) }()
+
+	size, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot *entity.FeedSnapshot
+	var position int
+	if after == nil {
+		if snapshot, err = s.firstFeedSnapshot(ctx, algorithm); err != nil {
+			return nil, err
+		}
+	} else {
+		var id string
+		if id, position, err = decodeCursor(*after); err != nil {
+			return nil, err
+		}
+		if snapshot, err = s.storage.FeedSnapshot(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrFeedSnapshotNotFound) {
+				return nil, ErrInvalidCursor
+			}
+			return nil, ErrInternal
+		}
+		if position > len(snapshot.PostIDs) {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	end := min(position+size, len(snapshot.PostIDs))
+	ids := snapshot.PostIDs[position:end]
+	posts, err := s.storage.PostsByIDs(ctx, ids)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	// cursor of the edge is the position of the next post in the snapshot
+	positions := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		positions[id] = position + i + 1
+	}
+	connection := &model.PostConnection{
+		Edges:    make([]*model.PostEdge, len(posts)),
+		PageInfo: &model.PageInfo{HasNextPage: end < len(snapshot.PostIDs)},
+	}
+	for i, post := range posts {
+		connection.Edges[i] = &model.PostEdge{
+			Cursor: encodeCursor(snapshot.ID, positions[post.ID]),
+			Node:   convertPostEntityIntoModel(*post),
+		}
+	}
+	if end > position {
+		cursor := encodeCursor(snapshot.ID, end)
+		connection.PageInfo.EndCursor = &cursor
+	}
+	return connection, nil
+}
+
+// returns the snapshot of the current interval, the first request of the interval ranks posts
+func (s *Service) firstFeedSnapshot(ctx context.Context, algorithm model.FeedAlgorithm) (*entity.FeedSnapshot, error) {
+	now := time.Now()
+	id := feedSnapshotID(algorithm, now.Truncate(s.feedSnapshotInterval))
+	snapshot, err := s.storage.FeedSnapshot(ctx, id)
+	if err == nil {
+		return snapshot, nil
+	}
+	if !errors.Is(err, storage.ErrFeedSnapshotNotFound) {
+		return nil, ErrInternal
+	}
+
+	query := entity.FeedQuery{Algorithm: entity.FeedHot, Limit: s.feedSnapshotSize}
+	switch algorithm {
+	case model.FeedAlgorithmTopDay:
+		query.Algorithm = entity.FeedTop
+		query.Since = now.Add(-24 * time.Hour)
+	case model.FeedAlgorithmTopWeek:
+		query.Algorithm = entity.FeedTop
+		query.Since = now.Add(-7 * 24 * time.Hour)
+	case model.FeedAlgorithmNew:
+		query.Algorithm = entity.FeedNew
+	}
+
+	ids, err := s.storage.RankedPostIDs(ctx, query)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	// concurrent first pages of the interval get the snapshot saved first
+	snapshot, err = s.storage.SaveFeedSnapshot(ctx, entity.FeedSnapshot{ID: id, PostIDs: ids, ExpiresAt: now.Add(s.feedSnapshotTTL)})
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return snapshot, nil
+}
+
+// PurgeFeedSnapshots deletes expired snapshots of the feed
+func (s *Service) PurgeFeedSnapshots(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.PurgeFeedSnapshots")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.storage.DeleteExpiredFeedSnapshots(ctx); err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+// returns first argument of the connection or the default page size
+func pageSize(first *int) (int, error) {
+	size := defaultPageSize
+	if first != nil {
+		size = *first
+	}
+	if size < 1 || size > MaxPageSize {
+		return 0, ErrInvalidPageSize
+	}
+	return size, nil
+}
+
+// id of the snapshot shared by first pages of the interval, which starts at the time
+func feedSnapshotID(algorithm model.FeedAlgorithm, interval time.Time) string {
+	return strings.ToLower(algorithm.String()) + "." + strconv.FormatInt(interval.UnixNano(), 10)
+}
+
+func encodeCursor(snapshotID string, position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(snapshotID + ":" + strconv.Itoa(position)))
+}
+
+func decodeCursor(cursor string) (snapshotID string, position int, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	snapshotID, value, ok := strings.Cut(string(b), ":")
+	if !ok || snapshotID == "" {
+		return "", 0, ErrInvalidCursor
+	}
+	if position, err = strconv.Atoi(value); err != nil || position < 0 {
+		return "", 0, ErrInvalidCursor
+	}
+	return snapshotID, position, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+func (s *Service) ValidateFollow(userID string) (*entity.Follow, error) {
+	followeeID, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w, user id: %s", ErrInvalidID, userID)
+	}
+	return &entity.Follow{FolloweeID: followeeID}, nil
+}
+
+// Follow adds follow of the authenticated user, with fan-out on write posts of the followee are copied into the home feed
+func (s *Service) Follow(ctx context.Context, follow entity.Follow) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Follow")
+	defer func() { endSpan(span, err) }()
+
+	if follow.FollowerID, err = s.AuthenticatedUserID(ctx); err != nil {
+		return false, err
+	}
+	if follow.FollowerID == follow.FolloweeID {
+		return false, ErrFollowYourself
+	}
+
+	followed, err := s.storage.Follow(ctx, follow, s.homeFanOut)
+	if err != nil {
+		return false, ErrInternal
+	}
+	return followed, nil
+}
+
+// Unfollow removes follow of the authenticated user
+func (s *Service) Unfollow(ctx context.Context, follow entity.Follow) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Unfollow")
+	defer func() { endSpan(span, err) }()
+
+	if follow.FollowerID, err = s.AuthenticatedUserID(ctx); err != nil {
+		return false, err
+	}
+
+	unfollowed, err := s.storage.Unfollow(ctx, follow, s.homeFanOut)
+	if err != nil {
+		return false, ErrInternal
+	}
+	return unfollowed, nil
+}
+
+// Followers returns users following the user, the newest follows first
+func (s *Service) Followers(ctx context.Context, userID int64, first *int, after *string) (_ *model.UserConnection, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Followers")
+	defer func() { endSpan(span, err) }()
+
+	return s.users(ctx, userID, first, after, s.storage.Followers, func(follow entity.Follow) int64 { return follow.FollowerID })
+}
+
+// Following returns users followed by the user, the newest follows first
+func (s *Service) Following(ctx context.Context, userID int64, first *int, after *string) (_ *model.UserConnection, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Following")
+	defer func() { endSpan(span, err) }()
+
+	return s.users(ctx, userID, first, after, s.storage.Following, func(follow entity.Follow) int64 { return follow.FolloweeID })
+}
+
+// loads the page of follows, user is the other side of the follow
+func (s *Service) users(ctx context.Context, userID int64, first *int, after *string,
+	load func(context.Context, int64, entity.Page) ([]entity.Follow, error), user func(entity.Follow) int64) (*model.UserConnection, error) {
+	page, err := keysetPage(first, after)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := load(ctx, userID, page)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	// one more item is loaded to check the next page
+	connection := &model.UserConnection{PageInfo: &model.PageInfo{HasNextPage: len(follows) == page.Limit}}
+	follows = follows[:min(len(follows), page.Limit-1)]
+	connection.Edges = make([]*model.UserEdge, len(follows))
+	for i, follow := range follows {
+		connection.Edges[i] = &model.UserEdge{
+			Cursor:     encodeIDCursor(follow.ID),
+			Node:       &model.User{ID: strconv.FormatInt(user(follow), 10)},
+			FollowedAt: follow.CreatedAt,
+		}
+	}
+	if len(follows) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(follows)-1].Cursor
+	}
+	return connection, nil
+}
+
+// HomeFeed returns posts of the users followed by the authenticated user, the newest first
+func (s *Service) HomeFeed(ctx context.Context, first *int, after *string) (_ *model.PostConnection, err error) {
+	ctx, span := tracer.Start(ctx, "Service.HomeFeed")
+	defer func() { endSpan(span, err) }()
+
+	userID, err := s.AuthenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := keysetPage(first, after)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.storage.HomeFeed(ctx, userID, page, s.homeFanOut)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	// one more item is loaded to check the next page
+	connection := &model.PostConnection{PageInfo: &model.PageInfo{HasNextPage: len(posts) == page.Limit}}
+	posts = posts[:min(len(posts), page.Limit-1)]
+	connection.Edges = make([]*model.PostEdge, len(posts))
+	for i, post := range posts {
+		connection.Edges[i] = &model.PostEdge{
+			Cursor: encodeIDCursor(post.ID),
+			Node:   convertPostEntityIntoModel(*post),
+		}
+	}
+	if len(posts) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(posts)-1].Cursor
+	}
+	return connection, nil
+}
+
+// AuthenticatedUserID returns id of the authenticated user or ErrUnauthenticated
+func (s *Service) AuthenticatedUserID(ctx context.Context) (int64, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return 0, ErrUnauthenticated
+	}
+	return userID, nil
+}
+
+// adds the new post into home feeds of followers of the author: timelines (fan-out on write)
+// and newPostsInFeed subscriptions; the post is already saved, so errors are only logged
+// (`reconcile timelines` command adds the missed posts into timelines)
+func (s *Service) publishPost(ctx context.Context, post entity.Post) {
+	if s.homeFanOut == entity.FanOutWrite {
+		if err := s.storage.PushToTimelines(ctx, post); err != nil {
+			slog.WarnContext(ctx, "failed to push post into timelines", slog.Int64("post_id", post.ID), slog.Any("error", err))
+		}
+	}
+	if s.subscriptions.Feed.Count() == 0 {
+		return
+	}
+
+	followers, err := s.storage.FollowerIDs(ctx, post.User)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load followers for feed update", slog.Int64("post_id", post.ID), slog.Any("error", err))
+		return
+	}
+	_, span := tracer.Start(ctx, "Subscription.Broadcast")
+	defer span.End()
+	target := convertPostEntityIntoModel(post)
+	for _, followerID := range followers {
+		s.subscriptions.Feed.Broadcast(followerID, target)
+	}
+}
+
+// page of the keyset pagination: cursor is id of the last item of the previous page,
+// limit includes one more item to check the next page
+func keysetPage(first *int, after *string) (entity.Page, error) {
+	size, err := pageSize(first)
+	if err != nil {
+		return entity.Page{}, err
+	}
+	page := entity.Page{Limit: size + 1}
+	if after != nil {
+		if page.AfterID, err = decodeIDCursor(*after); err != nil {
+			return entity.Page{}, err
+		}
+	}
+	return page, nil
+}
+
+func encodeIDCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeIDCursor(cursor string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import "errors"
+
+// kinds of the service errors
+const (
+	KindInvalidInput       = "INVALID_INPUT"
+	KindUnauthenticated    = "UNAUTHENTICATED"
+	KindNotFound           = "NOT_FOUND"
+	KindForbidden          = "FORBIDDEN"
+	KindFailedPrecondition = "FAILED_PRECONDITION"
+	// update is based on the stale version
+	KindConflict = "CONFLICT"
+	KindInternal = "INTERNAL"
+	// item of the batch is not saved because of another item error
+	KindAborted = "ABORTED"
+)
+
+var kinds = []struct {
+	err  error
+	kind string
+}{
+	{ErrInternal, KindInternal},
+	{ErrInvalidID, KindInvalidInput},
+	{ErrEmptyBody, KindInvalidInput},
+	{ErrCommentBodyTooBig, KindInvalidInput},
+	{ErrBatchTooLarge, KindInvalidInput},
+	{ErrDuplicateTempID, KindInvalidInput},
+	{ErrInvalidClientMutationID, KindInvalidInput},
+	{ErrInvalidParentTempID, KindInvalidInput},
+	{ErrBatchAborted, KindAborted},
+	{ErrPostNotFound, KindNotFound},
+	{ErrInvalidParentCommentID, KindNotFound},
+	{ErrCommentNotFound, KindNotFound},
+	{ErrRevisionNotFound, KindNotFound},
+	{ErrInvalidRestoreTarget, KindInvalidInput},
+	{ErrInvalidEmoji, KindInvalidInput},
+	{ErrInvalidPageSize, KindInvalidInput},
+	{ErrInvalidCursor, KindInvalidInput},
+	{ErrFollowYourself, KindInvalidInput},
+	{ErrInvalidNotificationIDs, KindInvalidInput},
+	{ErrInvalidContextSize, KindInvalidInput},
+	{ErrCursorSort, KindInvalidInput},
+	{ErrUnauthenticated, KindUnauthenticated},
+	{ErrVersionConflict, KindConflict},
+	{ErrAccess, KindForbidden},
+	{ErrPostCommentsDisabled, KindFailedPrecondition},
+	{ErrParentCommentBelongAnotherPost, KindFailedPrecondition},
+}
+
+// ErrorKind returns kind of the service error, empty string if err is not a service error
+func ErrorKind(err error) string {
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return ""
+}
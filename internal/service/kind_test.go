@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKind(t *testing.T) {
+	assert.Equal(t, KindNotFound, ErrorKind(fmt.Errorf("%w; post id: %d", ErrPostNotFound, 1)))
+	assert.Equal(t, KindInvalidInput, ErrorKind(errors.Join(ErrEmptyBody, ErrInvalidID)))
+	assert.Equal(t, KindForbidden, ErrorKind(ErrAccess))
+	assert.Equal(t, KindInternal, ErrorKind(ErrInternal))
+	assert.Equal(t, KindAborted, ErrorKind(ErrBatchAborted))
+	assert.Equal(t, KindInvalidInput, ErrorKind(fmt.Errorf("%w; temp id: %s", ErrDuplicateTempID, "a")))
+	assert.Equal(t, KindConflict, ErrorKind(fmt.Errorf("%w; post id: %d", ErrVersionConflict, 1)))
+	assert.Equal(t, KindNotFound, ErrorKind(ErrCommentNotFound))
+	assert.Equal(t, KindNotFound, ErrorKind(ErrRevisionNotFound))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrInvalidRestoreTarget))
+	assert.Equal(t, KindUnauthenticated, ErrorKind(ErrUnauthenticated))
+	assert.Equal(t, KindInvalidInput, ErrorKind(fmt.Errorf("%w; emoji: %q", ErrInvalidEmoji, "a")))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrInvalidPageSize))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrInvalidCursor))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrFollowYourself))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrInvalidNotificationIDs))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrInvalidContextSize))
+	assert.Equal(t, KindInvalidInput, ErrorKind(ErrCursorSort))
+	assert.Equal(t, "", ErrorKind(errors.New("unknown error")))
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+// users have no handles, so the user is mentioned by id (@42); the mention is not a part of a word (e.g. email)
+var mentionPattern = regexp.MustCompile(`\B@(\d+)\b`)
+
+// max number of notified users mentioned in one comment
+const maxMentions = 20
+
+// returns ids of mentioned users in the order of the first mention
+func parseMentions(text string) []int64 {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		id, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		if len(ids) == maxMentions {
+			break
+		}
+	}
+	return ids
+}
+
+// notifies mentioned users, author of the parent comment and author of the post about the new comment,
+// each user gets one notification (mention, then reply, then comment), the author of the comment is not notified;
+// the comment is already saved, so errors are only logged
+func (s *Service) notify(ctx context.Context, comment entity.Comment) {
+	notified := map[int64]bool{comment.UserID: true}
+	var notifications []entity.Notification
+	add := func(userID int64, kind string) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		notifications = append(notifications, entity.Notification{
+			UserID:    userID,
+			Kind:      kind,
+			ActorID:   comment.UserID,
+			PostID:    comment.PostID,
+			CommentID: comment.ID,
+		})
+	}
+
+	for _, userID := range parseMentions(comment.Text) {
+		add(userID, entity.NotificationMention)
+	}
+	if comment.ParentCommentID != nil {
+		parent, err := s.storage.CommentByID(ctx, *comment.ParentCommentID)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load parent comment for notification",
+				slog.Int64("comment_id", comment.ID), slog.Any("error", err))
+		} else {
+			add(parent.UserID, entity.NotificationReply)
+		}
+	}
+	post, err := s.storage.PostByID(ctx, comment.PostID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load post for notification",
+			slog.Int64("comment_id", comment.ID), slog.Any("error", err))
+	} else {
+		add(post.User, entity.NotificationComment)
+	}
+	if len(notifications) == 0 {
+		return
+	}
+
+	saved, err := s.storage.SaveNotifications(ctx, notifications)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to save notifications",
+			slog.Int64("comment_id", comment.ID), slog.Any("error", err))
+		return
+	}
+	_, span := tracer.Start(ctx, "Subscription.Broadcast")
+	defer span.End()
+	for _, notification := range saved {
+		s.subscriptions.Notifications.Broadcast(notification.UserID, convertNotificationEntityIntoModel(notification))
+	}
+}
+
+// Notifications returns notifications of the authenticated user, the newest first
+func (s *Service) Notifications(ctx context.Context, first *int, after *string, unreadOnly bool) (_ *model.NotificationConnection, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Notifications")
+	defer func() { endSpan(span, err) }()
+
+	userID, err := s.AuthenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := keysetPage(first, after)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := s.storage.Notifications(ctx, userID, page, unreadOnly)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	// one more item is loaded to check the next page
+	connection := &model.NotificationConnection{PageInfo: &model.PageInfo{HasNextPage: len(notifications) == page.Limit}}
+	notifications = notifications[:min(len(notifications), page.Limit-1)]
+	connection.Edges = make([]*model.NotificationEdge, len(notifications))
+	for i, notification := range notifications {
+		connection.Edges[i] = &model.NotificationEdge{
+			Cursor: encodeIDCursor(notification.ID),
+			Node:   convertNotificationEntityIntoModel(notification),
+		}
+	}
+	if len(notifications) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(notifications)-1].Cursor
+	}
+	return connection, nil
+}
+
+// nil ids mark all notifications
+func (s *Service) ValidateMarkNotificationsRead(ids []string) ([]int64, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	if len(ids) > MaxPageSize {
+		return nil, ErrInvalidNotificationIDs
+	}
+	parsed := make([]int64, len(ids))
+	for i, id := range ids {
+		var err error
+		if parsed[i], err = strconv.ParseInt(id, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w, notification id: %s", ErrInvalidID, id)
+		}
+	}
+	return parsed, nil
+}
+
+// MarkNotificationsRead marks notifications of the authenticated user as read, returns number of marked ones
+func (s *Service) MarkNotificationsRead(ctx context.Context, ids []int64) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Service.MarkNotificationsRead")
+	defer func() { endSpan(span, err) }()
+
+	userID, err := s.AuthenticatedUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	marked, err := s.storage.MarkNotificationsRead(ctx, userID, ids)
+	if err != nil {
+		return 0, ErrInternal
+	}
+	return marked, nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int64
+	}{
+		{text: "@1 and @2, thanks", want: []int64{1, 2}},
+		{text: "(@3) @3 @4!", want: []int64{3, 4}},
+		{text: "mail me: user@5.com", want: nil},
+		{text: "@6abc @ 7 @", want: nil},
+		{text: "no mentions", want: nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseMentions(tt.text), tt.text)
+	}
+
+	var many strings.Builder
+	for i := 1; i <= 2*maxMentions; i++ {
+		fmt.Fprintf(&many, "@%d ", i)
+	}
+	assert.Len(t, parseMentions(many.String()), maxMentions)
+}
@@ -20,6 +20,9 @@ func (s *Service) ValidatePost(input model.NewPost) (*entity.Post, error) {
 	if err != nil {
 		errList = append(errList, fmt.Errorf("%w; user id: %s", ErrInvalidID, input.UserID))
 	}
+	if err := validateClientMutationID(input.ClientMutationID); err != nil {
+		errList = append(errList, err)
+	}
 	if len(errList) > 0 {
 		return nil, errors.Join(errList...)
 	}
@@ -27,14 +30,87 @@ func (s *Service) ValidatePost(input model.NewPost) (*entity.Post, error) {
 	return convertNewPostModelIntoEntity(input), nil
 }
 
-func (s *Service) SavePost(ctx context.Context, post entity.Post) (*model.Post, error) {
-	postID, err := s.storage.SavePost(ctx, post)
+func (s *Service) SavePost(ctx context.Context, post entity.Post) (_ *model.Post, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SavePost")
+	defer func() { endSpan(span, err) }()
+
+	if post.ClientMutationID != "" && s.idempotencyWindow > 0 {
+		saved, created, err := s.storage.SavePostWithKey(ctx, post, s.idempotencyWindow)
+		if err != nil {
+			return nil, ErrInternal
+		}
+		if created {
+			s.publishPost(ctx, saved)
+		}
+		return convertPostEntityIntoModel(saved), nil
+	}
+
+	saved, err := s.storage.SavePost(ctx, post)
 	if err != nil {
 		return nil, ErrInternal
 	}
 
-	post.ID = postID
-	return convertPostEntityIntoModel(post), nil
+	s.publishPost(ctx, saved)
+	return convertPostEntityIntoModel(saved), nil
+}
+
+func validateClientMutationID(id *string) error {
+	if id == nil {
+		return nil
+	}
+	if len(*id) == 0 || len(*id) > maxClientMutationIDLen {
+		return ErrInvalidClientMutationID
+	}
+	return nil
+}
+
+func (s *Service) ValidateUpdatePost(input model.UpdatePost) (*entity.Edit, error) {
+	var errList []error
+	var edit entity.Edit
+	var err error
+	if len(input.Text) == 0 {
+		errList = append(errList, ErrEmptyBody)
+	}
+	if edit.ID, err = strconv.ParseInt(input.PostID, 10, 64); err != nil {
+		errList = append(errList, fmt.Errorf("%w, post id: %s", ErrInvalidID, input.PostID))
+	}
+	if len(errList) > 0 {
+		return nil, errors.Join(errList...)
+	}
+
+	edit.Text = input.Text
+	edit.ExpectedVersion = int64(input.ExpectedVersion)
+	return &edit, nil
+}
+
+// UpdatePost changes the text by the authenticated user
+func (s *Service) UpdatePost(ctx context.Context, edit entity.Edit) (_ *model.Post, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdatePost")
+	defer func() { endSpan(span, err) }()
+
+	if edit.UserID, err = s.AuthenticatedUserID(ctx); err != nil {
+		return nil, err
+	}
+	return s.updatePost(ctx, edit)
+}
+
+// checks rights of edit.UserID and applies the edit
+func (s *Service) updatePost(ctx context.Context, edit entity.Edit) (*model.Post, error) {
+	edit.Moderator = s.moderators[edit.UserID]
+	post, err := s.storage.UpdatePost(ctx, edit)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrPostNotFound):
+			return nil, fmt.Errorf("%w; post id: %d", ErrPostNotFound, edit.ID)
+		case errors.Is(err, storage.ErrAccess):
+			return nil, fmt.Errorf("%w; userID: %d; postID: %d", ErrAccess, edit.UserID, edit.ID)
+		case errors.Is(err, storage.ErrVersionConflict):
+			return nil, fmt.Errorf("%w; post id: %d; expected version: %d", ErrVersionConflict, edit.ID, edit.ExpectedVersion)
+		default:
+			return nil, ErrInternal
+		}
+	}
+	return convertPostEntityIntoModel(*post), nil
 }
 
 func (s *Service) ValidateDisableCommentsRequest(input model.DisableCommentsRequest) (userID int64, postID int64, err error) {
@@ -49,7 +125,10 @@ func (s *Service) ValidateDisableCommentsRequest(input model.DisableCommentsRequ
 	return userID, postID, err
 }
 
-func (s *Service) DisableComments(ctx context.Context, userID int64, postID int64) error {
+func (s *Service) DisableComments(ctx context.Context, userID int64, postID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.DisableComments")
+	defer func() { endSpan(span, err) }()
+
 	if err := s.storage.DisableComments(ctx, userID, postID); err != nil {
 		switch {
 		case errors.Is(err, storage.ErrPostNotFound):
@@ -65,7 +144,10 @@ func (s *Service) DisableComments(ctx context.Context, userID int64, postID int6
 	return nil
 }
 
-func (s *Service) PostById(ctx context.Context, ID int64) (*model.Post, error) {
+func (s *Service) PostById(ctx context.Context, ID int64) (_ *model.Post, err error) {
+	ctx, span := tracer.Start(ctx, "Service.PostById")
+	defer func() { endSpan(span, err) }()
+
 	post, err := s.storage.PostByID(ctx, ID)
 	if err != nil {
 		switch {
@@ -78,7 +160,10 @@ func (s *Service) PostById(ctx context.Context, ID int64) (*model.Post, error) {
 	return convertPostEntityIntoModel(*post), nil
 }
 
-func (s *Service) AllPosts(ctx context.Context) ([]*model.Post, error) {
+func (s *Service) AllPosts(ctx context.Context) (_ []*model.Post, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AllPosts")
+	defer func() { endSpan(span, err) }()
+
 	list, err := s.storage.AllPosts(ctx)
 	if err != nil {
 		return nil, ErrInternal
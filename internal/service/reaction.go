@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// max length of emoji in bytes (emoji with modifiers and zero width joiners)
+const maxEmojiLen = 32
+
+func (s *Service) ValidateReaction(targetID string, targetType model.ReactionTargetType, emoji string) (*entity.Reaction, error) {
+	var errList []error
+	var reaction entity.Reaction
+	var err error
+	if reaction.TargetID, err = strconv.ParseInt(targetID, 10, 64); err != nil {
+		errList = append(errList, fmt.Errorf("%w, target id: %s", ErrInvalidID, targetID))
+	}
+	if !isEmoji(emoji) {
+		errList = append(errList, fmt.Errorf("%w; emoji: %q", ErrInvalidEmoji, emoji))
+	}
+	if len(errList) > 0 {
+		return nil, errors.Join(errList...)
+	}
+
+	reaction.TargetType = convertReactionTargetType(targetType)
+	reaction.Emoji = emoji
+	return &reaction, nil
+}
+
+// emoji is a sequence of symbols with modifiers, variation selectors and joiners
+func isEmoji(emoji string) bool {
+	if len(emoji) == 0 || len(emoji) > maxEmojiLen || !utf8.ValidString(emoji) {
+		return false
+	}
+	var symbol bool
+	for _, r := range emoji {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbol = true
+		case unicode.In(r, unicode.Sk, unicode.Mn, unicode.Me, unicode.Cf):
+		default:
+			return false
+		}
+	}
+	return symbol
+}
+
+func convertReactionTargetType(targetType model.ReactionTargetType) string {
+	if targetType == model.ReactionTargetTypeComment {
+		return entity.TargetComment
+	}
+	return entity.TargetPost
+}
+
+// React adds reaction of the authenticated user, subscribers of the post get the comment with the new reaction
+func (s *Service) React(ctx context.Context, reaction entity.Reaction) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "Service.React")
+	defer func() { endSpan(span, err) }()
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return false, ErrUnauthenticated
+	}
+	reaction.UserID = userID
+
+	added, err := s.storage.AddReaction(ctx, reaction)
+	if err != nil {
+		return false, reactionError(err, reaction)
+	}
+	if added {
+		s.broadcastReaction(ctx, reaction)
+	}
+	return added, nil
+}
+
+// Unreact removes reaction of the authenticated user
+func (s *Service) Unreact(ctx context.Context, reaction entity.Reaction) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Unreact")
+	defer func() { endSpan(span, err) }()
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return false, ErrUnauthenticated
+	}
+	reaction.UserID = userID
+
+	removed, err := s.storage.RemoveReaction(ctx, reaction)
+	if err != nil {
+		return false, reactionError(err, reaction)
+	}
+	if removed {
+		s.broadcastReaction(ctx, reaction)
+	}
+	return removed, nil
+}
+
+func reactionError(err error, reaction entity.Reaction) error {
+	switch {
+	case errors.Is(err, storage.ErrPostNotFound):
+		return fmt.Errorf("%w; post id: %d", ErrPostNotFound, reaction.TargetID)
+	case errors.Is(err, storage.ErrCommentNotFound):
+		return fmt.Errorf("%w; comment id: %d", ErrCommentNotFound, reaction.TargetID)
+	default:
+		return ErrInternal
+	}
+}
+
+// comment subscription carries comments, so only reactions on comments are pushed
+func (s *Service) broadcastReaction(ctx context.Context, reaction entity.Reaction) {
+	if reaction.TargetType != entity.TargetComment {
+		return
+	}
+	comment, err := s.storage.CommentByID(ctx, reaction.TargetID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load comment for reaction update",
+			slog.Int64("comment_id", reaction.TargetID), slog.Any("error", err))
+		return
+	}
+	s.broadcast(ctx, comment.PostID, convertCommentEntityIntoModel(*comment))
+}
+
+// ReactionSummaries returns reactions of the targets for the authenticated user (viewer)
+func (s *Service) ReactionSummaries(ctx context.Context, targetType model.ReactionTargetType, targetIDs []int64) (_ map[int64][]*model.ReactionSummary, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ReactionSummaries")
+	defer func() { endSpan(span, err) }()
+
+	var viewerID *int64
+	if userID, ok := auth.UserID(ctx); ok {
+		viewerID = &userID
+	}
+	summaries, err := s.storage.ReactionSummaries(ctx, convertReactionTargetType(targetType), targetIDs, viewerID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	all := make(map[int64][]*model.ReactionSummary, len(summaries))
+	for id, list := range summaries {
+		all[id] = make([]*model.ReactionSummary, len(list))
+		for i, summary := range list {
+			all[id][i] = convertReactionSummaryEntityIntoModel(summary)
+		}
+	}
+	return all, nil
+}
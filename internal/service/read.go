@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// upToCommentID is nil to mark all comments of the post
+func (s *Service) ValidateMarkPostRead(postID string, upToCommentID *string) (*entity.ReadMark, error) {
+	var errList []error
+	var mark entity.ReadMark
+	var err error
+	if mark.PostID, err = strconv.ParseInt(postID, 10, 64); err != nil {
+		errList = append(errList, fmt.Errorf("%w, post id: %s", ErrInvalidID, postID))
+	}
+	if upToCommentID != nil {
+		if mark.CommentID, err = strconv.ParseInt(*upToCommentID, 10, 64); err != nil || mark.CommentID <= 0 {
+			errList = append(errList, fmt.Errorf("%w, comment id: %s", ErrInvalidID, *upToCommentID))
+		}
+	}
+	if len(errList) > 0 {
+		return nil, errors.Join(errList...)
+	}
+	return &mark, nil
+}
+
+// MarkPostRead moves the read mark of the authenticated user forward, returns the post
+func (s *Service) MarkPostRead(ctx context.Context, mark entity.ReadMark) (_ *model.Post, err error) {
+	ctx, span := tracer.Start(ctx, "Service.MarkPostRead")
+	defer func() { endSpan(span, err) }()
+
+	if mark.UserID, err = s.AuthenticatedUserID(ctx); err != nil {
+		return nil, err
+	}
+	if err = s.storage.MarkPostRead(ctx, mark); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrPostNotFound):
+			return nil, fmt.Errorf("%w; post id: %d", ErrPostNotFound, mark.PostID)
+		case errors.Is(err, storage.ErrCommentNotFound):
+			return nil, fmt.Errorf("%w; post id: %d; comment id: %d", ErrCommentNotFound, mark.PostID, mark.CommentID)
+		default:
+			return nil, ErrInternal
+		}
+	}
+	return s.PostById(ctx, mark.PostID)
+}
+
+// UnreadComments returns unread comments of the posts for the authenticated user, nil for anonymous requests
+func (s *Service) UnreadComments(ctx context.Context, postIDs []int64) (_ map[int64]*model.UnreadComments, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UnreadComments")
+	defer func() { endSpan(span, err) }()
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, nil
+	}
+	unread, err := s.storage.UnreadComments(ctx, userID, postIDs)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	all := make(map[int64]*model.UnreadComments, len(postIDs))
+	for _, id := range postIDs {
+		all[id] = &model.UnreadComments{Count: unread[id].Count}
+		if unread[id].Count > 0 {
+			cursor := encodeUnreadCursor(unread[id].FirstCommentID)
+			all[id].FirstUnreadCursor = &cursor
+		}
+	}
+	return all, nil
+}
+
+// firstUnreadCursor includes its comment, so comments(after: firstUnreadCursor) starts with the oldest unread comment;
+// cursors of the comments (commentContext) continue after their comment
+const unreadCursorPrefix = "unread:"
+
+func encodeUnreadCursor(commentID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(unreadCursorPrefix + strconv.FormatInt(commentID, 10)))
+}
+
+// returns id of the comment of the cursor and true, if the comment is included (firstUnreadCursor)
+func decodeCommentCursor(cursor string) (int64, bool, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false, ErrInvalidCursor
+	}
+	value, inclusive := strings.CutPrefix(string(b), unreadCursorPrefix)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false, ErrInvalidCursor
+	}
+	return id, inclusive, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+func (s *Service) PostRevisions(ctx context.Context, postID int64) (_ []*model.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "Service.PostRevisions")
+	defer func() { endSpan(span, err) }()
+
+	list, err := s.storage.PostRevisions(ctx, postID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return convertRevisions(list), nil
+}
+
+func (s *Service) CommentRevisions(ctx context.Context, commentID int64) (_ []*model.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CommentRevisions")
+	defer func() { endSpan(span, err) }()
+
+	list, err := s.storage.CommentRevisions(ctx, commentID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return convertRevisions(list), nil
+}
+
+func convertRevisions(list []entity.Revision) []*model.Revision {
+	all := make([]*model.Revision, len(list))
+	for i, revision := range list {
+		all[i] = convertRevisionEntityIntoModel(revision)
+	}
+	return all
+}
+
+func (s *Service) ValidateRestoreRevision(input model.RestoreRevision) (*entity.Restore, error) {
+	var errList []error
+	var restore entity.Restore
+	var err error
+	if (input.PostID == nil) == (input.CommentID == nil) {
+		errList = append(errList, ErrInvalidRestoreTarget)
+	}
+	if input.PostID != nil {
+		if restore.PostID, err = strconv.ParseInt(*input.PostID, 10, 64); err != nil {
+			errList = append(errList, fmt.Errorf("%w, post id: %s", ErrInvalidID, *input.PostID))
+		}
+	}
+	if input.CommentID != nil {
+		if restore.CommentID, err = strconv.ParseInt(*input.CommentID, 10, 64); err != nil {
+			errList = append(errList, fmt.Errorf("%w, comment id: %s", ErrInvalidID, *input.CommentID))
+		}
+	}
+	if len(errList) > 0 {
+		return nil, errors.Join(errList...)
+	}
+
+	restore.Version = int64(input.Version)
+	restore.ExpectedVersion = int64(input.ExpectedVersion)
+	return &restore, nil
+}
+
+// RestoreRevision updates the post (comment) with the text of the revision by the authenticated user,
+// access and expected version are checked by the update, revisions are never changed
+func (s *Service) RestoreRevision(ctx context.Context, restore entity.Restore) (_ *model.RestoreRevisionResult, err error) {
+	ctx, span := tracer.Start(ctx, "Service.RestoreRevision")
+	defer func() { endSpan(span, err) }()
+
+	if restore.UserID, err = s.AuthenticatedUserID(ctx); err != nil {
+		return nil, err
+	}
+	edit := entity.Edit{UserID: restore.UserID, ExpectedVersion: restore.ExpectedVersion}
+	if restore.PostID != 0 {
+		revisions, err := s.storage.PostRevisions(ctx, restore.PostID)
+		if err != nil {
+			return nil, ErrInternal
+		}
+		revision, ok := findRevision(revisions, restore.Version)
+		if !ok {
+			return nil, fmt.Errorf("%w; post id: %d; version: %d", ErrRevisionNotFound, restore.PostID, restore.Version)
+		}
+		edit.ID, edit.Text = restore.PostID, revision.Text
+		post, err := s.updatePost(ctx, edit)
+		if err != nil {
+			return nil, err
+		}
+		return &model.RestoreRevisionResult{Post: post}, nil
+	}
+
+	revisions, err := s.storage.CommentRevisions(ctx, restore.CommentID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	revision, ok := findRevision(revisions, restore.Version)
+	if !ok {
+		return nil, fmt.Errorf("%w; comment id: %d; version: %d", ErrRevisionNotFound, restore.CommentID, restore.Version)
+	}
+	edit.ID, edit.Text = restore.CommentID, revision.Text
+	comment, err := s.updateComment(ctx, edit)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RestoreRevisionResult{Comment: comment}, nil
+}
+
+func findRevision(revisions []entity.Revision, version int64) (entity.Revision, bool) {
+	for _, revision := range revisions {
+		if revision.Version == version {
+			return revision, true
+		}
+	}
+	return entity.Revision{}, false
+}
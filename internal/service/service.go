@@ -3,6 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/dkrasnykh/graphql-app/internal/entity"
 	"github.com/dkrasnykh/graphql-app/internal/subscription"
@@ -18,28 +24,201 @@ var (
 	ErrPostCommentsDisabled           = errors.New("сomments are turned off")
 	ErrInvalidParentCommentID         = errors.New("there is no comment with ParentCommentID for this post")
 	ErrParentCommentBelongAnotherPost = errors.New("parent comment belong another post")
+	ErrBatchTooLarge                  = fmt.Errorf("batch should not exceed %d items", MaxBatchSize)
+	ErrBatchAborted                   = errors.New("batch is not saved because of another item error")
+	ErrDuplicateTempID                = errors.New("temp id is used by another item of the batch")
+	ErrInvalidParentTempID            = errors.New("parent temp id should reference a comment declared earlier in the batch")
+	ErrCommentNotFound                = errors.New("comment with id does not exist")
+	ErrVersionConflict                = errors.New("version is changed by another update, reload and retry")
+	ErrRevisionNotFound               = errors.New("revision with version does not exist")
+	ErrInvalidRestoreTarget           = errors.New("either post id or comment id should be set")
+	ErrUnauthenticated                = errors.New("user is not authenticated")
+	ErrInvalidEmoji                   = fmt.Errorf("emoji should be a single emoji up to %d bytes", maxEmojiLen)
+	ErrInvalidClientMutationID        = fmt.Errorf("client mutation id should not be empty or exceed %d characters", maxClientMutationIDLen)
+	ErrInvalidPageSize                = fmt.Errorf("first should be from 1 to %d", MaxPageSize)
+	ErrInvalidCursor                  = errors.New("cursor is invalid or expired, load the first page again")
+	ErrFollowYourself                 = errors.New("user can not follow themselves")
+	ErrInvalidNotificationIDs         = fmt.Errorf("ids should contain up to %d notification ids", MaxPageSize)
+	ErrInvalidContextSize             = fmt.Errorf("ancestors and descendants should be from 0 to %d", MaxPageSize)
+	ErrCursorSort                     = errors.New("after cursor is supported only with TREE sort")
 )
 
+const maxClientMutationIDLen = 255
+
+// max number of items in createPosts and createComments batches
+const MaxBatchSize = 1000
+
+var tracer = otel.Tracer("github.com/dkrasnykh/graphql-app/internal/service")
+
 type Storager interface {
-	SavePost(ctx context.Context, post entity.Post) (int64, error)
+	// returns the saved post with id, version and creation time (last activity)
+	SavePost(ctx context.Context, post entity.Post) (entity.Post, error)
+	// saves post with ClientMutationID, if the user saved a post with the same key within window,
+	// returns the existing post and created = false
+	SavePostWithKey(ctx context.Context, post entity.Post, window time.Duration) (_ entity.Post, created bool, _ error)
+	// saves all posts in one transaction, returns them in the same order as SavePost
+	SavePosts(ctx context.Context, posts []entity.Post) ([]entity.Post, error)
 	PostByID(ctx context.Context, id int64) (*entity.Post, error)
 	AllPosts(ctx context.Context) ([]*entity.Post, error)
+	// changes text and increases version, returns storage.ErrVersionConflict if the version is changed
+	UpdatePost(ctx context.Context, edit entity.Edit) (*entity.Post, error)
+	// returns revisions ordered by version
+	PostRevisions(ctx context.Context, postID int64) ([]entity.Revision, error)
 	DisableComments(ctx context.Context, userID int64, postID int64) error
+	// returns posts in the order of ids, missing posts are skipped
+	PostsByIDs(ctx context.Context, ids []int64) ([]*entity.Post, error)
+
+	// returns ids of posts ranked by the algorithm of the query, scores are updated on save of comments and reactions
+	RankedPostIDs(ctx context.Context, query entity.FeedQuery) ([]int64, error)
+	// saves the snapshot, if the snapshot with the same id is already saved, returns the saved one
+	SaveFeedSnapshot(ctx context.Context, snapshot entity.FeedSnapshot) (*entity.FeedSnapshot, error)
+	// returns storage.ErrFeedSnapshotNotFound if the snapshot does not exist or expired
+	FeedSnapshot(ctx context.Context, id string) (*entity.FeedSnapshot, error)
+	DeleteExpiredFeedSnapshots(ctx context.Context) error
+
+	// adds the follow, returns false if the user already follows the followee;
+	// with entity.FanOutWrite posts of the followee are copied into the timeline of the follower
+	Follow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error)
+	// removes the follow, returns false if there is no such follow;
+	// with entity.FanOutWrite posts of the followee are removed from the timeline of the follower
+	Unfollow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error)
+	// returns follows of the followers of the user, the newest first
+	Followers(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error)
+	// returns follows of the user, the newest first
+	Following(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error)
+	FollowerIDs(ctx context.Context, userID int64) ([]int64, error)
+	// copies the post into timelines of followers of its author (fan-out on write)
+	PushToTimelines(ctx context.Context, post entity.Post) error
+	// returns posts of the followed users, the newest first: from the timeline of the user (entity.FanOutWrite)
+	// or from posts of the followed users (entity.FanOutRead)
+	HomeFeed(ctx context.Context, userID int64, page entity.Page, fanOut string) ([]*entity.Post, error)
+
+	// saves notifications, returns them with ids and creation time
+	SaveNotifications(ctx context.Context, notifications []entity.Notification) ([]entity.Notification, error)
+	// returns notifications of the user, the newest first
+	Notifications(ctx context.Context, userID int64, page entity.Page, unreadOnly bool) ([]entity.Notification, error)
+	// marks notifications of the user with ids (all notifications if ids is nil) as read, returns number of marked ones
+	MarkNotificationsRead(ctx context.Context, userID int64, ids []int64) (int, error)
+
+	// moves the read mark of the user forward to the comment (to the last comment of the post if CommentID is 0)
+	MarkPostRead(ctx context.Context, mark entity.ReadMark) error
+	// returns comments after the read marks of the user, posts without unread comments are missing
+	UnreadComments(ctx context.Context, userID int64, postIDs []int64) (map[int64]entity.Unread, error)
 
 	SaveComment(ctx context.Context, comment entity.Comment) (int64, error)
-	AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*entity.Comment, error)
+	// saves comment with ClientMutationID, if the user saved a comment with the same key within window,
+	// returns the existing comment and created = false
+	SaveCommentWithKey(ctx context.Context, comment entity.Comment, window time.Duration) (_ entity.Comment, created bool, _ error)
+	// saves all comments in one transaction or returns *storage.BatchError of the first failed item
+	SaveComments(ctx context.Context, comments []entity.BatchComment) ([]int64, error)
+	AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort string) ([]*entity.Comment, error)
+	// returns comments of the post following the comment afterID in the TREE order (starting with the comment
+	// if inclusive), returns storage.ErrCommentNotFound if the comment does not belong to the post
+	CommentsAfter(ctx context.Context, postID int64, afterID int64, inclusive bool, limit *int, offset *int) ([]*entity.Comment, error)
+	// returns up to ancestors nearest ancestors of the comment and up to descendants first comments of its subtree
+	CommentContext(ctx context.Context, id int64, ancestors int, descendants int) (*entity.CommentContext, error)
+	Vote(ctx context.Context, vote entity.Vote) (*entity.Comment, error)
+	UpdateComment(ctx context.Context, edit entity.Edit) (*entity.Comment, error)
+	// returns revisions ordered by version
+	CommentRevisions(ctx context.Context, commentID int64) ([]entity.Revision, error)
+
+	CommentByID(ctx context.Context, id int64) (*entity.Comment, error)
+
+	// adds the reaction, returns false if the user already added the emoji to the target
+	AddReaction(ctx context.Context, reaction entity.Reaction) (bool, error)
+	// removes the reaction, returns false if there is no such reaction
+	RemoveReaction(ctx context.Context, reaction entity.Reaction) (bool, error)
+	// returns reactions of the targets ordered by count (desc) and emoji, viewerID is nil for anonymous requests
+	ReactionSummaries(ctx context.Context, targetType string, targetIDs []int64, viewerID *int64) (map[int64][]entity.ReactionSummary, error)
+
+	// returns error if storage is not ready to serve requests
+	Health(ctx context.Context) error
 
 	Clear() // for unit tests (implemented only for memory storage)
 }
 
+// defaults of the ranked feed, see WithFeed
+const (
+	DefaultFeedSnapshotSize     = 1000
+	DefaultFeedSnapshotTTL      = time.Hour
+	DefaultFeedSnapshotInterval = time.Minute
+)
+
+// default time, during which repeat of create mutation with the same client mutation id returns the original result
+const DefaultIdempotencyWindow = 24 * time.Hour
+
 type Service struct {
-	storage       Storager
-	subscriptions *subscription.Subscription
+	storage           Storager
+	subscriptions     *subscription.Subscription
+	idempotencyWindow time.Duration
+	// ids of users, who can edit and restore posts and comments of other users
+	moderators map[int64]bool
+	// max number of posts in the feed and time during which its next pages can be loaded
+	feedSnapshotSize int
+	feedSnapshotTTL  time.Duration
+	// first pages of the same algorithm share the snapshot during the interval
+	feedSnapshotInterval time.Duration
+	// strategy of the home feed: entity.FanOutWrite or entity.FanOutRead
+	homeFanOut string
+}
+
+type Option func(s *Service)
+
+// WithIdempotencyWindow sets time, during which client mutation id is kept, 0 turns off idempotency keys
+func WithIdempotencyWindow(window time.Duration) Option {
+	return func(s *Service) {
+		s.idempotencyWindow = window
+	}
+}
+
+func WithModerators(userIDs []int64) Option {
+	return func(s *Service) {
+		for _, id := range userIDs {
+			s.moderators[id] = true
+		}
+	}
+}
+
+// WithFeed sets depth of the ranked feed, time during which its cursors are valid
+// and interval during which its first pages are served from the same snapshot
+func WithFeed(snapshotSize int, snapshotTTL, snapshotInterval time.Duration) Option {
+	return func(s *Service) {
+		s.feedSnapshotSize = snapshotSize
+		s.feedSnapshotTTL = snapshotTTL
+		s.feedSnapshotInterval = snapshotInterval
+	}
+}
+
+// WithHomeFanOut sets strategy of the home feed, timelines are filled only with entity.FanOutWrite,
+// so after switching from read to write timelines are rebuilt by `reconcile timelines` command
+func WithHomeFanOut(strategy string) Option {
+	return func(s *Service) {
+		s.homeFanOut = strategy
+	}
+}
+
+func New(storage Storager, subscriptions *subscription.Subscription, opts ...Option) *Service {
+	s := &Service{
+		storage:              storage,
+		subscriptions:        subscriptions,
+		idempotencyWindow:    DefaultIdempotencyWindow,
+		moderators:           make(map[int64]bool),
+		feedSnapshotSize:     DefaultFeedSnapshotSize,
+		feedSnapshotTTL:      DefaultFeedSnapshotTTL,
+		feedSnapshotInterval: DefaultFeedSnapshotInterval,
+		homeFanOut:           entity.FanOutWrite,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func New(storage Storager, subscriptions *subscription.Subscription) *Service {
-	return &Service{
-		storage:       storage,
-		subscriptions: subscriptions,
+// marks span as failed, if service method returns error
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
+	span.End()
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+const defaultContextSize = 10
+
+func (s *Service) CommentByID(ctx context.Context, id int64) (_ *model.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CommentByID")
+	defer func() { endSpan(span, err) }()
+
+	comment, err := s.storage.CommentByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrCommentNotFound):
+			return nil, fmt.Errorf("%w; comment id: %d", ErrCommentNotFound, id)
+		default:
+			return nil, ErrInternal
+		}
+	}
+	return convertCommentEntityIntoModel(*comment), nil
+}
+
+// CommentContext returns the comment with its nearest ancestors and the first comments of its subtree,
+// cursor of the last returned comment continues the thread with comments(after: cursor)
+func (s *Service) CommentContext(ctx context.Context, id int64, ancestors *int, descendants *int) (_ *model.CommentContext, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CommentContext")
+	defer func() { endSpan(span, err) }()
+
+	ancestorsSize, err := contextSize(ancestors)
+	if err != nil {
+		return nil, err
+	}
+	descendantsSize, err := contextSize(descendants)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more descendant to check the rest of the subtree
+	thread, err := s.storage.CommentContext(ctx, id, ancestorsSize, descendantsSize+1)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrCommentNotFound):
+			return nil, fmt.Errorf("%w; comment id: %d", ErrCommentNotFound, id)
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	target := &model.CommentContext{
+		Comment:     convertCommentEntityIntoModel(thread.Comment),
+		Ancestors:   make([]*model.Comment, len(thread.Ancestors)),
+		Descendants: make([]*model.Comment, 0, min(len(thread.Descendants), descendantsSize)),
+		Cursor:      encodeIDCursor(thread.Comment.ID),
+	}
+	for i, ancestor := range thread.Ancestors {
+		target.Ancestors[i] = convertCommentEntityIntoModel(*ancestor)
+	}
+	// there are more ancestors while the farthest returned one is not a root comment
+	target.HasMoreAncestors = thread.Comment.ParentCommentID != nil
+	if len(thread.Ancestors) > 0 {
+		target.HasMoreAncestors = thread.Ancestors[0].ParentCommentID != nil
+	}
+
+	target.HasMoreDescendants = len(thread.Descendants) > descendantsSize
+	for _, descendant := range thread.Descendants[:min(len(thread.Descendants), descendantsSize)] {
+		target.Descendants = append(target.Descendants, convertCommentEntityIntoModel(*descendant))
+		target.Cursor = encodeIDCursor(descendant.ID)
+	}
+	return target, nil
+}
+
+// returns ancestors (descendants) argument of the comment context or the default size
+func contextSize(size *int) (int, error) {
+	if size == nil {
+		return defaultContextSize, nil
+	}
+	if *size < 0 || *size > MaxPageSize {
+		return 0, ErrInvalidContextSize
+	}
+	return *size, nil
+}
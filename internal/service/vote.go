@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+	"github.com/dkrasnykh/graphql-app/internal/auth"
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (s *Service) ValidateVote(commentID string, value model.VoteValue) (*entity.Vote, error) {
+	id, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w, comment id: %s", ErrInvalidID, commentID)
+	}
+
+	vote := entity.Vote{CommentID: id, Value: entity.VoteNone}
+	switch value {
+	case model.VoteValueUp:
+		vote.Value = entity.VoteUp
+	case model.VoteValueDown:
+		vote.Value = entity.VoteDown
+	}
+	return &vote, nil
+}
+
+// Vote replaces the vote of the authenticated user and returns the comment with the new score
+func (s *Service) Vote(ctx context.Context, vote entity.Vote) (_ *model.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Vote")
+	defer func() { endSpan(span, err) }()
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	vote.UserID = userID
+
+	comment, err := s.storage.Vote(ctx, vote)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrCommentNotFound):
+			return nil, fmt.Errorf("%w; comment id: %d", ErrCommentNotFound, vote.CommentID)
+		default:
+			return nil, ErrInternal
+		}
+	}
+	return convertCommentEntityIntoModel(*comment), nil
+}
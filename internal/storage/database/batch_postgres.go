@@ -0,0 +1,265 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// SavePosts reserves ids from the sequence and inserts all posts with COPY
+func (s *StoragePostgres) SavePosts(ctx context.Context, posts []entity.Post) ([]entity.Post, error) {
+	const op = "Storage.postgresql.SavePosts"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	ids, err := nextIDs(newCtx, tx, "posts", len(posts))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	now := createdAt()
+	rows := make([][]any, len(posts))
+	for i, post := range posts {
+		rows[i] = []any{ids[i], post.Text, post.User, post.CommentsOFF, now, now, storage.HotTerm(now)}
+	}
+	_, err = tx.CopyFrom(newCtx, pgx.Identifier{"posts"},
+		[]string{"id", "text", "user_id", "is_comments_disabled", "created_at", "last_activity_at", "hot"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		slog.ErrorContext(newCtx, "failed to copy posts", slog.String("op", op), slog.Any("error", err))
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	revisions := make([][]any, len(posts))
+	for i, post := range posts {
+		revisions[i] = []any{ids[i], entity.FirstVersion, post.Text, post.User}
+	}
+	_, err = tx.CopyFrom(newCtx, pgx.Identifier{"post_revisions"},
+		[]string{"post_id", "version", "text", "editor_id"}, pgx.CopyFromRows(revisions))
+	if err != nil {
+		slog.ErrorContext(newCtx, "failed to copy post revisions", slog.String("op", op), slog.Any("error", err))
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	saved := make([]entity.Post, len(posts))
+	for i, post := range posts {
+		post.ID = ids[i]
+		post.Version = entity.FirstVersion
+		post.LastActivityAt = now
+		saved[i] = post
+	}
+	return saved, nil
+}
+
+type parentComment struct {
+	postID int64
+	rank   string
+}
+
+// SaveComments locks posts and parent comments of the batch, checks all comments,
+// builds ranks for reserved ids and inserts all comments with COPY
+func (s *StoragePostgres) SaveComments(ctx context.Context, comments []entity.BatchComment) ([]int64, error) {
+	const op = "Storage.postgresql.SaveComments"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	postIDs := make([]int64, 0, len(comments))
+	parentIDs := make([]int64, 0)
+	for _, comment := range comments {
+		postIDs = append(postIDs, comment.PostID)
+		if comment.ParentIndex == nil && comment.ParentCommentID != nil {
+			parentIDs = append(parentIDs, *comment.ParentCommentID)
+		}
+	}
+
+	// rows are locked in the order of ids to avoid deadlocks between batches
+	disabled := make(map[int64]bool)
+	hot := make(map[int64]float64)
+	rows, err := tx.Query(newCtx,
+		"SELECT id, COALESCE(is_comments_disabled, false), hot FROM posts WHERE id = ANY($1) ORDER BY id FOR UPDATE",
+		uniqueIDs(postIDs))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	var id int64
+	var isDisabled bool
+	var postHot float64
+	_, err = pgx.ForEachRow(rows, []any{&id, &isDisabled, &postHot}, func() error {
+		disabled[id] = isDisabled
+		hot[id] = postHot
+		return nil
+	})
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	parents := make(map[int64]parentComment)
+	if len(parentIDs) > 0 {
+		rows, err = tx.Query(newCtx,
+			"SELECT id, post_id, rank FROM comments WHERE id = ANY($1) ORDER BY id FOR UPDATE",
+			uniqueIDs(parentIDs))
+		if err != nil {
+			return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+		var parent parentComment
+		_, err = pgx.ForEachRow(rows, []any{&id, &parent.postID, &parent.rank}, func() error {
+			parents[id] = parent
+			return nil
+		})
+		if err != nil {
+			return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+	}
+
+	for i := range comments {
+		if err := checkBatchComment(comments, i, disabled, parents); err != nil {
+			return nil, rollback(newCtx, tx, op, &storage.BatchError{Index: i, Err: err})
+		}
+	}
+
+	ids, err := nextIDs(newCtx, tx, "comments", len(comments))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	// build ranks (rank needed for pagination data sorting), parent of the batch comment has lower index
+	ranks := make([]string, len(comments))
+	copyRows := make([][]any, len(comments))
+	for i, comment := range comments {
+		parentCommentID := comment.ParentCommentID
+		var rank string
+		switch {
+		case comment.ParentIndex != nil:
+			parentCommentID = &ids[*comment.ParentIndex]
+			rank = ranks[*comment.ParentIndex] + "-"
+		case comment.ParentCommentID != nil:
+			rank = parents[*comment.ParentCommentID].rank + "-"
+		}
+		ranks[i] = rank + fmt.Sprintf("%019d", ids[i])
+		copyRows[i] = []any{ids[i], comment.Text, comment.UserID, comment.PostID, parentCommentID, ranks[i]}
+	}
+
+	_, err = tx.CopyFrom(newCtx, pgx.Identifier{"comments"},
+		[]string{"id", "text", "user_id", "post_id", "parent_comment_id", "rank"}, pgx.CopyFromRows(copyRows))
+	if err != nil {
+		slog.ErrorContext(newCtx, "failed to copy comments", slog.String("op", op), slog.Any("error", err))
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	revisions := make([][]any, len(comments))
+	for i, comment := range comments {
+		revisions[i] = []any{ids[i], entity.FirstVersion, comment.Text, comment.UserID}
+	}
+	_, err = tx.CopyFrom(newCtx, pgx.Identifier{"comment_revisions"},
+		[]string{"comment_id", "version", "text", "editor_id"}, pgx.CopyFromRows(revisions))
+	if err != nil {
+		slog.ErrorContext(newCtx, "failed to copy comment revisions", slog.String("op", op), slog.Any("error", err))
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	// post rows and parent comment rows are locked above, comments of the batch are not visible to others yet
+	counts := make(map[int64]int)
+	replies := make(map[int64]int64)
+	for _, comment := range comments {
+		counts[comment.PostID] += 1
+		switch {
+		case comment.ParentIndex != nil:
+			replies[ids[*comment.ParentIndex]] += 1
+		case comment.ParentCommentID != nil:
+			replies[*comment.ParentCommentID] += 1
+		}
+	}
+	now := time.Now()
+	for postID, n := range counts {
+		if err = addActivity(newCtx, tx, postID, hot[postID], n, now); err != nil {
+			return nil, rollback(newCtx, tx, op, err)
+		}
+		if err = addComments(newCtx, tx, postID, n); err != nil {
+			return nil, rollback(newCtx, tx, op, err)
+		}
+	}
+	if err = addReplies(newCtx, tx, replies); err != nil {
+		return nil, rollback(newCtx, tx, op, err)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return nil, storage.ErrInternal
+	}
+	return ids, nil
+}
+
+func checkBatchComment(comments []entity.BatchComment, i int, disabled map[int64]bool, parents map[int64]parentComment) error {
+	comment := comments[i]
+
+	isDisabled, ok := disabled[comment.PostID]
+	if !ok {
+		return storage.ErrPostNotFound
+	}
+	if isDisabled {
+		return storage.ErrPostCommentsDisabled
+	}
+
+	switch {
+	case comment.ParentIndex != nil:
+		if *comment.ParentIndex < 0 || *comment.ParentIndex >= i {
+			return storage.ErrInvalidParentCommentID
+		}
+		if parent := comments[*comment.ParentIndex]; parent.PostID != comment.PostID {
+			return fmt.Errorf("%w; parent comment post id: %d", storage.ErrParentCommentBelongAnotherPost, parent.PostID)
+		}
+	case comment.ParentCommentID != nil:
+		parent, ok := parents[*comment.ParentCommentID]
+		if !ok {
+			return storage.ErrInvalidParentCommentID
+		}
+		if parent.postID != comment.PostID {
+			return fmt.Errorf("%w; parent comment post id: %d", storage.ErrParentCommentBelongAnotherPost, parent.postID)
+		}
+	}
+	return nil
+}
+
+// reserves n ids from the sequence of the table
+func nextIDs(ctx context.Context, tx pgx.Tx, table string, n int) ([]int64, error) {
+	rows, err := tx.Query(ctx, "SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)", table, n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// rolls back the transaction and returns err
+func rollback(ctx context.Context, tx pgx.Tx, op string, err error) error {
+	if rbErr := tx.Rollback(ctx); rbErr != nil {
+		slog.ErrorContext(ctx, "transaction rollback error", slog.String("op", op), slog.Any("error", rbErr))
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestSavePosts_OK() {
+	ctx := context.Background()
+	userID := rand.Int63()
+
+	posts := []entity.Post{
+		{Text: "awesome post 1", User: userID},
+		{Text: "awesome post 2", User: userID, CommentsOFF: true},
+	}
+	saved, err := ts.SavePosts(ctx, posts)
+	ts.Require().NoError(err)
+	ts.Require().Len(saved, 2)
+	ts.Less(saved[0].ID, saved[1].ID)
+
+	for i := range saved {
+		post, err := ts.PostByID(ctx, saved[i].ID)
+		ts.Require().NoError(err)
+		ts.Equal(posts[i].Text, post.Text)
+		ts.Equal(posts[i].CommentsOFF, post.CommentsOFF)
+		ts.Equal(post.Version, saved[i].Version)
+		ts.True(post.LastActivityAt.Equal(saved[i].LastActivityAt))
+	}
+}
+
+/*
+batch comments reference parents by index:
+
+"awesome post" (id: 1)
+|
+|-> "comment 1" (saved before the batch)
+|	|
+|	|-> "comment 4" (batch item 2, parent_comment_id: comment 1)
+|
+|-> "comment 2" (batch item 0)
+|	|
+|	|-> "comment 3" (batch item 1, parent index: 0)
+|	|	|
+|	|	|-> "comment 5" (batch item 3, parent index: 1)
+*/
+func (ts *StoragerTestSuite) TestSaveComments_OK() {
+	ctx := context.Background()
+	userID := rand.Int63()
+
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: userID}))
+	ts.Require().NoError(err)
+	commentID1, err := ts.SaveComment(ctx, entity.Comment{Text: "comment 1", UserID: userID, PostID: postID})
+	ts.Require().NoError(err)
+
+	index0, index1 := 0, 1
+	comments := []entity.BatchComment{
+		{Comment: entity.Comment{Text: "comment 2", UserID: userID, PostID: postID}},
+		{Comment: entity.Comment{Text: "comment 3", UserID: userID, PostID: postID}, ParentIndex: &index0},
+		{Comment: entity.Comment{Text: "comment 4", ParentCommentID: &commentID1, UserID: userID, PostID: postID}},
+		{Comment: entity.Comment{Text: "comment 5", UserID: userID, PostID: postID}, ParentIndex: &index1},
+	}
+	ids, err := ts.SaveComments(ctx, comments)
+	ts.Require().NoError(err)
+	ts.Require().Len(ids, 4)
+
+	limit, offset := 10, 0
+	list, err := ts.AllComments(ctx, postID, &limit, &offset, entity.SortTree)
+	ts.Require().NoError(err)
+
+	texts := make([]string, len(list))
+	for i, c := range list {
+		texts[i] = c.Text
+	}
+	ts.Equal([]string{"comment 1", "comment 4", "comment 2", "comment 3", "comment 5"}, texts)
+
+	ts.Equal(ids[0], *list[3].ParentCommentID)
+	ts.Equal(ids[1], *list[4].ParentCommentID)
+}
+
+func (ts *StoragerTestSuite) TestSaveComments_ItemError() {
+	ctx := context.Background()
+	userID := rand.Int63()
+
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: userID}))
+	ts.Require().NoError(err)
+	closedPostID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "closed post", User: userID, CommentsOFF: true}))
+	ts.Require().NoError(err)
+
+	comments := []entity.BatchComment{
+		{Comment: entity.Comment{Text: "comment 1", UserID: userID, PostID: postID}},
+		{Comment: entity.Comment{Text: "comment 2", UserID: userID, PostID: closedPostID}},
+	}
+	_, err = ts.SaveComments(ctx, comments)
+
+	var batchErr *storage.BatchError
+	ts.Require().True(errors.As(err, &batchErr))
+	ts.Equal(1, batchErr.Index)
+	ts.ErrorIs(err, storage.ErrPostCommentsDisabled)
+
+	// nothing is saved
+	limit, offset := 10, 0
+	list, err := ts.AllComments(ctx, postID, &limit, &offset, entity.SortTree)
+	ts.Require().NoError(err)
+	ts.Empty(list)
+}
+
+func (ts *StoragerTestSuite) TestSaveComments_ParentIndexAnotherPost() {
+	ctx := context.Background()
+	userID := rand.Int63()
+
+	postID1, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post 1", User: userID}))
+	ts.Require().NoError(err)
+	postID2, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post 2", User: userID}))
+	ts.Require().NoError(err)
+
+	index0 := 0
+	comments := []entity.BatchComment{
+		{Comment: entity.Comment{Text: "comment 1", UserID: userID, PostID: postID1}},
+		{Comment: entity.Comment{Text: "comment 2", UserID: userID, PostID: postID2}, ParentIndex: &index0},
+	}
+	_, err = ts.SaveComments(ctx, comments)
+	ts.ErrorIs(err, storage.ErrParentCommentBelongAnotherPost)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -27,14 +28,28 @@ func (s *StoragePostgres) SaveComment(ctx context.Context, comment entity.Commen
 		return 0, storage.ErrInternal
 	}
 
+	id, err := insertComment(newCtx, tx, comment)
+	if err != nil {
+		return 0, rollback(newCtx, tx, op, err)
+	}
+
+	err = tx.Commit(newCtx)
+	if err != nil {
+		return 0, storage.ErrInternal
+	}
+
+	return id, nil
+}
+
+// checks post and parent comment under row locks and inserts comment with its rank,
+// the caller owns the transaction
+func insertComment(ctx context.Context, tx pgx.Tx, comment entity.Comment) (int64, error) {
 	// check that the post exists and comments are enabled
 	var isDisabled bool
-	row := tx.QueryRow(ctx, "SELECT is_comments_disabled FROM posts WHERE id = $1 FOR UPDATE", comment.PostID)
-	err = row.Scan(&isDisabled)
+	var hot float64
+	row := tx.QueryRow(ctx, "SELECT is_comments_disabled, hot FROM posts WHERE id = $1 FOR UPDATE", comment.PostID)
+	err := row.Scan(&isDisabled, &hot)
 	if err != nil {
-		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
-		}
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, storage.ErrPostNotFound
 		}
@@ -42,9 +57,6 @@ func (s *StoragePostgres) SaveComment(ctx context.Context, comment entity.Commen
 	}
 
 	if isDisabled {
-		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
-		}
 		return 0, storage.ErrPostCommentsDisabled
 	}
 
@@ -60,18 +72,12 @@ func (s *StoragePostgres) SaveComment(ctx context.Context, comment entity.Commen
 		row := tx.QueryRow(ctx, "SELECT post_id, rank FROM comments WHERE id = $1 FOR UPDATE", *comment.ParentCommentID)
 		err = row.Scan(&parentPostID, &parentRank)
 		if err != nil {
-			if err := tx.Rollback(newCtx); err != nil {
-				slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
-			}
 			if errors.Is(err, pgx.ErrNoRows) {
 				return 0, storage.ErrInvalidParentCommentID
 			}
 			return 0, storage.ErrInternal
 		}
 		if parentPostID != comment.PostID {
-			if err := tx.Rollback(newCtx); err != nil {
-				slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
-			}
 			return 0, fmt.Errorf("%w; parent comment post id: %d", storage.ErrParentCommentBelongAnotherPost, parentPostID)
 		}
 
@@ -81,14 +87,11 @@ func (s *StoragePostgres) SaveComment(ctx context.Context, comment entity.Commen
 
 	// insert new comment
 	var id int64
-	row = tx.QueryRow(context.Background(),
+	row = tx.QueryRow(ctx,
 		"INSERT INTO comments (text, user_id, post_id, parent_comment_id) values ($1, $2, $3, $4) RETURNING id",
 		comment.Text, comment.UserID, comment.PostID, comment.ParentCommentID)
 	err = row.Scan(&id)
 	if err != nil {
-		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
-		}
 		return 0, storage.ErrInternal
 	}
 
@@ -99,29 +102,132 @@ func (s *StoragePostgres) SaveComment(ctx context.Context, comment entity.Commen
 	// update current comment
 	_, err = tx.Exec(ctx, "UPDATE comments SET rank = $1 WHERE id = $2", string(rank), id)
 	if err != nil {
-		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
-		}
 		return 0, storage.ErrInternal
 	}
+	if err = insertCommentRevision(ctx, tx, id, entity.FirstVersion, comment.Text, comment.UserID); err != nil {
+		return 0, err
+	}
+	// the post row and the parent comment row are locked above
+	if err = addActivity(ctx, tx, comment.PostID, hot, 1, time.Now()); err != nil {
+		return 0, err
+	}
+	if err = addComments(ctx, tx, comment.PostID, 1); err != nil {
+		return 0, err
+	}
+	if comment.ParentCommentID != nil {
+		if err = addReplies(ctx, tx, map[int64]int64{*comment.ParentCommentID: 1}); err != nil {
+			return 0, err
+		}
+	}
 
-	err = tx.Commit(newCtx)
+	return id, nil
+}
+
+// the row is locked until the end of transaction, so version can not be changed between check and update
+func (s *StoragePostgres) UpdateComment(ctx context.Context, edit entity.Edit) (*entity.Comment, error) {
+	const op = "Storage.postgresql.UpdateComment"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
 	if err != nil {
-		return 0, storage.ErrInternal
+		return nil, storage.ErrInternal
 	}
 
-	return id, nil
+	var userID, version int64
+	row := tx.QueryRow(newCtx, "SELECT user_id, version FROM comments WHERE id = $1 FOR UPDATE", edit.ID)
+	if err = row.Scan(&userID, &version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, rollback(newCtx, tx, op, storage.ErrCommentNotFound)
+		}
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if userID != edit.UserID && !edit.Moderator {
+		return nil, rollback(newCtx, tx, op, fmt.Errorf("%w, author ID:%d", storage.ErrAccess, userID))
+	}
+	if version != edit.ExpectedVersion {
+		return nil, rollback(newCtx, tx, op, fmt.Errorf("%w; current version: %d", storage.ErrVersionConflict, version))
+	}
+
+	comment, err := scanComment(tx.QueryRow(newCtx,
+		"UPDATE comments SET text = $1, version = version + 1 WHERE id = $2 RETURNING "+commentColumns, edit.Text, edit.ID))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if err = insertCommentRevision(newCtx, tx, comment.ID, comment.Version, comment.Text, edit.UserID); err != nil {
+		return nil, rollback(newCtx, tx, op, err)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return nil, storage.ErrInternal
+	}
+	return comment, nil
+}
+
+const commentColumns = "id, text, user_id, post_id, parent_comment_id, version, upvotes, downvotes, reply_count"
+
+func scanComment(row pgx.Row) (*entity.Comment, error) {
+	var comment entity.Comment
+	var parentCommentID sql.NullInt64
+	if err := row.Scan(&comment.ID, &comment.Text, &comment.UserID, &comment.PostID, &parentCommentID, &comment.Version,
+		&comment.Upvotes, &comment.Downvotes, &comment.ReplyCount); err != nil {
+		return nil, err
+	}
+	if parentCommentID.Valid {
+		comment.ParentCommentID = &parentCommentID.Int64
+	}
+	return &comment, nil
+}
+
+// sibling order of the sorts, ties are resolved by creation order (oldest first)
+var commentOrders = map[string]string{
+	entity.SortTop: "upvotes - downvotes DESC, id",
+	entity.SortNew: "id DESC",
+	entity.SortControversial: `CASE WHEN upvotes = 0 OR downvotes = 0 THEN 0
+		ELSE power(upvotes + downvotes, LEAST(upvotes, downvotes)::float8 / GREATEST(upvotes, downvotes)) END DESC, id`,
 }
 
 // default values limit = 10, offset = 0 (graphql schema)
-func (s *StoragePostgres) AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*entity.Comment, error) {
+func (s *StoragePostgres) AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort string) ([]*entity.Comment, error) {
 	const op = "Storage.postgresql.AllComments"
 
 	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	// TODO query can be more simply, if storing an additional field in the database (id root comment value)
+	order, ok := commentOrders[sort]
+	if !ok {
+		return s.treeComments(newCtx, postID, limit, offset)
+	}
+
+	// the path of the comment is built from positions of its ancestors among their siblings at query time,
+	// so a vote changes only counters of one row and never rewrites stored paths (rank) of the subtree
 	rows, err := s.db.Query(newCtx,
+		`WITH RECURSIVE siblings AS (
+				SELECT id, parent_comment_id, ROW_NUMBER() OVER (PARTITION BY parent_comment_id ORDER BY `+order+`) AS position
+				FROM comments WHERE post_id = $1
+			), tree AS (
+				SELECT id, ARRAY[position] AS path FROM siblings WHERE parent_comment_id IS NULL
+				UNION ALL
+				SELECT siblings.id, tree.path || siblings.position FROM siblings JOIN tree ON siblings.parent_comment_id = tree.id
+			)
+			SELECT c.id, c.text, c.user_id, c.post_id, c.parent_comment_id, c.version, c.upvotes, c.downvotes, c.reply_count
+			FROM tree JOIN comments AS c ON tree.id = c.id
+			ORDER BY tree.path OFFSET $2 LIMIT $3;`,
+		postID, *offset, *limit)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	return scanComments(newCtx, op, rows)
+}
+
+// comments in the order of creation, ordered by the stored path (rank)
+func (s *StoragePostgres) treeComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*entity.Comment, error) {
+	const op = "Storage.postgresql.treeComments"
+
+	// TODO query can be more simply, if storing an additional field in the database (id root comment value)
+	rows, err := s.db.Query(ctx,
 		`WITH RECURSIVE tmp(comment_id, parent_id, root) AS (
 				SELECT t1.comment_id, t1.parent_id, t1.comment_id AS root
 				FROM (SELECT id AS comment_id, parent_comment_id AS parent_id FROM comments WHERE parent_comment_id IS NULL AND post_id = $1) AS t1
@@ -129,7 +235,7 @@ func (s *StoragePostgres) AllComments(ctx context.Context, postID int64, limit *
     			SELECT t2.comment_id, t2.parent_id, tmp.root
     			FROM (SELECT id AS comment_id, parent_comment_id AS parent_id FROM comments WHERE post_id = $1) AS t2 JOIN tmp ON tmp.comment_id = t2.parent_id
 			)
-			SELECT c.id, c.text, c.user_id, c.post_id, c.parent_comment_id 
+			SELECT c.id, c.text, c.user_id, c.post_id, c.parent_comment_id, c.version, c.upvotes, c.downvotes, c.reply_count
 			FROM tmp LEFT JOIN comments AS c ON tmp.comment_id = c.id 
 			ORDER BY tmp.root, c.rank OFFSET $2 LIMIT $3;`,
 		postID, *offset, *limit)
@@ -137,6 +243,12 @@ func (s *StoragePostgres) AllComments(ctx context.Context, postID int64, limit *
 		return nil, storage.ErrInternal
 	}
 
+	return scanComments(ctx, op, rows)
+}
+
+func scanComments(ctx context.Context, op string, rows pgx.Rows) ([]*entity.Comment, error) {
+	defer rows.Close()
+
 	if rows.Err() != nil && !errors.Is(rows.Err(), pgx.ErrNoRows) {
 		return nil, storage.ErrInternal
 	}
@@ -146,9 +258,9 @@ func (s *StoragePostgres) AllComments(ctx context.Context, postID int64, limit *
 	for rows.Next() {
 		var c entity.Comment
 		var parentCommentID sql.NullInt64
-		err := rows.Scan(&c.ID, &c.Text, &c.UserID, &c.PostID, &parentCommentID)
+		err := rows.Scan(&c.ID, &c.Text, &c.UserID, &c.PostID, &parentCommentID, &c.Version, &c.Upvotes, &c.Downvotes, &c.ReplyCount)
 		if err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w failed to parse selection row from database", op, err)
+			slog.ErrorContext(ctx, "failed to parse selection row from database", slog.String("op", op), slog.Any("error", err))
 		}
 		if parentCommentID.Valid {
 			c.ParentCommentID = &parentCommentID.Int64
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// adds n new comments to the counter of the post, the comments are created at the time of the transaction
+// (as their first revisions)
+func addComments(ctx context.Context, tx pgx.Tx, postID int64, n int) error {
+	_, err := tx.Exec(ctx,
+		"UPDATE posts SET comment_count = comment_count + $1, last_activity_at = GREATEST(last_activity_at, now()) WHERE id = $2",
+		n, postID)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// adds new replies to the counters of the parent comments
+func addReplies(ctx context.Context, tx pgx.Tx, replies map[int64]int64) error {
+	if len(replies) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(replies))
+	counts := make([]int64, 0, len(replies))
+	for id, n := range replies {
+		ids = append(ids, id)
+		counts = append(counts, n)
+	}
+	_, err := tx.Exec(ctx,
+		`UPDATE comments SET reply_count = reply_count + r.n
+		FROM unnest($1::bigint[], $2::bigint[]) AS r(id, n)
+		WHERE comments.id = r.id`,
+		ids, counts)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// ReconcileCounters recomputes comment counts and last activity of posts and reply counts of comments
+// from the comments table. Posts are locked (EXCLUSIVE mode allows reads), so new comments wait for the end
+// of the reconciliation; the query timeout is not applied, ctx limits the run
+func (s *StoragePostgres) ReconcileCounters(ctx context.Context) (entity.Reconciled, error) {
+	const op = "Storage.postgresql.ReconcileCounters"
+
+	var reconciled entity.Reconciled
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return reconciled, storage.ErrInternal
+	}
+
+	if _, err = tx.Exec(ctx, "LOCK TABLE posts IN EXCLUSIVE MODE"); err != nil {
+		return reconciled, rollback(ctx, tx, op, storage.ErrInternal)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`WITH counts AS (
+			SELECT p.id, count(c.id) AS comment_count, GREATEST(p.created_at, max(r.created_at)) AS last_activity_at
+			FROM posts AS p
+			LEFT JOIN comments AS c ON c.post_id = p.id
+			LEFT JOIN comment_revisions AS r ON r.comment_id = c.id AND r.version = 1
+			GROUP BY p.id
+		)
+		UPDATE posts SET comment_count = counts.comment_count, last_activity_at = counts.last_activity_at
+		FROM counts
+		WHERE counts.id = posts.id
+			AND (posts.comment_count <> counts.comment_count OR posts.last_activity_at <> counts.last_activity_at)`)
+	if err != nil {
+		return reconciled, rollback(ctx, tx, op, storage.ErrInternal)
+	}
+	reconciled.Posts = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx,
+		`WITH replies AS (
+			SELECT c.id, count(r.id) AS reply_count
+			FROM comments AS c LEFT JOIN comments AS r ON r.parent_comment_id = c.id
+			GROUP BY c.id
+		)
+		UPDATE comments SET reply_count = replies.reply_count
+		FROM replies
+		WHERE replies.id = comments.id AND comments.reply_count <> replies.reply_count`)
+	if err != nil {
+		return reconciled, rollback(ctx, tx, op, storage.ErrInternal)
+	}
+	reconciled.Comments = tag.RowsAffected()
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Reconciled{}, storage.ErrInternal
+	}
+	return reconciled, nil
+}
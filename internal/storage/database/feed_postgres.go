@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// scores of the locked post row
+type postScore struct {
+	hot       float64
+	createdAt time.Time
+}
+
+// locks the post row, scores are changed by the caller in the same transaction
+func lockPostScore(ctx context.Context, tx pgx.Tx, postID int64) (postScore, error) {
+	var score postScore
+	err := tx.QueryRow(ctx, "SELECT hot, created_at FROM posts WHERE id = $1 FOR UPDATE", postID).Scan(&score.hot, &score.createdAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return score, storage.ErrPostNotFound
+		}
+		return score, storage.ErrInternal
+	}
+	return score, nil
+}
+
+// adds n items created at the same time to scores of the post
+func addActivity(ctx context.Context, tx pgx.Tx, postID int64, hot float64, n int, at time.Time) error {
+	hot = storage.AddHot(hot, storage.HotTerm(at)+math.Log2(float64(n)))
+	_, err := tx.Exec(ctx, "UPDATE posts SET activity = activity + $1, hot = $2 WHERE id = $3", n, hot, postID)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// removes the item created at createdAt from scores of the post
+func removeActivity(ctx context.Context, tx pgx.Tx, postID int64, score postScore, createdAt time.Time) error {
+	hot := storage.RemoveHot(score.hot, storage.HotTerm(createdAt), storage.HotTerm(score.createdAt))
+	_, err := tx.Exec(ctx, "UPDATE posts SET activity = activity - 1, hot = $1 WHERE id = $2", hot, postID)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// ties are resolved by id (the newest post first)
+func (s *StoragePostgres) RankedPostIDs(ctx context.Context, query entity.FeedQuery) ([]int64, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var rows pgx.Rows
+	var err error
+	switch query.Algorithm {
+	case entity.FeedTop:
+		rows, err = s.db.Query(newCtx,
+			"SELECT id FROM posts WHERE created_at >= $1 ORDER BY activity DESC, hot DESC, id DESC LIMIT $2", query.Since, query.Limit)
+	case entity.FeedNew:
+		rows, err = s.db.Query(newCtx, "SELECT id FROM posts ORDER BY created_at DESC, id DESC LIMIT $1", query.Limit)
+	default:
+		rows, err = s.db.Query(newCtx, "SELECT id FROM posts ORDER BY hot DESC, id DESC LIMIT $1", query.Limit)
+	}
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return ids, nil
+}
+
+// saves the snapshot, if the snapshot with the same id is already saved, returns the saved one
+// (the no-op update waits for the concurrent insert of the id, so RETURNING gives the saved row)
+func (s *StoragePostgres) SaveFeedSnapshot(ctx context.Context, snapshot entity.FeedSnapshot) (*entity.FeedSnapshot, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	saved := entity.FeedSnapshot{ID: snapshot.ID}
+	err := s.db.QueryRow(newCtx, `INSERT INTO feed_snapshots (id, post_ids, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET id = EXCLUDED.id
+		RETURNING post_ids, expires_at`,
+		snapshot.ID, snapshot.PostIDs, snapshot.ExpiresAt).Scan(&saved.PostIDs, &saved.ExpiresAt)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return &saved, nil
+}
+
+func (s *StoragePostgres) FeedSnapshot(ctx context.Context, id string) (*entity.FeedSnapshot, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	snapshot := entity.FeedSnapshot{ID: id}
+	err := s.db.QueryRow(newCtx, "SELECT post_ids, expires_at FROM feed_snapshots WHERE id = $1 AND expires_at > $2", id, time.Now()).
+		Scan(&snapshot.PostIDs, &snapshot.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrFeedSnapshotNotFound
+		}
+		return nil, storage.ErrInternal
+	}
+	return &snapshot, nil
+}
+
+func (s *StoragePostgres) DeleteExpiredFeedSnapshots(ctx context.Context) error {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.db.Exec(newCtx, "DELETE FROM feed_snapshots WHERE expires_at <= $1", time.Now()); err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// returns posts in the order of ids, missing posts are skipped
+func (s *StoragePostgres) PostsByIDs(ctx context.Context, ids []int64) ([]*entity.Post, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.Query(newCtx, "SELECT "+postColumns+" FROM posts WHERE id = ANY($1)", ids)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Post, error) {
+		return scanPost(row)
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	byID := make(map[int64]*entity.Post, len(list))
+	for _, post := range list {
+		byID[post.ID] = post
+	}
+	posts := make([]*entity.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
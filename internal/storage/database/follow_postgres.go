@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// primary key of the follows table keeps one follow per follower and followee
+func (s *StoragePostgres) Follow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error) {
+	const op = "Storage.postgresql.Follow"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return false, storage.ErrInternal
+	}
+
+	tag, err := tx.Exec(newCtx,
+		"INSERT INTO follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		follow.FollowerID, follow.FolloweeID, time.Now())
+	if err != nil {
+		return false, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if tag.RowsAffected() == 1 && fanOut == entity.FanOutWrite {
+		_, err = tx.Exec(newCtx,
+			"INSERT INTO timelines (user_id, post_id) SELECT $1, id FROM posts WHERE user_id = $2 ON CONFLICT DO NOTHING",
+			follow.FollowerID, follow.FolloweeID)
+		if err != nil {
+			return false, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return false, storage.ErrInternal
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *StoragePostgres) Unfollow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error) {
+	const op = "Storage.postgresql.Unfollow"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return false, storage.ErrInternal
+	}
+
+	tag, err := tx.Exec(newCtx, "DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2", follow.FollowerID, follow.FolloweeID)
+	if err != nil {
+		return false, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if tag.RowsAffected() == 1 && fanOut == entity.FanOutWrite {
+		_, err = tx.Exec(newCtx,
+			"DELETE FROM timelines WHERE user_id = $1 AND post_id IN (SELECT id FROM posts WHERE user_id = $2)",
+			follow.FollowerID, follow.FolloweeID)
+		if err != nil {
+			return false, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return false, storage.ErrInternal
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *StoragePostgres) Followers(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error) {
+	return s.follows(ctx, "followee_id", userID, page)
+}
+
+func (s *StoragePostgres) Following(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error) {
+	return s.follows(ctx, "follower_id", userID, page)
+}
+
+// returns the page of follows with the user in the column, the newest first
+func (s *StoragePostgres) follows(ctx context.Context, column string, userID int64, page entity.Page) ([]entity.Follow, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.Query(newCtx,
+		"SELECT id, follower_id, followee_id, created_at FROM follows WHERE "+column+" = $1 AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3",
+		userID, page.AfterID, page.Limit)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	follows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Follow, error) {
+		var follow entity.Follow
+		err := row.Scan(&follow.ID, &follow.FollowerID, &follow.FolloweeID, &follow.CreatedAt)
+		return follow, err
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return follows, nil
+}
+
+func (s *StoragePostgres) FollowerIDs(ctx context.Context, userID int64) ([]int64, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.Query(newCtx, "SELECT follower_id FROM follows WHERE followee_id = $1", userID)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return ids, nil
+}
+
+func (s *StoragePostgres) PushToTimelines(ctx context.Context, post entity.Post) error {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	_, err := s.db.Exec(newCtx,
+		"INSERT INTO timelines (user_id, post_id) SELECT follower_id, $1 FROM follows WHERE followee_id = $2 ON CONFLICT DO NOTHING",
+		post.ID, post.User)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// ReconcileTimelines rebuilds timelines (fan-out on write) from the follows and the posts, entries of concurrent
+// follows are not visible to the statements, so they are kept; the query timeout is not applied, ctx limits the run
+func (s *StoragePostgres) ReconcileTimelines(ctx context.Context) (entity.ReconciledTimelines, error) {
+	const op = "Storage.postgresql.ReconcileTimelines"
+
+	var reconciled entity.ReconciledTimelines
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return reconciled, storage.ErrInternal
+	}
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO timelines (user_id, post_id)
+		SELECT f.follower_id, p.id FROM follows AS f JOIN posts AS p ON p.user_id = f.followee_id
+		ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return reconciled, rollback(ctx, tx, op, storage.ErrInternal)
+	}
+	reconciled.Added = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx,
+		`DELETE FROM timelines AS t
+		WHERE NOT EXISTS (
+			SELECT 1 FROM follows AS f JOIN posts AS p ON p.user_id = f.followee_id
+			WHERE f.follower_id = t.user_id AND p.id = t.post_id
+		)`)
+	if err != nil {
+		return reconciled, rollback(ctx, tx, op, storage.ErrInternal)
+	}
+	reconciled.Removed = tag.RowsAffected()
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.ReconciledTimelines{}, storage.ErrInternal
+	}
+	return reconciled, nil
+}
+
+func (s *StoragePostgres) HomeFeed(ctx context.Context, userID int64, page entity.Page, fanOut string) ([]*entity.Post, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	ids := "SELECT id FROM posts WHERE user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1) AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3"
+	if fanOut == entity.FanOutWrite {
+		ids = "SELECT post_id FROM timelines WHERE user_id = $1 AND ($2 = 0 OR post_id < $2) ORDER BY post_id DESC LIMIT $3"
+	}
+	rows, err := s.db.Query(newCtx, "SELECT "+postColumns+" FROM posts WHERE id IN ("+ids+") ORDER BY id DESC", userID, page.AfterID, page.Limit)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	posts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Post, error) {
+		return scanPost(row)
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return posts, nil
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+const (
+	kindPost    = "post"
+	kindComment = "comment"
+)
+
+func (s *StoragePostgres) SavePostWithKey(ctx context.Context, post entity.Post, window time.Duration) (entity.Post, bool, error) {
+	const op = "Storage.postgresql.SavePostWithKey"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.purgeKeys(newCtx, window); err != nil {
+		return entity.Post{}, false, err
+	}
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return entity.Post{}, false, storage.ErrInternal
+	}
+
+	id, err := claimKey(newCtx, tx, kindPost, post.User, post.ClientMutationID, window)
+	if err != nil {
+		return entity.Post{}, false, rollback(newCtx, tx, op, err)
+	}
+	if id != 0 {
+		saved, err := scanPost(tx.QueryRow(newCtx, "SELECT "+postColumns+" FROM posts WHERE id = $1", id))
+		if err != nil {
+			return entity.Post{}, false, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+		if err = tx.Commit(newCtx); err != nil {
+			return entity.Post{}, false, storage.ErrInternal
+		}
+		saved.ClientMutationID = post.ClientMutationID
+		return *saved, false, nil
+	}
+
+	now := createdAt()
+	row := tx.QueryRow(newCtx, insertPostQuery, post.Text, post.User, post.CommentsOFF, now, storage.HotTerm(now))
+	if err = row.Scan(&post.ID); err != nil {
+		return entity.Post{}, false, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	post.Version = entity.FirstVersion
+	post.LastActivityAt = now
+	if err = bindKey(newCtx, tx, kindPost, post.User, post.ClientMutationID, post.ID); err != nil {
+		return entity.Post{}, false, rollback(newCtx, tx, op, err)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return entity.Post{}, false, storage.ErrInternal
+	}
+	return post, true, nil
+}
+
+func (s *StoragePostgres) SaveCommentWithKey(ctx context.Context, comment entity.Comment, window time.Duration) (entity.Comment, bool, error) {
+	const op = "Storage.postgresql.SaveCommentWithKey"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.purgeKeys(newCtx, window); err != nil {
+		return entity.Comment{}, false, err
+	}
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return entity.Comment{}, false, storage.ErrInternal
+	}
+
+	id, err := claimKey(newCtx, tx, kindComment, comment.UserID, comment.ClientMutationID, window)
+	if err != nil {
+		return entity.Comment{}, false, rollback(newCtx, tx, op, err)
+	}
+	if id != 0 {
+		saved, err := scanComment(tx.QueryRow(newCtx, "SELECT "+commentColumns+" FROM comments WHERE id = $1", id))
+		if err != nil {
+			return entity.Comment{}, false, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+		if err = tx.Commit(newCtx); err != nil {
+			return entity.Comment{}, false, storage.ErrInternal
+		}
+		saved.ClientMutationID = comment.ClientMutationID
+		return *saved, false, nil
+	}
+
+	comment.ID, err = insertComment(newCtx, tx, comment)
+	if err != nil {
+		return entity.Comment{}, false, rollback(newCtx, tx, op, err)
+	}
+	comment.Version = entity.FirstVersion
+	if err = bindKey(newCtx, tx, kindComment, comment.UserID, comment.ClientMutationID, comment.ID); err != nil {
+		return entity.Comment{}, false, rollback(newCtx, tx, op, err)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return entity.Comment{}, false, storage.ErrInternal
+	}
+	return comment, true, nil
+}
+
+// claimKey locks the key row until the end of transaction, so concurrent retries wait for the first request;
+// returns id of the entity created with the key within window or 0, if the entity should be created.
+// The upsert locks the row in one statement, so the key deleted by purgeKeys is inserted again
+func claimKey(ctx context.Context, tx pgx.Tx, kind string, userID int64, key string, window time.Duration) (int64, error) {
+	var entityID sql.NullInt64
+	var expired bool
+	row := tx.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (user_id, kind, key) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, kind, key) DO UPDATE SET key = EXCLUDED.key
+		RETURNING entity_id, created_at < now() - make_interval(secs => $4)`,
+		userID, kind, key, window.Seconds())
+	if err := row.Scan(&entityID, &expired); err != nil {
+		return 0, storage.ErrInternal
+	}
+	if !entityID.Valid {
+		// new key
+		return 0, nil
+	}
+	if !expired {
+		return entityID.Int64, nil
+	}
+
+	// the key is expired, it is reused for the new entity
+	_, err := tx.Exec(ctx,
+		"UPDATE idempotency_keys SET created_at = now(), entity_id = NULL WHERE user_id = $1 AND kind = $2 AND key = $3",
+		userID, kind, key)
+	if err != nil {
+		return 0, storage.ErrInternal
+	}
+	return 0, nil
+}
+
+// expired keys are deleted on save of a new one
+func (s *StoragePostgres) purgeKeys(ctx context.Context, window time.Duration) error {
+	_, err := s.db.Exec(ctx, "DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1)", window.Seconds())
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+func bindKey(ctx context.Context, tx pgx.Tx, kind string, userID int64, key string, entityID int64) error {
+	_, err := tx.Exec(ctx,
+		"UPDATE idempotency_keys SET entity_id = $4 WHERE user_id = $1 AND kind = $2 AND key = $3",
+		userID, kind, key, entityID)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    user_id    BIGINT       NOT NULL,
+    kind       VARCHAR(16)  NOT NULL,
+    key        VARCHAR(255) NOT NULL,
+    entity_id  BIGINT,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, kind, key)
+);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
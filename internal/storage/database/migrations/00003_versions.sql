@@ -0,0 +1,8 @@
+-- +goose Up
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN version;
+ALTER TABLE comments DROP COLUMN version;
@@ -0,0 +1,34 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS post_revisions
+(
+    post_id    BIGINT        NOT NULL,
+    version    BIGINT        NOT NULL,
+    text       VARCHAR(2000) NOT NULL,
+    editor_id  BIGINT        NOT NULL,
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    PRIMARY KEY (post_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS comment_revisions
+(
+    comment_id BIGINT        NOT NULL,
+    version    BIGINT        NOT NULL,
+    text       VARCHAR(2000) NOT NULL,
+    editor_id  BIGINT        NOT NULL,
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    PRIMARY KEY (comment_id, version)
+);
+
+-- current texts of existing posts and comments are their first known revisions
+INSERT INTO post_revisions (post_id, version, text, editor_id)
+SELECT id, version, text, user_id FROM posts
+ON CONFLICT DO NOTHING;
+
+INSERT INTO comment_revisions (comment_id, version, text, editor_id)
+SELECT id, version, text, user_id FROM comments
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE post_revisions;
+DROP TABLE comment_revisions;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS reactions
+(
+    target_type VARCHAR(16) NOT NULL,
+    target_id   BIGINT      NOT NULL,
+    emoji       VARCHAR(32) NOT NULL,
+    user_id     BIGINT      NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (target_type, target_id, emoji, user_id)
+);
+
+-- +goose Down
+DROP TABLE reactions;
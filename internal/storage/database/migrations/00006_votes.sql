@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- score counters are stored in the comment row, so a vote changes one row and sorting does not aggregate votes
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS upvotes BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS downvotes BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS comment_votes
+(
+    comment_id BIGINT   NOT NULL,
+    user_id    BIGINT   NOT NULL,
+    value      SMALLINT NOT NULL,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE comment_votes;
+ALTER TABLE comments DROP COLUMN upvotes;
+ALTER TABLE comments DROP COLUMN downvotes;
@@ -0,0 +1,52 @@
+-- +goose Up
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+-- number of comments and reactions
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS activity BIGINT NOT NULL DEFAULT 0;
+-- see storage.HotTerm
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS hot DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- creation time of existing posts is the time of their first revision
+UPDATE posts SET created_at = r.created_at
+FROM post_revisions AS r
+WHERE r.post_id = posts.id AND r.version = 1;
+
+-- scores of existing posts: log2 of the sum of 2^(t / 12 hours) over the post, its comments and reactions
+WITH items AS (
+    SELECT id AS post_id, created_at FROM posts
+    UNION ALL
+    SELECT c.post_id, r.created_at FROM comments AS c JOIN comment_revisions AS r ON r.comment_id = c.id AND r.version = 1
+    UNION ALL
+    SELECT target_id, created_at FROM reactions WHERE target_type = 'post'
+), terms AS (
+    SELECT post_id, (EXTRACT(EPOCH FROM created_at) / 43200)::float8 AS term FROM items
+), tops AS (
+    SELECT post_id, max(term) AS top FROM terms GROUP BY post_id
+), scores AS (
+    SELECT terms.post_id, count(*) - 1 AS activity, tops.top + ln(sum(power(2, terms.term - tops.top))) / ln(2) AS hot
+    FROM terms JOIN tops ON tops.post_id = terms.post_id
+    GROUP BY terms.post_id, tops.top
+)
+UPDATE posts SET activity = scores.activity, hot = scores.hot
+FROM scores
+WHERE scores.post_id = posts.id;
+
+CREATE INDEX IF NOT EXISTS posts_hot_idx ON posts (hot DESC, id DESC);
+CREATE INDEX IF NOT EXISTS posts_created_at_idx ON posts (created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS feed_snapshots
+(
+    id         VARCHAR(64) PRIMARY KEY,
+    post_ids   BIGINT[]    NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS feed_snapshots_expires_at_idx ON feed_snapshots (expires_at);
+
+-- +goose Down
+DROP TABLE feed_snapshots;
+DROP INDEX posts_hot_idx;
+DROP INDEX posts_created_at_idx;
+ALTER TABLE posts DROP COLUMN created_at;
+ALTER TABLE posts DROP COLUMN activity;
+ALTER TABLE posts DROP COLUMN hot;
@@ -0,0 +1,29 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS follows
+(
+    id          BIGSERIAL   NOT NULL UNIQUE,
+    follower_id BIGINT      NOT NULL,
+    followee_id BIGINT      NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (follower_id, followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_follower_id_idx ON follows (follower_id, id DESC);
+CREATE INDEX IF NOT EXISTS follows_followee_id_idx ON follows (followee_id, id DESC);
+
+-- posts of the followed users for the home feed (fan-out on read)
+CREATE INDEX IF NOT EXISTS posts_user_id_idx ON posts (user_id, id DESC);
+
+-- home feed of the user (fan-out on write)
+CREATE TABLE IF NOT EXISTS timelines
+(
+    user_id BIGINT NOT NULL,
+    post_id BIGINT NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE timelines;
+DROP INDEX posts_user_id_idx;
+DROP TABLE follows;
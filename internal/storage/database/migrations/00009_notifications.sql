@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id         BIGSERIAL   NOT NULL UNIQUE,
+    user_id    BIGINT      NOT NULL,
+    kind       VARCHAR(16) NOT NULL,
+    actor_id   BIGINT      NOT NULL,
+    post_id    BIGINT      NOT NULL,
+    comment_id BIGINT      NOT NULL,
+    is_read    BOOLEAN     NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, id DESC);
+-- inbox with unreadOnly reads only unread notifications
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id, id DESC) WHERE NOT is_read;
+
+-- +goose Down
+DROP TABLE notifications;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- id of the last read comment of the post for each user
+CREATE TABLE IF NOT EXISTS read_marks
+(
+    user_id    BIGINT      NOT NULL,
+    post_id    BIGINT      NOT NULL,
+    comment_id BIGINT      NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- unread comments of the post are counted with the range scan of the index
+CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments (post_id, id);
+
+-- +goose Down
+DROP INDEX comments_post_id_idx;
+DROP TABLE read_marks;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- number of comments and time of the newest comment (creation time of the post without comments)
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ;
+-- number of direct replies
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reply_count BIGINT NOT NULL DEFAULT 0;
+
+-- counters of existing posts and comments, creation time of the comment is the time of its first revision
+WITH counts AS (
+    SELECT p.id, count(c.id) AS comment_count, GREATEST(p.created_at, max(r.created_at)) AS last_activity_at
+    FROM posts AS p
+    LEFT JOIN comments AS c ON c.post_id = p.id
+    LEFT JOIN comment_revisions AS r ON r.comment_id = c.id AND r.version = 1
+    GROUP BY p.id
+)
+UPDATE posts SET comment_count = counts.comment_count, last_activity_at = counts.last_activity_at
+FROM counts
+WHERE counts.id = posts.id;
+
+ALTER TABLE posts ALTER COLUMN last_activity_at SET NOT NULL;
+
+WITH replies AS (
+    SELECT parent_comment_id AS id, count(*) AS reply_count
+    FROM comments WHERE parent_comment_id IS NOT NULL
+    GROUP BY parent_comment_id
+)
+UPDATE comments SET reply_count = replies.reply_count
+FROM replies
+WHERE replies.id = comments.id;
+
+-- +goose Down
+ALTER TABLE comments DROP COLUMN reply_count;
+ALTER TABLE posts DROP COLUMN last_activity_at;
+ALTER TABLE posts DROP COLUMN comment_count;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- rank is the path of the comment from its root (zero-padded ids joined by '-'), so the subtree of the comment
+-- and the comments after it in the TREE order are read with the range scan of the index;
+-- "C" collation compares ranks byte by byte as the memory storage orders ids
+CREATE INDEX IF NOT EXISTS comments_post_id_rank_idx ON comments (post_id, rank COLLATE "C");
+
+-- +goose Down
+DROP INDEX comments_post_id_rank_idx;
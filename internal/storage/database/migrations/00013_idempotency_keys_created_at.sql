@@ -0,0 +1,7 @@
+-- +goose Up
+
+-- expired keys are deleted with the range scan of the index
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+
+-- +goose Down
+DROP INDEX idempotency_keys_created_at_idx;
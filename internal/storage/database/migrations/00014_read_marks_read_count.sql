@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- number of comments of the post up to the read mark, so unread comments are counted from posts.comment_count
+ALTER TABLE read_marks ADD COLUMN IF NOT EXISTS read_count BIGINT NOT NULL DEFAULT 0;
+
+UPDATE read_marks r
+SET read_count = (SELECT count(*) FROM comments c WHERE c.post_id = r.post_id AND c.id <= r.comment_id);
+
+-- +goose Down
+ALTER TABLE read_marks DROP COLUMN read_count;
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// SaveNotifications reserves ids from the sequence and inserts all notifications with COPY
+func (s *StoragePostgres) SaveNotifications(ctx context.Context, notifications []entity.Notification) ([]entity.Notification, error) {
+	const op = "Storage.postgresql.SaveNotifications"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	ids, err := nextIDs(newCtx, tx, "notifications", len(notifications))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	now := time.Now()
+	saved := make([]entity.Notification, len(notifications))
+	rows := make([][]any, len(notifications))
+	for i, notification := range notifications {
+		notification.ID = ids[i]
+		notification.Read = false
+		notification.CreatedAt = now
+		saved[i] = notification
+		rows[i] = []any{notification.ID, notification.UserID, notification.Kind, notification.ActorID,
+			notification.PostID, notification.CommentID, now}
+	}
+	_, err = tx.CopyFrom(newCtx, pgx.Identifier{"notifications"},
+		[]string{"id", "user_id", "kind", "actor_id", "post_id", "comment_id", "created_at"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		slog.ErrorContext(newCtx, "failed to copy notifications", slog.String("op", op), slog.Any("error", err))
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return nil, storage.ErrInternal
+	}
+	return saved, nil
+}
+
+func (s *StoragePostgres) Notifications(ctx context.Context, userID int64, page entity.Page, unreadOnly bool) ([]entity.Notification, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	filter := "user_id = $1 AND ($2 = 0 OR id < $2)"
+	if unreadOnly {
+		filter += " AND NOT is_read"
+	}
+	query := "SELECT id, user_id, kind, actor_id, post_id, comment_id, is_read, created_at FROM notifications WHERE " +
+		filter + " ORDER BY id DESC LIMIT $3"
+	rows, err := s.db.Query(newCtx, query, userID, page.AfterID, page.Limit)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	notifications, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Notification, error) {
+		var n entity.Notification
+		err := row.Scan(&n.ID, &n.UserID, &n.Kind, &n.ActorID, &n.PostID, &n.CommentID, &n.Read, &n.CreatedAt)
+		return n, err
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return notifications, nil
+}
+
+func (s *StoragePostgres) MarkNotificationsRead(ctx context.Context, userID int64, ids []int64) (int, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	query := "UPDATE notifications SET is_read = true WHERE user_id = $1 AND NOT is_read"
+	args := []any{userID}
+	if ids != nil {
+		query += " AND id = ANY($2)"
+		args = append(args, ids)
+	}
+	tag, err := s.db.Exec(newCtx, query, args...)
+	if err != nil {
+		return 0, storage.ErrInternal
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+func (ts *StoragerTestSuite) saveNotifications(userID int64, n int) []entity.Notification {
+	notifications := make([]entity.Notification, n)
+	for i := range notifications {
+		notifications[i] = entity.Notification{
+			UserID:    userID,
+			Kind:      entity.NotificationMention,
+			ActorID:   rand.Int63(),
+			PostID:    rand.Int63(),
+			CommentID: rand.Int63(),
+		}
+	}
+	saved, err := ts.SaveNotifications(context.Background(), notifications)
+	ts.Require().NoError(err)
+	ts.Require().Len(saved, n)
+	return saved
+}
+
+func (ts *StoragerTestSuite) TestSaveNotifications() {
+	userID := rand.Int63()
+	saved := ts.saveNotifications(userID, 2)
+	ts.Less(saved[0].ID, saved[1].ID)
+	ts.False(saved[0].CreatedAt.IsZero())
+
+	notifications, err := ts.Notifications(context.Background(), userID, entity.Page{Limit: 10}, false)
+	ts.Require().NoError(err)
+	ts.Require().Len(notifications, 2)
+	ts.Equal(saved[1].ID, notifications[0].ID)
+	ts.Equal(saved[1].CommentID, notifications[0].CommentID)
+	ts.Equal(entity.NotificationMention, notifications[0].Kind)
+	ts.False(notifications[0].Read)
+}
+
+func (ts *StoragerTestSuite) TestNotifications_PagesAndUnread() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	saved := ts.saveNotifications(userID, 4)
+	// notifications of another user are not listed
+	ts.saveNotifications(rand.Int63(), 1)
+
+	marked, err := ts.MarkNotificationsRead(ctx, userID, []int64{saved[2].ID, saved[3].ID, rand.Int63()})
+	ts.Require().NoError(err)
+	ts.Equal(2, marked)
+	marked, err = ts.MarkNotificationsRead(ctx, userID, []int64{saved[2].ID})
+	ts.Require().NoError(err)
+	ts.Equal(0, marked)
+
+	page, err := ts.Notifications(ctx, userID, entity.Page{Limit: 2}, false)
+	ts.Require().NoError(err)
+	ts.Require().Len(page, 2)
+	ts.Equal(saved[3].ID, page[0].ID)
+	ts.True(page[0].Read)
+	page, err = ts.Notifications(ctx, userID, entity.Page{AfterID: page[1].ID, Limit: 2}, false)
+	ts.Require().NoError(err)
+	ts.Require().Len(page, 2)
+	ts.Equal(saved[1].ID, page[0].ID)
+
+	unread, err := ts.Notifications(ctx, userID, entity.Page{Limit: 10}, true)
+	ts.Require().NoError(err)
+	ts.Require().Len(unread, 2)
+	ts.Equal(saved[1].ID, unread[0].ID)
+	ts.Equal(saved[0].ID, unread[1].ID)
+
+	marked, err = ts.MarkNotificationsRead(ctx, userID, nil)
+	ts.Require().NoError(err)
+	ts.Equal(2, marked)
+	unread, err = ts.Notifications(ctx, userID, entity.Page{Limit: 10}, true)
+	ts.Require().NoError(err)
+	ts.Empty(unread)
+}
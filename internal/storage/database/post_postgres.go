@@ -12,31 +12,26 @@ import (
 	"github.com/dkrasnykh/graphql-app/internal/storage"
 )
 
-func (s *StoragePostgres) SavePost(ctx context.Context, post entity.Post) (int64, error) {
+func (s *StoragePostgres) SavePost(ctx context.Context, post entity.Post) (entity.Post, error) {
 	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	var id int64
-	row := s.db.QueryRow(newCtx, "INSERT INTO posts (text, user_id, is_comments_disabled) values ($1, $2, $3) RETURNING id",
-		post.Text, post.User, post.CommentsOFF)
-	err := row.Scan(&id)
-	if err != nil {
-		return 0, storage.ErrInternal
+	now := createdAt()
+	row := s.db.QueryRow(newCtx, insertPostQuery, post.Text, post.User, post.CommentsOFF, now, storage.HotTerm(now))
+	if err := row.Scan(&post.ID); err != nil {
+		return entity.Post{}, storage.ErrInternal
 	}
+	post.Version = entity.FirstVersion
+	post.LastActivityAt = now
 
-	return id, nil
+	return post, nil
 }
 
 func (s *StoragePostgres) PostByID(ctx context.Context, id int64) (*entity.Post, error) {
 	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	rows, err := s.db.Query(newCtx, "select (id, text, user_id, is_comments_disabled) from posts where id = $1", id)
-	if err != nil {
-		return nil, storage.ErrInternal
-	}
-
-	post, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[entity.Post])
+	post, err := scanPost(s.db.QueryRow(newCtx, "SELECT "+postColumns+" FROM posts WHERE id = $1", id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, storage.ErrPostNotFound
@@ -44,19 +39,21 @@ func (s *StoragePostgres) PostByID(ctx context.Context, id int64) (*entity.Post,
 
 		return nil, storage.ErrInternal
 	}
-	return &post, nil
+	return post, nil
 }
 
 func (s *StoragePostgres) AllPosts(ctx context.Context) ([]*entity.Post, error) {
 	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	rows, err := s.db.Query(newCtx, "select (id, text, user_id, is_comments_disabled) from posts")
+	rows, err := s.db.Query(newCtx, "SELECT "+postColumns+" FROM posts")
 	if err != nil {
 		return nil, storage.ErrInternal
 	}
 
-	list, err := pgx.CollectRows(rows, pgx.RowTo[*entity.Post])
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Post, error) {
+		return scanPost(row)
+	})
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, storage.ErrInternal
 	}
@@ -64,6 +61,17 @@ func (s *StoragePostgres) AllPosts(ctx context.Context) ([]*entity.Post, error)
 	return list, nil
 }
 
+const postColumns = "id, text, user_id, COALESCE(is_comments_disabled, false), version, comment_count, last_activity_at"
+
+func scanPost(row pgx.Row) (*entity.Post, error) {
+	var post entity.Post
+	if err := row.Scan(&post.ID, &post.Text, &post.User, &post.CommentsOFF, &post.Version,
+		&post.CommentCount, &post.LastActivityAt); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
 // TODO move validation logic into service layer
 // all checks must be performed in one transaction
 // TODO design how to begin/commit transaction into service layer (lock / unlock for memory storage)
@@ -84,7 +92,7 @@ func (s *StoragePostgres) DisableComments(ctx context.Context, userID int64, pos
 	err = row.Scan(&currUserID, &disabled)
 	if err != nil {
 		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
+			slog.ErrorContext(newCtx, "transaction rollback error", slog.String("op", op), slog.Any("error", err))
 		}
 		if errors.Is(err, pgx.ErrNoRows) {
 			return storage.ErrPostNotFound
@@ -94,20 +102,20 @@ func (s *StoragePostgres) DisableComments(ctx context.Context, userID int64, pos
 	}
 	if currUserID != userID {
 		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
+			slog.ErrorContext(newCtx, "transaction rollback error", slog.String("op", op), slog.Any("error", err))
 		}
 		return fmt.Errorf("%w, keeper ID:%d", storage.ErrAccess, currUserID)
 	}
 	if disabled {
 		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
+			slog.ErrorContext(newCtx, "transaction rollback error", slog.String("op", op), slog.Any("error", err))
 		}
 		return storage.ErrPostCommentsDisabled
 	}
-	_, err = tx.Exec(newCtx, "UPDATE posts SET is_comments_disabled = true WHERE id = $1", postID)
+	_, err = tx.Exec(newCtx, "UPDATE posts SET is_comments_disabled = true, version = version + 1 WHERE id = $1", postID)
 	if err != nil {
 		if err := tx.Rollback(newCtx); err != nil {
-			slog.Log(newCtx, slog.LevelError, "%s %w transaction rollback error", op, err)
+			slog.ErrorContext(newCtx, "transaction rollback error", slog.String("op", op), slog.Any("error", err))
 		}
 		return storage.ErrInternal
 	}
@@ -119,3 +127,45 @@ func (s *StoragePostgres) DisableComments(ctx context.Context, userID int64, pos
 
 	return nil
 }
+
+// the row is locked until the end of transaction, so version can not be changed between check and update
+func (s *StoragePostgres) UpdatePost(ctx context.Context, edit entity.Edit) (*entity.Post, error) {
+	const op = "Storage.postgresql.UpdatePost"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	var userID, version int64
+	row := tx.QueryRow(newCtx, "SELECT user_id, version FROM posts WHERE id = $1 FOR UPDATE", edit.ID)
+	if err = row.Scan(&userID, &version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, rollback(newCtx, tx, op, storage.ErrPostNotFound)
+		}
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if userID != edit.UserID && !edit.Moderator {
+		return nil, rollback(newCtx, tx, op, fmt.Errorf("%w, keeper ID:%d", storage.ErrAccess, userID))
+	}
+	if version != edit.ExpectedVersion {
+		return nil, rollback(newCtx, tx, op, fmt.Errorf("%w; current version: %d", storage.ErrVersionConflict, version))
+	}
+
+	post, err := scanPost(tx.QueryRow(newCtx,
+		"UPDATE posts SET text = $1, version = version + 1 WHERE id = $2 RETURNING "+postColumns, edit.Text, edit.ID))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if err = insertPostRevision(newCtx, tx, post.ID, post.Version, post.Text, edit.UserID); err != nil {
+		return nil, rollback(newCtx, tx, op, err)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return nil, storage.ErrInternal
+	}
+	return post, nil
+}
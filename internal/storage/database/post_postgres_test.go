@@ -13,13 +13,17 @@ import (
 func (ts *StoragerTestSuite) TestSavePost_OK() {
 	post := entity.Post{Text: "awesome post", User: int64(3)}
 
-	postID, err := ts.SavePost(context.Background(), post)
-	ts.NoError(err)
-
-	saved, err := ts.PostByID(context.Background(), postID)
-	ts.NoError(err)
-	ts.Equal(post.Text, saved.Text)
-	ts.Equal(post.User, saved.User)
+	saved, err := ts.SavePost(context.Background(), post)
+	ts.Require().NoError(err)
+	ts.NotZero(saved.ID)
+	ts.EqualValues(entity.FirstVersion, saved.Version)
+
+	// the saved post has the stored creation time
+	stored, err := ts.PostByID(context.Background(), saved.ID)
+	ts.Require().NoError(err)
+	ts.Equal(post.Text, stored.Text)
+	ts.Equal(post.User, stored.User)
+	ts.True(stored.LastActivityAt.Equal(saved.LastActivityAt))
 }
 
 func (ts *StoragerTestSuite) TestPostByID_PostNotFound() {
@@ -36,10 +40,10 @@ func (ts *StoragerTestSuite) TestAllPosts_EmptyResult() {
 
 func (ts *StoragerTestSuite) TestAllPosts_OK() {
 	post1 := entity.Post{Text: "awesome post", User: int64(3)}
-	postID1, err := ts.SavePost(context.Background(), post1)
+	postID1, err := savedPostID(ts.SavePost(context.Background(), post1))
 	ts.NoError(err)
 	post2 := entity.Post{Text: "awesome post 1", User: int64(4)}
-	postID2, err := ts.SavePost(context.Background(), post2)
+	postID2, err := savedPostID(ts.SavePost(context.Background(), post2))
 	ts.NoError(err)
 
 	list, err := ts.AllPosts(context.Background())
@@ -65,7 +69,7 @@ func (ts *StoragerTestSuite) TestDisableComments_PostNotFound() {
 func (ts *StoragerTestSuite) TestDisableComments_PostBelongAnotherUser() {
 	userID := int64(1)
 	post := entity.Post{Text: "awesome post", User: userID}
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	anotherUserID := int64(2)
@@ -76,7 +80,7 @@ func (ts *StoragerTestSuite) TestDisableComments_PostBelongAnotherUser() {
 func (ts *StoragerTestSuite) TestDisableComments_PostAlreadyDisabled() {
 	userID := rand.Int63()
 	post := entity.Post{Text: "awesome post", User: userID, CommentsOFF: true}
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	err = ts.DisableComments(context.Background(), userID, postID)
@@ -86,7 +90,7 @@ func (ts *StoragerTestSuite) TestDisableComments_PostAlreadyDisabled() {
 func (ts *StoragerTestSuite) TestDisableComments_OK() {
 	userID := rand.Int63()
 	post := entity.Post{Text: "awesome post", User: userID}
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	err = ts.DisableComments(context.Background(), userID, postID)
@@ -12,23 +12,65 @@ import (
 	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/dkrasnykh/graphql-app/internal/config"
 	"github.com/dkrasnykh/graphql-app/internal/entity"
 )
 
 // for running db tests locally, need to run db container first (docker-compose.yml - db service)
 type Storager interface {
-	SavePost(ctx context.Context, post entity.Post) (int64, error)
+	SavePost(ctx context.Context, post entity.Post) (entity.Post, error)
+	SavePostWithKey(ctx context.Context, post entity.Post, window time.Duration) (entity.Post, bool, error)
+	SavePosts(ctx context.Context, posts []entity.Post) ([]entity.Post, error)
 	PostByID(ctx context.Context, id int64) (*entity.Post, error)
 	AllPosts(ctx context.Context) ([]*entity.Post, error)
+	UpdatePost(ctx context.Context, edit entity.Edit) (*entity.Post, error)
+	PostRevisions(ctx context.Context, postID int64) ([]entity.Revision, error)
 	DisableComments(ctx context.Context, userID int64, postID int64) error
+	PostsByIDs(ctx context.Context, ids []int64) ([]*entity.Post, error)
+	RankedPostIDs(ctx context.Context, query entity.FeedQuery) ([]int64, error)
+	SaveFeedSnapshot(ctx context.Context, snapshot entity.FeedSnapshot) (*entity.FeedSnapshot, error)
+	FeedSnapshot(ctx context.Context, id string) (*entity.FeedSnapshot, error)
+	DeleteExpiredFeedSnapshots(ctx context.Context) error
+	Follow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error)
+	Unfollow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error)
+	Followers(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error)
+	Following(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error)
+	FollowerIDs(ctx context.Context, userID int64) ([]int64, error)
+	PushToTimelines(ctx context.Context, post entity.Post) error
+	HomeFeed(ctx context.Context, userID int64, page entity.Page, fanOut string) ([]*entity.Post, error)
+	SaveNotifications(ctx context.Context, notifications []entity.Notification) ([]entity.Notification, error)
+	Notifications(ctx context.Context, userID int64, page entity.Page, unreadOnly bool) ([]entity.Notification, error)
+	MarkNotificationsRead(ctx context.Context, userID int64, ids []int64) (int, error)
+	MarkPostRead(ctx context.Context, mark entity.ReadMark) error
+	UnreadComments(ctx context.Context, userID int64, postIDs []int64) (map[int64]entity.Unread, error)
+	ReconcileCounters(ctx context.Context) (entity.Reconciled, error)
+	ReconcileTimelines(ctx context.Context) (entity.ReconciledTimelines, error)
 
 	SaveComment(ctx context.Context, comment entity.Comment) (int64, error)
-	AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*entity.Comment, error)
+	SaveCommentWithKey(ctx context.Context, comment entity.Comment, window time.Duration) (entity.Comment, bool, error)
+	SaveComments(ctx context.Context, comments []entity.BatchComment) ([]int64, error)
+	AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort string) ([]*entity.Comment, error)
+	CommentsAfter(ctx context.Context, postID int64, afterID int64, inclusive bool, limit *int, offset *int) ([]*entity.Comment, error)
+	CommentContext(ctx context.Context, id int64, ancestors int, descendants int) (*entity.CommentContext, error)
+	Vote(ctx context.Context, vote entity.Vote) (*entity.Comment, error)
+	UpdateComment(ctx context.Context, edit entity.Edit) (*entity.Comment, error)
+	CommentRevisions(ctx context.Context, commentID int64) ([]entity.Revision, error)
+	CommentByID(ctx context.Context, id int64) (*entity.Comment, error)
+
+	AddReaction(ctx context.Context, reaction entity.Reaction) (bool, error)
+	RemoveReaction(ctx context.Context, reaction entity.Reaction) (bool, error)
+	ReactionSummaries(ctx context.Context, targetType string, targetIDs []int64, viewerID *int64) (map[int64][]entity.ReactionSummary, error)
+
+	Health(ctx context.Context) error
 }
 
 type testStorager interface {
 	Storager
 	clean(ctx context.Context) error
+	// breaks counters of all posts and comments for the reconciliation tests
+	resetCounters(ctx context.Context) error
+	// returns number of kept idempotency keys
+	countIdempotencyKeys(ctx context.Context) (int, error)
 }
 
 type StoragerTestSuite struct {
@@ -65,9 +107,10 @@ func (ts *StoragerTestSuite) SetupSuite() {
 	ts.tc = pgc
 	databaseURL := fmt.Sprintf("postgres://postgres:postgres@%s:%s/testdb?sslmode=disable", host, port.Port())
 
-	err = Migrate(databaseURL)
+	cfg := config.Postgres{URL: databaseURL, QueryTimeout: 2 * time.Second}
+	err = Migrate(cfg)
 	require.NoError(ts.T(), err)
-	storage, err := New(databaseURL)
+	storage, err := New(cfg)
 
 	require.NoError(ts.T(), err)
 
@@ -79,14 +122,33 @@ func (ts *StoragerTestSuite) SetupSuite() {
 func (s *StoragePostgres) clean(ctx context.Context) error {
 	newCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
-	_, err := s.db.Exec(newCtx, "DELETE FROM comments")
-	if err != nil {
+	for _, table := range []string{"read_marks", "notifications", "timelines", "follows", "feed_snapshots", "idempotency_keys", "comment_votes", "reactions", "comment_revisions", "post_revisions", "comments", "posts"} {
+		if _, err := s.db.Exec(newCtx, "DELETE FROM "+table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *StoragePostgres) resetCounters(ctx context.Context) error {
+	if _, err := s.db.Exec(ctx, "UPDATE posts SET comment_count = 0, last_activity_at = 'epoch'"); err != nil {
 		return err
 	}
-	_, err = s.db.Exec(newCtx, "DELETE FROM posts")
+	_, err := s.db.Exec(ctx, "UPDATE comments SET reply_count = 0")
 	return err
 }
 
+// returns id of the post saved by SavePost
+func savedPostID(post entity.Post, err error) (int64, error) {
+	return post.ID, err
+}
+
+func (s *StoragePostgres) countIdempotencyKeys(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.QueryRow(ctx, "SELECT count(*) FROM idempotency_keys").Scan(&count)
+	return count, err
+}
+
 func (ts *StoragerTestSuite) TearDownSuite() {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
@@ -105,3 +167,21 @@ func (ts *StoragerTestSuite) SetupTest() {
 func (ts *StoragerTestSuite) TearDownTest() {
 	ts.Require().NoError(ts.clean(context.Background()))
 }
+
+func (ts *StoragerTestSuite) TestHealth_OK() {
+	ts.Require().NoError(ts.Health(context.Background()))
+}
+
+func (ts *StoragerTestSuite) TestHealth_MigrationVersionMismatch() {
+	ctx := context.Background()
+	s := ts.testStorager.(*StoragePostgres)
+
+	_, err := s.db.Exec(ctx, "INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)", SchemaVersion+1)
+	ts.Require().NoError(err)
+	defer func() {
+		_, err := s.db.Exec(ctx, "DELETE FROM goose_db_version WHERE version_id = $1", SchemaVersion+1)
+		ts.Require().NoError(err)
+	}()
+
+	ts.Require().Error(s.Health(ctx))
+}
@@ -6,15 +6,20 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/dkrasnykh/graphql-app/internal/config"
 )
 
-func Migrate(databaseURL string) error {
-	pool, err := newPool(databaseURL)
+// version of the last migration, storage is ready only if database is migrated to this version
+const SchemaVersion = 14
+
+func Migrate(cfg config.Postgres) error {
+	pool, err := newPool(cfg)
 	if err != nil {
 		return err
 	}
 
-	if err = migrate(pool, 1); err != nil {
+	if err = migrate(pool, SchemaVersion); err != nil {
 		return fmt.Errorf("postgres migration error: %w", err)
 	}
 
@@ -23,11 +28,32 @@ func Migrate(databaseURL string) error {
 	return nil
 }
 
-func newPool(databaseURL string) (*pgxpool.Pool, error) {
-	newCtx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+func newPool(cfg config.Postgres) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("postgres connection error: %w", err)
+	}
+	poolCfg.ConnConfig.Tracer = queryTracer{}
+	if cfg.ConnectTimeout > 0 {
+		poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+
+	newCtx, cancel := context.WithTimeout(context.Background(), poolCfg.ConnConfig.ConnectTimeout+time.Second)
 	defer cancel()
 
-	pool, err := pgxpool.New(newCtx, databaseURL)
+	pool, err := pgxpool.NewWithConfig(newCtx, poolCfg)
 	if err != nil {
 		return pool, fmt.Errorf("postgres connection error: %w", err)
 	}
@@ -40,15 +66,54 @@ type StoragePostgres struct {
 	timeout time.Duration
 }
 
-func New(databaseURL string) (*StoragePostgres, error) {
-	pool, err := newPool(databaseURL)
+// used if query timeout is not configured
+const defaultQueryTimeout = 2 * time.Second
+
+func New(cfg config.Postgres) (*StoragePostgres, error) {
+	pool, err := newPool(cfg)
 	if err != nil {
 		return nil, err
 	}
+
+	timeout := cfg.QueryTimeout
+	if timeout <= 0 {
+		timeout = defaultQueryTimeout
+	}
 	return &StoragePostgres{
 		db:      pool,
-		timeout: time.Second * 2,
+		timeout: timeout,
 	}, nil
 }
 
 func (s *StoragePostgres) Clear() {}
+
+// connection pool statistics (for metrics)
+func (s *StoragePostgres) Stat() *pgxpool.Stat {
+	return s.db.Stat()
+}
+
+// closes all connections of the pool
+func (s *StoragePostgres) Close() {
+	s.db.Close()
+}
+
+// Health checks that database is reachable and migrated to SchemaVersion
+func (s *StoragePostgres) Health(ctx context.Context) error {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.db.Ping(newCtx); err != nil {
+		return fmt.Errorf("postgres ping error: %w", err)
+	}
+
+	var version int64
+	row := s.db.QueryRow(newCtx, "SELECT version_id FROM goose_db_version WHERE is_applied ORDER BY id DESC LIMIT 1")
+	if err := row.Scan(&version); err != nil {
+		return fmt.Errorf("postgres migration version error: %w", err)
+	}
+	if version != SchemaVersion {
+		return fmt.Errorf("postgres migration version %d, expected %d", version, SchemaVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (s *StoragePostgres) CommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	comment, err := scanComment(s.db.QueryRow(newCtx, "SELECT "+commentColumns+" FROM comments WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrCommentNotFound
+		}
+		return nil, storage.ErrInternal
+	}
+	return comment, nil
+}
+
+// primary key of the reactions table keeps one reaction with the emoji per user and target
+func (s *StoragePostgres) AddReaction(ctx context.Context, reaction entity.Reaction) (bool, error) {
+	const op = "Storage.postgresql.AddReaction"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return false, storage.ErrInternal
+	}
+
+	score, err := lockReactionTarget(newCtx, tx, reaction)
+	if err != nil {
+		return false, rollback(newCtx, tx, op, err)
+	}
+	now := time.Now()
+	tag, err := tx.Exec(newCtx,
+		"INSERT INTO reactions (target_type, target_id, emoji, user_id, created_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING",
+		reaction.TargetType, reaction.TargetID, reaction.Emoji, reaction.UserID, now)
+	if err != nil {
+		return false, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if tag.RowsAffected() == 1 && score != nil {
+		if err = addActivity(newCtx, tx, reaction.TargetID, score.hot, 1, now); err != nil {
+			return false, rollback(newCtx, tx, op, err)
+		}
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return false, storage.ErrInternal
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *StoragePostgres) RemoveReaction(ctx context.Context, reaction entity.Reaction) (bool, error) {
+	const op = "Storage.postgresql.RemoveReaction"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return false, storage.ErrInternal
+	}
+
+	score, err := lockReactionTarget(newCtx, tx, reaction)
+	if err != nil {
+		return false, rollback(newCtx, tx, op, err)
+	}
+	var createdAt time.Time
+	err = tx.QueryRow(newCtx,
+		"DELETE FROM reactions WHERE target_type = $1 AND target_id = $2 AND emoji = $3 AND user_id = $4 RETURNING created_at",
+		reaction.TargetType, reaction.TargetID, reaction.Emoji, reaction.UserID).Scan(&createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, rollback(newCtx, tx, op, nil)
+	}
+	if err != nil {
+		return false, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if score != nil {
+		if err = removeActivity(newCtx, tx, reaction.TargetID, *score, createdAt); err != nil {
+			return false, rollback(newCtx, tx, op, err)
+		}
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return false, storage.ErrInternal
+	}
+	return true, nil
+}
+
+// post row is locked for update of its scores (returned), comment row is locked in share mode,
+// so reactions of the same comment do not block each other
+func lockReactionTarget(ctx context.Context, tx pgx.Tx, reaction entity.Reaction) (*postScore, error) {
+	if reaction.TargetType == entity.TargetPost {
+		score, err := lockPostScore(ctx, tx, reaction.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		return &score, nil
+	}
+	var id int64
+	if err := tx.QueryRow(ctx, "SELECT id FROM comments WHERE id = $1 FOR SHARE", reaction.TargetID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrCommentNotFound
+		}
+		return nil, storage.ErrInternal
+	}
+	return nil, nil
+}
+
+func (s *StoragePostgres) ReactionSummaries(ctx context.Context, targetType string, targetIDs []int64, viewerID *int64) (map[int64][]entity.ReactionSummary, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.Query(newCtx,
+		`SELECT target_id, emoji, count(*), COALESCE(bool_or(user_id = $3), false)
+		FROM reactions WHERE target_type = $1 AND target_id = ANY($2)
+		GROUP BY target_id, emoji
+		ORDER BY target_id, count(*) DESC, emoji`,
+		targetType, targetIDs, viewerID)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	all := make(map[int64][]entity.ReactionSummary)
+	var id int64
+	var summary entity.ReactionSummary
+	_, err = pgx.ForEachRow(rows, []any{&id, &summary.Emoji, &summary.Count, &summary.ViewerHasReacted}, func() error {
+		all[id] = append(all[id], summary)
+		return nil
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return all, nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// the read mark only moves forward, so a request from an old tab does not make read comments unread.
+// The mark stores the number of comments up to it, which is the counter of the post without the comments after the mark
+// (there are none, if the whole post is read).
+func (s *StoragePostgres) MarkPostRead(ctx context.Context, mark entity.ReadMark) error {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var lastID *int64
+	var found bool
+	var readCount int64
+	err := s.db.QueryRow(newCtx,
+		`SELECT (SELECT max(id) FROM comments WHERE post_id = p.id),
+			EXISTS (SELECT 1 FROM comments WHERE id = $2 AND post_id = p.id),
+			p.comment_count - CASE WHEN $2 = 0 THEN 0 ELSE (SELECT count(*) FROM comments WHERE post_id = p.id AND id > $2) END
+		FROM posts p WHERE p.id = $1`,
+		mark.PostID, mark.CommentID).Scan(&lastID, &found, &readCount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrPostNotFound
+		}
+		return storage.ErrInternal
+	}
+	commentID := mark.CommentID
+	if commentID == 0 {
+		if lastID == nil {
+			return nil
+		}
+		commentID = *lastID
+	} else if !found {
+		return storage.ErrCommentNotFound
+	}
+
+	_, err = s.db.Exec(newCtx,
+		`INSERT INTO read_marks (user_id, post_id, comment_id, read_count, updated_at) VALUES ($1, $2, $3, $4, now())
+		ON CONFLICT (user_id, post_id) DO UPDATE
+		SET comment_id = GREATEST(read_marks.comment_id, EXCLUDED.comment_id),
+			read_count = CASE WHEN EXCLUDED.comment_id > read_marks.comment_id THEN EXCLUDED.read_count ELSE read_marks.read_count END,
+			updated_at = EXCLUDED.updated_at`,
+		mark.UserID, mark.PostID, commentID, max(readCount, 0))
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// unread comments are the counter of the post without the comments read up to the mark,
+// the first unread comment is one probe of the (post_id, id) index, posts without unread comments are missing
+// (the counter may lag behind until the reconciliation, so the post with the first unread comment has at least one)
+func (s *StoragePostgres) UnreadComments(ctx context.Context, userID int64, postIDs []int64) (map[int64]entity.Unread, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.Query(newCtx,
+		`SELECT p.id, GREATEST(p.comment_count - COALESCE(r.read_count, 0), 1), c.id
+		FROM posts p
+		LEFT JOIN read_marks r ON r.user_id = $1 AND r.post_id = p.id
+		CROSS JOIN LATERAL (
+			SELECT id FROM comments WHERE post_id = p.id AND id > COALESCE(r.comment_id, 0) ORDER BY id LIMIT 1
+		) c
+		WHERE p.id = ANY($2)`,
+		userID, postIDs)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	all := make(map[int64]entity.Unread)
+	var postID int64
+	var unread entity.Unread
+	_, err = pgx.ForEachRow(rows, []any{&postID, &unread.Count, &unread.FirstCommentID}, func() error {
+		all[postID] = unread
+		return nil
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return all, nil
+}
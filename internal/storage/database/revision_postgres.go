@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// inserts post with the first revision in one statement, $4 is the creation time (and last activity) and $5 is its hot score
+const insertPostQuery = `WITH p AS (
+		INSERT INTO posts (text, user_id, is_comments_disabled, created_at, last_activity_at, hot) VALUES ($1, $2, $3, $4, $4, $5)
+		RETURNING id, version, text, user_id
+	)
+	INSERT INTO post_revisions (post_id, version, text, editor_id) SELECT id, version, text, user_id FROM p RETURNING post_id`
+
+// creation time with the precision of timestamptz, so the saved post gets the stored value
+func createdAt() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func (s *StoragePostgres) PostRevisions(ctx context.Context, postID int64) ([]entity.Revision, error) {
+	return s.revisions(ctx,
+		"SELECT version, text, editor_id, created_at FROM post_revisions WHERE post_id = $1 ORDER BY version", postID)
+}
+
+func (s *StoragePostgres) CommentRevisions(ctx context.Context, commentID int64) ([]entity.Revision, error) {
+	return s.revisions(ctx,
+		"SELECT version, text, editor_id, created_at FROM comment_revisions WHERE comment_id = $1 ORDER BY version", commentID)
+}
+
+func (s *StoragePostgres) revisions(ctx context.Context, query string, id int64) ([]entity.Revision, error) {
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.Query(newCtx, query, id)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Revision, error) {
+		var revision entity.Revision
+		err := row.Scan(&revision.Version, &revision.Text, &revision.EditorID, &revision.CreatedAt)
+		return revision, err
+	})
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	return list, nil
+}
+
+func insertPostRevision(ctx context.Context, tx pgx.Tx, postID int64, version int64, text string, editorID int64) error {
+	_, err := tx.Exec(ctx, "INSERT INTO post_revisions (post_id, version, text, editor_id) VALUES ($1, $2, $3, $4)",
+		postID, version, text, editorID)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+func insertCommentRevision(ctx context.Context, tx pgx.Tx, commentID int64, version int64, text string, editorID int64) error {
+	_, err := tx.Exec(ctx, "INSERT INTO comment_revisions (comment_id, version, text, editor_id) VALUES ($1, $2, $3, $4)",
+		commentID, version, text, editorID)
+	if err != nil {
+		return storage.ErrInternal
+	}
+	return nil
+}
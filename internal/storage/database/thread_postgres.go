@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// ancestors are parsed from the rank of the comment, descendants are the comments with the rank prefix
+func (s *StoragePostgres) CommentContext(ctx context.Context, id int64, ancestors int, descendants int) (*entity.CommentContext, error) {
+	const op = "Storage.postgresql.CommentContext"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	postID, rank, err := s.commentRank(newCtx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the path of the comment ends with the comment itself
+	path := strings.Split(rank, "-")
+	ids := make([]int64, 0, ancestors+1)
+	for _, subRank := range path[max(len(path)-1-ancestors, 0):] {
+		ancestorID, err := strconv.ParseInt(subRank, 10, 64)
+		if err != nil {
+			return nil, storage.ErrInternal
+		}
+		ids = append(ids, ancestorID)
+	}
+	rows, err := s.db.Query(newCtx,
+		`SELECT `+commentColumns+` FROM comments WHERE id = ANY($1) ORDER BY rank COLLATE "C"`, ids)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+	list, err := scanComments(newCtx, op, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != len(ids) {
+		return nil, storage.ErrInternal
+	}
+	thread := entity.CommentContext{Comment: *list[len(list)-1], Ancestors: list[:len(list)-1]}
+
+	if descendants > 0 {
+		// '.' follows '-', so ranks of the subtree are between "rank-" and "rank."
+		rows, err = s.db.Query(newCtx,
+			`SELECT `+commentColumns+` FROM comments
+			WHERE post_id = $1 AND rank COLLATE "C" > $2 AND rank COLLATE "C" < $3
+			ORDER BY rank COLLATE "C" LIMIT $4`,
+			postID, rank+"-", rank+".", descendants)
+		if err != nil {
+			return nil, storage.ErrInternal
+		}
+		if thread.Descendants, err = scanComments(newCtx, op, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return &thread, nil
+}
+
+// default values limit = 10, offset = 0 (graphql schema), offset is counted from the comment afterID
+func (s *StoragePostgres) CommentsAfter(ctx context.Context, postID int64, afterID int64, inclusive bool, limit *int, offset *int) ([]*entity.Comment, error) {
+	const op = "Storage.postgresql.CommentsAfter"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	commentPostID, rank, err := s.commentRank(newCtx, afterID)
+	if err != nil {
+		return nil, err
+	}
+	if commentPostID != postID {
+		return nil, storage.ErrCommentNotFound
+	}
+
+	after := `rank COLLATE "C" > $2`
+	if inclusive {
+		after = `rank COLLATE "C" >= $2`
+	}
+	rows, err := s.db.Query(newCtx,
+		`SELECT `+commentColumns+` FROM comments
+		WHERE post_id = $1 AND `+after+`
+		ORDER BY rank COLLATE "C" OFFSET $3 LIMIT $4`,
+		postID, rank, *offset, *limit)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	return scanComments(newCtx, op, rows)
+}
+
+func (s *StoragePostgres) commentRank(ctx context.Context, id int64) (postID int64, rank string, err error) {
+	err = s.db.QueryRow(ctx, "SELECT post_id, rank FROM comments WHERE id = $1", id).Scan(&postID, &rank)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", storage.ErrCommentNotFound
+		}
+		return 0, "", storage.ErrInternal
+	}
+	return postID, rank, nil
+}
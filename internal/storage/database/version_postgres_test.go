@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestUpdatePost_OK() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: userID}))
+	ts.Require().NoError(err)
+
+	post, err := ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: userID, Text: "edited post", ExpectedVersion: entity.FirstVersion})
+	ts.Require().NoError(err)
+	ts.Equal("edited post", post.Text)
+	ts.Equal(int64(entity.FirstVersion+1), post.Version)
+
+	saved, err := ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal(*post, *saved)
+}
+
+func (ts *StoragerTestSuite) TestUpdatePost_VersionConflict() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: userID}))
+	ts.Require().NoError(err)
+	ts.Require().NoError(ts.DisableComments(ctx, userID, postID))
+
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: userID, Text: "edited post", ExpectedVersion: entity.FirstVersion})
+	ts.True(errors.Is(err, storage.ErrVersionConflict))
+
+	saved, err := ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal("awesome post", saved.Text)
+}
+
+func (ts *StoragerTestSuite) TestUpdatePost_Errors() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: userID}))
+	ts.Require().NoError(err)
+
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: userID + 1, Text: "edited post", ExpectedVersion: entity.FirstVersion})
+	ts.True(errors.Is(err, storage.ErrAccess))
+
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID + 1, UserID: userID, Text: "edited post", ExpectedVersion: entity.FirstVersion})
+	ts.True(errors.Is(err, storage.ErrPostNotFound))
+}
+
+func (ts *StoragerTestSuite) TestUpdateComment_OK() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment 1", UserID: userID, PostID: postID})
+	ts.Require().NoError(err)
+
+	comment, err := ts.UpdateComment(ctx, entity.Edit{ID: commentID, UserID: userID, Text: "edited comment", ExpectedVersion: entity.FirstVersion})
+	ts.Require().NoError(err)
+	ts.Equal("edited comment", comment.Text)
+	ts.Equal(int64(entity.FirstVersion+1), comment.Version)
+
+	limit, offset := 10, 0
+	list, err := ts.AllComments(ctx, postID, &limit, &offset, entity.SortTree)
+	ts.Require().NoError(err)
+	ts.Require().Len(list, 1)
+	ts.Equal(*comment, *list[0])
+}
+
+func (ts *StoragerTestSuite) TestUpdateComment_Errors() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment 1", UserID: userID, PostID: postID})
+	ts.Require().NoError(err)
+
+	_, err = ts.UpdateComment(ctx, entity.Edit{ID: commentID, UserID: userID, Text: "edited comment", ExpectedVersion: entity.FirstVersion + 1})
+	ts.True(errors.Is(err, storage.ErrVersionConflict))
+
+	_, err = ts.UpdateComment(ctx, entity.Edit{ID: commentID, UserID: userID + 1, Text: "edited comment", ExpectedVersion: entity.FirstVersion})
+	ts.True(errors.Is(err, storage.ErrAccess))
+
+	_, err = ts.UpdateComment(ctx, entity.Edit{ID: commentID + 1, UserID: userID, Text: "edited comment", ExpectedVersion: entity.FirstVersion})
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
+
+// concurrent updates of the same version: only one of them is applied
+func (ts *StoragerTestSuite) TestUpdatePost_Concurrent() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: userID}))
+	ts.Require().NoError(err)
+
+	const n = 10
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: userID, Text: "edited post", ExpectedVersion: entity.FirstVersion})
+		}(i)
+	}
+	wg.Wait()
+
+	var applied int
+	for _, err := range errs {
+		if err == nil {
+			applied++
+			continue
+		}
+		ts.True(errors.Is(err, storage.ErrVersionConflict))
+	}
+	ts.Equal(1, applied)
+
+	post, err := ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal(int64(entity.FirstVersion+1), post.Version)
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// the comment row is locked first, so votes of the same user for the comment are applied one by one
+func (s *StoragePostgres) Vote(ctx context.Context, vote entity.Vote) (*entity.Comment, error) {
+	const op = "Storage.postgresql.Vote"
+
+	newCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tx, err := s.db.Begin(newCtx)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	var id int64
+	if err = tx.QueryRow(newCtx, "SELECT id FROM comments WHERE id = $1 FOR UPDATE", vote.CommentID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, rollback(newCtx, tx, op, storage.ErrCommentNotFound)
+		}
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	previous := entity.VoteNone
+	err = tx.QueryRow(newCtx, "DELETE FROM comment_votes WHERE comment_id = $1 AND user_id = $2 RETURNING value",
+		vote.CommentID, vote.UserID).Scan(&previous)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+	if vote.Value != entity.VoteNone {
+		_, err = tx.Exec(newCtx, "INSERT INTO comment_votes (comment_id, user_id, value) VALUES ($1, $2, $3)",
+			vote.CommentID, vote.UserID, vote.Value)
+		if err != nil {
+			return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+		}
+	}
+
+	upvotes, downvotes := voteDelta(vote.Value)
+	previousUpvotes, previousDownvotes := voteDelta(previous)
+	comment, err := scanComment(tx.QueryRow(newCtx,
+		"UPDATE comments SET upvotes = upvotes + $1, downvotes = downvotes + $2 WHERE id = $3 RETURNING "+commentColumns,
+		upvotes-previousUpvotes, downvotes-previousDownvotes, vote.CommentID))
+	if err != nil {
+		return nil, rollback(newCtx, tx, op, storage.ErrInternal)
+	}
+
+	if err = tx.Commit(newCtx); err != nil {
+		return nil, storage.ErrInternal
+	}
+	return comment, nil
+}
+
+// returns changes of upvotes and downvotes counters made by the vote
+func voteDelta(value int) (upvotes int64, downvotes int64) {
+	switch value {
+	case entity.VoteUp:
+		return 1, 0
+	case entity.VoteDown:
+		return 0, 1
+	}
+	return 0, 0
+}
@@ -1,6 +1,9 @@
 package storage
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrPostNotFound                   = errors.New("post with id does not exist")
@@ -9,4 +12,21 @@ var (
 	ErrInvalidParentCommentID         = errors.New("there is no comment with ParentCommentID for this post")
 	ErrInternal                       = errors.New("database connection failed")
 	ErrParentCommentBelongAnotherPost = errors.New("parent comment belong another post")
+	ErrCommentNotFound                = errors.New("comment with id does not exist")
+	ErrVersionConflict                = errors.New("version is changed by another update")
+	ErrFeedSnapshotNotFound           = errors.New("feed snapshot does not exist or expired")
 )
+
+// BatchError is an error of the batch item, none of the batch items are saved
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch item %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"math"
+	"time"
+)
+
+// HotHalfLife is the age, at which an item of the post (the post itself, a comment or a reaction)
+// adds to the hot score half as much as a new item
+const HotHalfLife = 12 * time.Hour
+
+/*
+Hot score of the post is log2 of Σ 2^(t / HotHalfLife) over the post and its comments and reactions,
+where t is the creation time of the item. All scores decay at the same rate, so the order of posts
+changes only with new activity and the stored score is updated only when an item is added or removed.
+Scores are kept in log scale, the sum itself does not fit into float64.
+*/
+
+// HotTerm returns log2 of the item contribution
+func HotTerm(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(HotHalfLife)
+}
+
+// AddHot adds the item contribution to the score
+func AddHot(hot float64, term float64) float64 {
+	high, low := max(hot, term), min(hot, term)
+	return high + math.Log2(1+math.Exp2(low-high))
+}
+
+// RemoveHot removes contribution of the item added earlier, the score keeps contribution of the post itself
+func RemoveHot(hot float64, term float64, postTerm float64) float64 {
+	rest := 1 - math.Exp2(term-hot)
+	if rest <= 0 || math.IsNaN(rest) {
+		return postTerm
+	}
+	return max(hot+math.Log2(rest), postTerm)
+}
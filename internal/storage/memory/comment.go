@@ -3,6 +3,8 @@ package memory
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/dkrasnykh/graphql-app/internal/entity"
 	"github.com/dkrasnykh/graphql-app/internal/storage"
@@ -11,47 +13,146 @@ import (
 func (s *StorageMemory) SaveComment(ctx context.Context, comment entity.Comment) (int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	// post exists check
+
+	if err := s.checkComment(comment); err != nil {
+		return 0, err
+	}
+	if comment.ParentCommentID != nil {
+		if err := s.checkParentComment(comment); err != nil {
+			return 0, err
+		}
+	}
+
+	return s.insertComment(comment), nil
+}
+
+// all comments are checked before insert, so the batch is saved entirely or not saved at all
+func (s *StorageMemory) SaveComments(ctx context.Context, comments []entity.BatchComment) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, comment := range comments {
+		if err := s.checkComment(comment.Comment); err != nil {
+			return nil, &storage.BatchError{Index: i, Err: err}
+		}
+		switch {
+		case comment.ParentIndex != nil:
+			if *comment.ParentIndex < 0 || *comment.ParentIndex >= i {
+				return nil, &storage.BatchError{Index: i, Err: storage.ErrInvalidParentCommentID}
+			}
+			if parent := comments[*comment.ParentIndex]; parent.PostID != comment.PostID {
+				err := fmt.Errorf("%w; parent comment post id: %d", storage.ErrParentCommentBelongAnotherPost, parent.PostID)
+				return nil, &storage.BatchError{Index: i, Err: err}
+			}
+		case comment.ParentCommentID != nil:
+			if err := s.checkParentComment(comment.Comment); err != nil {
+				return nil, &storage.BatchError{Index: i, Err: err}
+			}
+		}
+	}
+
+	ids := make([]int64, len(comments))
+	for i, comment := range comments {
+		if comment.ParentIndex != nil {
+			parentID := ids[*comment.ParentIndex]
+			comment.ParentCommentID = &parentID
+		}
+		ids[i] = s.insertComment(comment.Comment)
+	}
+
+	return ids, nil
+}
+
+// post exists and comments are enabled
+func (s *StorageMemory) checkComment(comment entity.Comment) error {
 	post, ok := s.IDValuePostMap[comment.PostID]
 	if !ok {
-		return 0, storage.ErrPostNotFound
+		return storage.ErrPostNotFound
 	}
-	// comments are enabled check
 	if post.CommentsOFF {
-		return 0, storage.ErrPostCommentsDisabled
+		return storage.ErrPostCommentsDisabled
 	}
+	return nil
+}
 
-	if comment.ParentCommentID != nil {
-		// parent comment exists check
-		parentComment, ok := s.IDValueCommentMap[*comment.ParentCommentID]
-		if !ok {
-			return 0, storage.ErrInvalidParentCommentID
-		}
-		// parent comment PostID check
-		if parentComment.PostID != comment.PostID {
-			return 0, fmt.Errorf("%w; parent comment post id: %d", storage.ErrParentCommentBelongAnotherPost, parentComment.PostID)
-		}
+// parent comment exists and belongs the same post
+func (s *StorageMemory) checkParentComment(comment entity.Comment) error {
+	parentComment, ok := s.IDValueCommentMap[*comment.ParentCommentID]
+	if !ok {
+		return storage.ErrInvalidParentCommentID
 	}
+	if parentComment.PostID != comment.PostID {
+		return fmt.Errorf("%w; parent comment post id: %d", storage.ErrParentCommentBelongAnotherPost, parentComment.PostID)
+	}
+	return nil
+}
 
-	// insert new comment
+func (s *StorageMemory) insertComment(comment entity.Comment) int64 {
 	id := s.CommentCounter
 	comment.ID = id
+	comment.Version = entity.FirstVersion
+	now := time.Now()
 	s.IDValueCommentMap[id] = comment
+	s.CommentRevisionList[id] = []entity.Revision{{Version: comment.Version, Text: comment.Text, EditorID: comment.UserID, CreatedAt: now}}
+	s.addActivity(comment.PostID, now)
+	s.PostComments[comment.PostID] = append(s.PostComments[comment.PostID], id)
+
+	post := s.IDValuePostMap[comment.PostID]
+	post.CommentCount += 1
+	post.LastActivityAt = now
+	s.IDValuePostMap[comment.PostID] = post
 
 	if comment.ParentCommentID == nil {
 		// root comment
 		s.PostRootComments[comment.PostID] = append(s.PostRootComments[comment.PostID], id)
 	} else {
 		s.PostAdjList[comment.PostID][*comment.ParentCommentID] = append(s.PostAdjList[comment.PostID][*comment.ParentCommentID], id)
+		s.CommentParents[id] = *comment.ParentCommentID
+		parent := s.IDValueCommentMap[*comment.ParentCommentID]
+		parent.ReplyCount += 1
+		s.IDValueCommentMap[*comment.ParentCommentID] = parent
 	}
 
 	s.CommentCounter += 1
 
-	return id, nil
+	return id
+}
+
+// version is checked under the same lock as the update
+func (s *StorageMemory) UpdateComment(ctx context.Context, edit entity.Edit) (*entity.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.IDValueCommentMap[edit.ID]
+	if !ok {
+		return nil, storage.ErrCommentNotFound
+	}
+	if comment.UserID != edit.UserID && !edit.Moderator {
+		return nil, fmt.Errorf("%w, author ID:%d", storage.ErrAccess, comment.UserID)
+	}
+	if comment.Version != edit.ExpectedVersion {
+		return nil, fmt.Errorf("%w; current version: %d", storage.ErrVersionConflict, comment.Version)
+	}
+
+	comment.Text = edit.Text
+	comment.Version += 1
+	s.IDValueCommentMap[edit.ID] = comment
+	s.CommentRevisionList[edit.ID] = append(s.CommentRevisionList[edit.ID],
+		entity.Revision{Version: comment.Version, Text: comment.Text, EditorID: edit.UserID, CreatedAt: time.Now()})
+
+	return &comment, nil
+}
+
+func (s *StorageMemory) CommentRevisions(ctx context.Context, commentID int64) ([]entity.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.CommentRevisionList[commentID]), nil
 }
 
-// default values limit = 10, offset = 0 (graphql schema)
-func (s *StorageMemory) AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*entity.Comment, error) {
+// default values limit = 10, offset = 0 (graphql schema),
+// siblings are ordered at query time, so votes do not reorder stored lists
+func (s *StorageMemory) AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort string) ([]*entity.Comment, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -65,12 +166,12 @@ func (s *StorageMemory) AllComments(ctx context.Context, postID int64, limit *in
 			commentsList = append(commentsList, &comm)
 		}
 		i += 1
-		for _, u := range s.PostAdjList[postID][v] {
+		for _, u := range s.siblings(s.PostAdjList[postID][v], sort) {
 			dfs(u)
 		}
 	}
 
-	for _, root := range s.PostRootComments[postID] {
+	for _, root := range s.siblings(s.PostRootComments[postID], sort) {
 		dfs(root)
 		if len(commentsList) == *limit {
 			break
@@ -43,9 +43,9 @@ func (ts *StoragerTestSuite) TestAllComments_OK() {
 	post1 := entity.Post{Text: "awesome post 1", User: userID}
 	post2 := entity.Post{Text: "awesome post 2", User: userID}
 
-	postID1, err := ts.SavePost(ctx, post1)
+	postID1, err := savedPostID(ts.SavePost(ctx, post1))
 	ts.NoError(err)
-	postID2, err := ts.SavePost(ctx, post2)
+	postID2, err := savedPostID(ts.SavePost(ctx, post2))
 	ts.NoError(err)
 
 	comment1 := entity.Comment{Text: "comment 1", UserID: userID, PostID: postID1}
@@ -77,15 +77,26 @@ func (ts *StoragerTestSuite) TestAllComments_OK() {
 	ts.NoError(err)
 
 	comment1.ID = commentID1
+	comment1.Version = entity.FirstVersion
 	comment2.ID = commentID2
+	comment2.Version = entity.FirstVersion
 	comment3.ID = commentID3
+	comment3.Version = entity.FirstVersion
 	comment4.ID = commentID4
+	comment4.Version = entity.FirstVersion
 	comment5.ID = commentID5
+	comment5.Version = entity.FirstVersion
 	comment6.ID = commentID6
+	comment6.Version = entity.FirstVersion
 	comment7.ID = commentID7
+	comment7.Version = entity.FirstVersion
+	// number of direct replies
+	comment1.ReplyCount = 2
+	comment3.ReplyCount = 1
+	comment4.ReplyCount = 1
 
 	limit, offset := 10, 0
-	list, err := ts.AllComments(ctx, postID1, &limit, &offset)
+	list, err := ts.AllComments(ctx, postID1, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"]
 	ts.Equal(6, len(list))
@@ -96,13 +107,13 @@ func (ts *StoragerTestSuite) TestAllComments_OK() {
 	ts.Equal(comment6, *list[4])
 	ts.Equal(comment2, *list[5])
 	// ["comment 7"]
-	list, err = ts.AllComments(ctx, postID2, &limit, &offset)
+	list, err = ts.AllComments(ctx, postID2, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	ts.Equal(1, len(list))
 	ts.Equal(comment7, *list[0])
 
 	limit, offset = 2, 1
-	list, err = ts.AllComments(ctx, postID1, &limit, &offset)
+	list, err = ts.AllComments(ctx, postID1, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"] -> ["comment 3", "comment 5"]
 	ts.Equal(2, len(list))
@@ -110,7 +121,7 @@ func (ts *StoragerTestSuite) TestAllComments_OK() {
 	ts.Equal(comment5, *list[1])
 
 	limit, offset = 10, 4
-	list, err = ts.AllComments(ctx, postID1, &limit, &offset)
+	list, err = ts.AllComments(ctx, postID1, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"] -> ["comment 6", "comment 2"]
 	ts.Equal(2, len(list))
@@ -118,7 +129,7 @@ func (ts *StoragerTestSuite) TestAllComments_OK() {
 	ts.Equal(comment2, *list[1])
 
 	limit, offset = 10, 10
-	list, err = ts.AllComments(ctx, postID1, &limit, &offset)
+	list, err = ts.AllComments(ctx, postID1, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	// ["comment 1", "comment 3", "comment 5", "comment 4", "comment 6", "comment 2"] -> []
 	ts.Equal(0, len(list))
@@ -128,7 +139,7 @@ func (ts *StoragerTestSuite) TestAllComments_EmptyResult() {
 	//userID := rand.Int63()
 	postID := rand.Int63()
 	limit, offset := 10, 0
-	list, err := ts.AllComments(context.Background(), postID, &limit, &offset)
+	list, err := ts.AllComments(context.Background(), postID, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	ts.Equal(0, len(list))
 }
@@ -146,7 +157,7 @@ func (ts *StoragerTestSuite) TestSaveComment_PostNotFound() {
 func (ts *StoragerTestSuite) TestSaveComment_PostCommentsDisabled() {
 	userID := rand.Int63()
 	postCommentsOFF := entity.Post{Text: "awesome post", User: userID, CommentsOFF: true}
-	postID, err := ts.SavePost(context.Background(), postCommentsOFF)
+	postID, err := savedPostID(ts.SavePost(context.Background(), postCommentsOFF))
 	ts.NoError(err)
 
 	comment := entity.Comment{Text: "comment", PostID: postID, UserID: int64(3)}
@@ -159,7 +170,7 @@ func (ts *StoragerTestSuite) TestSaveComment_ParentCommentIDNotFound() {
 	userID := rand.Int63()
 	post := entity.Post{Text: "awesome post", User: userID}
 
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	parentID := int64(10)
@@ -174,10 +185,10 @@ func (ts *StoragerTestSuite) TestSaveComment_ParentCommentBelongAnotherPost() {
 	post1 := entity.Post{Text: "awesome post 1", User: userID}
 	post2 := entity.Post{Text: "awesome post 2", User: userID}
 
-	postID1, err := ts.SavePost(context.Background(), post1)
+	postID1, err := savedPostID(ts.SavePost(context.Background(), post1))
 	ts.NoError(err)
 
-	postID2, err := ts.SavePost(context.Background(), post2)
+	postID2, err := savedPostID(ts.SavePost(context.Background(), post2))
 	ts.NoError(err)
 
 	parentComment := entity.Comment{Text: "parent comment", PostID: postID1, UserID: int64(3)}
@@ -193,7 +204,7 @@ func (ts *StoragerTestSuite) TestSaveComment_OKRootComment() {
 	userID := rand.Int63()
 	post := entity.Post{Text: "awesome post", User: userID}
 
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	comment := entity.Comment{Text: "comment", PostID: postID, UserID: int64(3)}
@@ -201,7 +212,7 @@ func (ts *StoragerTestSuite) TestSaveComment_OKRootComment() {
 	ts.NoError(err)
 
 	offset, limit := 0, 10
-	list, err := ts.AllComments(context.Background(), postID, &limit, &offset)
+	list, err := ts.AllComments(context.Background(), postID, &limit, &offset, entity.SortTree)
 	ts.NoError(err)
 	ts.Equal(1, len(list))
 	ts.Equal(comment.Text, list[0].Text)
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+// ReconcileCounters recomputes comment counts and last activity of posts and reply counts of comments
+// from the comments, creation time of the comment is the time of its first revision
+func (s *StorageMemory) ReconcileCounters(ctx context.Context) (entity.Reconciled, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[int64]int64)
+	replies := make(map[int64]int64)
+	lastActivity := make(map[int64]time.Time)
+	for id := range s.IDValuePostMap {
+		lastActivity[id] = s.PostScores[id].CreatedAt
+	}
+	for id, comment := range s.IDValueCommentMap {
+		counts[comment.PostID] += 1
+		if comment.ParentCommentID != nil {
+			replies[*comment.ParentCommentID] += 1
+		}
+		if createdAt := s.CommentRevisionList[id][0].CreatedAt; createdAt.After(lastActivity[comment.PostID]) {
+			lastActivity[comment.PostID] = createdAt
+		}
+	}
+
+	var reconciled entity.Reconciled
+	for id, post := range s.IDValuePostMap {
+		if post.CommentCount != counts[id] || !post.LastActivityAt.Equal(lastActivity[id]) {
+			post.CommentCount = counts[id]
+			post.LastActivityAt = lastActivity[id]
+			s.IDValuePostMap[id] = post
+			reconciled.Posts += 1
+		}
+	}
+	for id, comment := range s.IDValueCommentMap {
+		if comment.ReplyCount != replies[id] {
+			comment.ReplyCount = replies[id]
+			s.IDValueCommentMap[id] = comment
+			reconciled.Comments += 1
+		}
+	}
+	return reconciled, nil
+}
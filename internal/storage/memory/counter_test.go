@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+func (ts *StoragerTestSuite) TestCounters() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: 1}))
+	ts.Require().NoError(err)
+	post, err := ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal(int64(0), post.CommentCount)
+	ts.False(post.LastActivityAt.IsZero())
+	createdAt := post.LastActivityAt
+
+	rootID, err := ts.SaveComment(ctx, entity.Comment{Text: "root", UserID: 2, PostID: postID})
+	ts.Require().NoError(err)
+	_, err = ts.SaveComment(ctx, entity.Comment{Text: "reply", UserID: 3, PostID: postID, ParentCommentID: &rootID})
+	ts.Require().NoError(err)
+	first := 0
+	ids, err := ts.SaveComments(ctx, []entity.BatchComment{
+		{Comment: entity.Comment{Text: "batch root", UserID: 2, PostID: postID}},
+		{Comment: entity.Comment{Text: "batch reply", UserID: 3, PostID: postID}, ParentIndex: &first},
+		{Comment: entity.Comment{Text: "reply", UserID: 4, PostID: postID, ParentCommentID: &rootID}},
+	})
+	ts.Require().NoError(err)
+
+	post, err = ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal(int64(5), post.CommentCount)
+	ts.False(post.LastActivityAt.Before(createdAt))
+	root, err := ts.CommentByID(ctx, rootID)
+	ts.Require().NoError(err)
+	ts.Equal(int64(2), root.ReplyCount)
+	batchRoot, err := ts.CommentByID(ctx, ids[0])
+	ts.Require().NoError(err)
+	ts.Equal(int64(1), batchRoot.ReplyCount)
+
+	// failed batch does not change counters
+	missing := int64(1 << 40)
+	_, err = ts.SaveComments(ctx, []entity.BatchComment{
+		{Comment: entity.Comment{Text: "reply", UserID: 4, PostID: postID, ParentCommentID: &rootID}},
+		{Comment: entity.Comment{Text: "reply", UserID: 4, PostID: postID, ParentCommentID: &missing}},
+	})
+	ts.Require().Error(err)
+	post, err = ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal(int64(5), post.CommentCount)
+}
+
+func (ts *StoragerTestSuite) TestReconcileCounters() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: 1}))
+	ts.Require().NoError(err)
+	emptyID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "post without comments", User: 1}))
+	ts.Require().NoError(err)
+	rootID, err := ts.SaveComment(ctx, entity.Comment{Text: "root", UserID: 2, PostID: postID})
+	ts.Require().NoError(err)
+	_, err = ts.SaveComment(ctx, entity.Comment{Text: "reply", UserID: 3, PostID: postID, ParentCommentID: &rootID})
+	ts.Require().NoError(err)
+
+	post, err := ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	empty, err := ts.PostByID(ctx, emptyID)
+	ts.Require().NoError(err)
+
+	// counters kept on save are already correct
+	reconciled, err := ts.ReconcileCounters(ctx)
+	ts.Require().NoError(err)
+	ts.Equal(entity.Reconciled{}, reconciled)
+
+	ts.Require().NoError(ts.resetCounters(ctx))
+	reconciled, err = ts.ReconcileCounters(ctx)
+	ts.Require().NoError(err)
+	ts.Equal(entity.Reconciled{Posts: 2, Comments: 1}, reconciled)
+
+	reconciledPost, err := ts.PostByID(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Equal(post.CommentCount, reconciledPost.CommentCount)
+	ts.True(post.LastActivityAt.Equal(reconciledPost.LastActivityAt))
+	reconciledEmpty, err := ts.PostByID(ctx, emptyID)
+	ts.Require().NoError(err)
+	ts.True(empty.LastActivityAt.Equal(reconciledEmpty.LastActivityAt))
+	root, err := ts.CommentByID(ctx, rootID)
+	ts.Require().NoError(err)
+	ts.Equal(int64(1), root.ReplyCount)
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// scores of the post are updated with every comment and reaction of the post
+type PostScore struct {
+	CreatedAt time.Time
+	// number of comments and reactions
+	Activity int64
+	// see storage.HotTerm
+	Hot float64
+}
+
+func (s *StorageMemory) addActivity(postID int64, at time.Time) {
+	score := s.PostScores[postID]
+	score.Activity += 1
+	score.Hot = storage.AddHot(score.Hot, storage.HotTerm(at))
+	s.PostScores[postID] = score
+}
+
+// createdAt is the time of the removed item
+func (s *StorageMemory) removeActivity(postID int64, createdAt time.Time) {
+	score := s.PostScores[postID]
+	score.Activity -= 1
+	score.Hot = storage.RemoveHot(score.Hot, storage.HotTerm(createdAt), storage.HotTerm(score.CreatedAt))
+	s.PostScores[postID] = score
+}
+
+func (s *StorageMemory) RankedPostIDs(ctx context.Context, query entity.FeedQuery) ([]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]int64, 0, len(s.PostScores))
+	for id, score := range s.PostScores {
+		if query.Algorithm == entity.FeedTop && score.CreatedAt.Before(query.Since) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b int64) int {
+		return comparePosts(a, s.PostScores[a], b, s.PostScores[b], query.Algorithm)
+	})
+	if len(ids) > query.Limit {
+		ids = ids[:query.Limit]
+	}
+	return ids, nil
+}
+
+// ties are resolved by id (the newest post first)
+func comparePosts(aID int64, a PostScore, bID int64, b PostScore, algorithm string) int {
+	var order int
+	switch algorithm {
+	case entity.FeedHot:
+		order = cmp.Compare(b.Hot, a.Hot)
+	case entity.FeedTop:
+		order = cmp.Compare(b.Activity, a.Activity)
+		if order == 0 {
+			order = cmp.Compare(b.Hot, a.Hot)
+		}
+	case entity.FeedNew:
+		order = b.CreatedAt.Compare(a.CreatedAt)
+	}
+	if order != 0 {
+		return order
+	}
+	return cmp.Compare(bID, aID)
+}
+
+// saves the snapshot, if the snapshot with the same id is already saved, returns the saved one
+func (s *StorageMemory) SaveFeedSnapshot(ctx context.Context, snapshot entity.FeedSnapshot) (*entity.FeedSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved, ok := s.FeedSnapshots[snapshot.ID]
+	if !ok {
+		saved = snapshot
+		saved.PostIDs = slices.Clone(snapshot.PostIDs)
+		s.FeedSnapshots[snapshot.ID] = saved
+	}
+	saved.PostIDs = slices.Clone(saved.PostIDs)
+	return &saved, nil
+}
+
+func (s *StorageMemory) FeedSnapshot(ctx context.Context, id string) (*entity.FeedSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.FeedSnapshots[id]
+	if !ok || !snapshot.ExpiresAt.After(time.Now()) {
+		return nil, storage.ErrFeedSnapshotNotFound
+	}
+	snapshot.PostIDs = slices.Clone(snapshot.PostIDs)
+	return &snapshot, nil
+}
+
+func (s *StorageMemory) DeleteExpiredFeedSnapshots(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, snapshot := range s.FeedSnapshots {
+		if !snapshot.ExpiresAt.After(now) {
+			delete(s.FeedSnapshots, id)
+		}
+	}
+	return nil
+}
+
+// returns posts in the order of ids, missing posts are skipped
+func (s *StorageMemory) PostsByIDs(ctx context.Context, ids []int64) ([]*entity.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]*entity.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := s.IDValuePostMap[id]; ok {
+			posts = append(posts, &post)
+		}
+	}
+	return posts, nil
+}
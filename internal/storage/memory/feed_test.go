@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) savePosts(n int) []int64 {
+	ids := make([]int64, n)
+	for i := range ids {
+		id, err := savedPostID(ts.SavePost(context.Background(), entity.Post{Text: "awesome post", User: rand.Int63()}))
+		ts.Require().NoError(err)
+		ids[i] = id
+	}
+	return ids
+}
+
+func (ts *StoragerTestSuite) rankedPostIDs(query entity.FeedQuery) []int64 {
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	ids, err := ts.RankedPostIDs(context.Background(), query)
+	ts.Require().NoError(err)
+	return ids
+}
+
+func (ts *StoragerTestSuite) TestRankedPostIDs_Hot() {
+	ctx := context.Background()
+	ids := ts.savePosts(3)
+	post1, post2, post3 := ids[0], ids[1], ids[2]
+
+	// without activity the newest post is the hottest
+	ts.Equal([]int64{post3, post2, post1}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedHot}))
+
+	_, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: post1})
+	ts.Require().NoError(err)
+	ts.Equal([]int64{post1, post3, post2}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedHot}))
+
+	reactions := []entity.Reaction{
+		{TargetType: entity.TargetPost, TargetID: post2, UserID: rand.Int63(), Emoji: "🔥"},
+		{TargetType: entity.TargetPost, TargetID: post2, UserID: rand.Int63(), Emoji: "🔥"},
+	}
+	for _, reaction := range reactions {
+		_, err = ts.AddReaction(ctx, reaction)
+		ts.Require().NoError(err)
+	}
+	ts.Equal([]int64{post2, post1, post3}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedHot}))
+
+	for _, reaction := range reactions {
+		_, err = ts.RemoveReaction(ctx, reaction)
+		ts.Require().NoError(err)
+	}
+	ts.Equal([]int64{post1, post3, post2}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedHot}))
+
+	ts.Equal([]int64{post1, post3}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedHot, Limit: 2}))
+}
+
+func (ts *StoragerTestSuite) TestRankedPostIDs_TopSince() {
+	ctx := context.Background()
+	old := ts.savePosts(1)[0]
+	since := time.Now()
+	ids := ts.savePosts(3)
+	post1, post2, post3 := ids[0], ids[1], ids[2]
+
+	for _, postID := range []int64{old, old, post1, post2, post2} {
+		_, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: postID})
+		ts.Require().NoError(err)
+	}
+	// reactions on comments are not activity of the post
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: post3})
+	ts.Require().NoError(err)
+	for i := 0; i < 3; i++ {
+		_, err = ts.AddReaction(ctx, entity.Reaction{TargetType: entity.TargetComment, TargetID: commentID, UserID: rand.Int63(), Emoji: "👍"})
+		ts.Require().NoError(err)
+	}
+
+	// post1 and post3 have one comment each, post3 is the hottest of them
+	ts.Equal([]int64{post2, post3, post1}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedTop, Since: since}))
+	ts.Equal([]int64{post2, old, post3, post1}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedTop}))
+}
+
+func (ts *StoragerTestSuite) TestRankedPostIDs_New() {
+	ctx := context.Background()
+	ids := ts.savePosts(3)
+
+	_, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: ids[0]})
+	ts.Require().NoError(err)
+	ts.Equal([]int64{ids[2], ids[1], ids[0]}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedNew}))
+}
+
+func (ts *StoragerTestSuite) TestRankedPostIDs_BatchComments() {
+	ctx := context.Background()
+	ids := ts.savePosts(2)
+
+	_, err := ts.SaveComments(ctx, []entity.BatchComment{
+		{Comment: entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: ids[0]}},
+		{Comment: entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: ids[0]}},
+	})
+	ts.Require().NoError(err)
+	ts.Equal([]int64{ids[0], ids[1]}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedHot}))
+	ts.Equal([]int64{ids[0], ids[1]}, ts.rankedPostIDs(entity.FeedQuery{Algorithm: entity.FeedTop}))
+}
+
+func (ts *StoragerTestSuite) TestFeedSnapshot() {
+	ctx := context.Background()
+	snapshot := entity.FeedSnapshot{ID: "snapshot", PostIDs: []int64{3, 1, 2}, ExpiresAt: time.Now().Add(time.Hour)}
+	saved, err := ts.SaveFeedSnapshot(ctx, snapshot)
+	ts.Require().NoError(err)
+	ts.Equal(snapshot.PostIDs, saved.PostIDs)
+
+	saved, err = ts.FeedSnapshot(ctx, snapshot.ID)
+	ts.Require().NoError(err)
+	ts.Equal(snapshot.PostIDs, saved.PostIDs)
+	ts.WithinDuration(snapshot.ExpiresAt, saved.ExpiresAt, time.Millisecond)
+
+	// the snapshot saved first is kept
+	saved, err = ts.SaveFeedSnapshot(ctx, entity.FeedSnapshot{ID: snapshot.ID, PostIDs: []int64{4}, ExpiresAt: time.Now().Add(2 * time.Hour)})
+	ts.Require().NoError(err)
+	ts.Equal(snapshot.PostIDs, saved.PostIDs)
+	ts.WithinDuration(snapshot.ExpiresAt, saved.ExpiresAt, time.Millisecond)
+
+	_, err = ts.FeedSnapshot(ctx, "unknown")
+	ts.True(errors.Is(err, storage.ErrFeedSnapshotNotFound))
+
+	expired := entity.FeedSnapshot{ID: "expired", PostIDs: []int64{}, ExpiresAt: time.Now().Add(-time.Second)}
+	_, err = ts.SaveFeedSnapshot(ctx, expired)
+	ts.Require().NoError(err)
+	_, err = ts.FeedSnapshot(ctx, expired.ID)
+	ts.True(errors.Is(err, storage.ErrFeedSnapshotNotFound))
+}
+
+func (ts *StoragerTestSuite) TestDeleteExpiredFeedSnapshots() {
+	ctx := context.Background()
+	_, err := ts.SaveFeedSnapshot(ctx, entity.FeedSnapshot{ID: "expired", PostIDs: []int64{1}, ExpiresAt: time.Now().Add(-time.Second)})
+	ts.Require().NoError(err)
+	_, err = ts.SaveFeedSnapshot(ctx, entity.FeedSnapshot{ID: "snapshot", PostIDs: []int64{1}, ExpiresAt: time.Now().Add(time.Hour)})
+	ts.Require().NoError(err)
+
+	ts.Require().NoError(ts.DeleteExpiredFeedSnapshots(ctx))
+
+	// id of the deleted snapshot is free for a new one
+	saved, err := ts.SaveFeedSnapshot(ctx, entity.FeedSnapshot{ID: "expired", PostIDs: []int64{2}, ExpiresAt: time.Now().Add(time.Hour)})
+	ts.Require().NoError(err)
+	ts.Equal([]int64{2}, saved.PostIDs)
+
+	saved, err = ts.FeedSnapshot(ctx, "snapshot")
+	ts.Require().NoError(err)
+	ts.Equal([]int64{1}, saved.PostIDs)
+}
+
+func (ts *StoragerTestSuite) TestPostsByIDs() {
+	ids := ts.savePosts(3)
+
+	posts, err := ts.PostsByIDs(context.Background(), []int64{ids[2], rand.Int63(), ids[0]})
+	ts.Require().NoError(err)
+	ts.Require().Len(posts, 2)
+	ts.Equal(ids[2], posts[0].ID)
+	ts.Equal(ids[0], posts[1].ID)
+}
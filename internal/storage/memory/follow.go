@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+type FollowKey struct {
+	FollowerID int64
+	FolloweeID int64
+}
+
+func (s *StorageMemory) Follow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := FollowKey{FollowerID: follow.FollowerID, FolloweeID: follow.FolloweeID}
+	if _, ok := s.Follows[key]; ok {
+		return false, nil
+	}
+	follow.ID = s.FollowCounter
+	s.FollowCounter += 1
+	follow.CreatedAt = time.Now()
+	s.Follows[key] = follow
+
+	if fanOut == entity.FanOutWrite {
+		for id, post := range s.IDValuePostMap {
+			if post.User == follow.FolloweeID {
+				s.pushToTimeline(follow.FollowerID, id)
+			}
+		}
+	}
+	return true, nil
+}
+
+func (s *StorageMemory) Unfollow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := FollowKey{FollowerID: follow.FollowerID, FolloweeID: follow.FolloweeID}
+	if _, ok := s.Follows[key]; !ok {
+		return false, nil
+	}
+	delete(s.Follows, key)
+
+	if fanOut == entity.FanOutWrite {
+		for id := range s.Timelines[follow.FollowerID] {
+			if s.IDValuePostMap[id].User == follow.FolloweeID {
+				delete(s.Timelines[follow.FollowerID], id)
+			}
+		}
+	}
+	return true, nil
+}
+
+func (s *StorageMemory) Followers(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.follows(page, func(follow entity.Follow) bool { return follow.FolloweeID == userID }), nil
+}
+
+func (s *StorageMemory) Following(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.follows(page, func(follow entity.Follow) bool { return follow.FollowerID == userID }), nil
+}
+
+// returns the page of matched follows, the newest first
+func (s *StorageMemory) follows(page entity.Page, match func(entity.Follow) bool) []entity.Follow {
+	follows := make([]entity.Follow, 0)
+	for _, follow := range s.Follows {
+		if match(follow) && (page.AfterID == 0 || follow.ID < page.AfterID) {
+			follows = append(follows, follow)
+		}
+	}
+	slices.SortFunc(follows, func(a, b entity.Follow) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if len(follows) > page.Limit {
+		follows = follows[:page.Limit]
+	}
+	return follows
+}
+
+func (s *StorageMemory) FollowerIDs(ctx context.Context, userID int64) ([]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.followerIDs(userID), nil
+}
+
+func (s *StorageMemory) followerIDs(userID int64) []int64 {
+	ids := make([]int64, 0)
+	for key := range s.Follows {
+		if key.FolloweeID == userID {
+			ids = append(ids, key.FollowerID)
+		}
+	}
+	return ids
+}
+
+func (s *StorageMemory) PushToTimelines(ctx context.Context, post entity.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, followerID := range s.followerIDs(post.User) {
+		s.pushToTimeline(followerID, post.ID)
+	}
+	return nil
+}
+
+func (s *StorageMemory) pushToTimeline(userID int64, postID int64) {
+	if _, ok := s.Timelines[userID]; !ok {
+		s.Timelines[userID] = make(map[int64]bool)
+	}
+	s.Timelines[userID][postID] = true
+}
+
+// ReconcileTimelines rebuilds timelines (fan-out on write) from the follows and the posts
+func (s *StorageMemory) ReconcileTimelines(ctx context.Context) (entity.ReconciledTimelines, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	postIDs := make(map[int64][]int64)
+	for id, post := range s.IDValuePostMap {
+		postIDs[post.User] = append(postIDs[post.User], id)
+	}
+
+	var reconciled entity.ReconciledTimelines
+	for key := range s.Follows {
+		for _, id := range postIDs[key.FolloweeID] {
+			if !s.Timelines[key.FollowerID][id] {
+				s.pushToTimeline(key.FollowerID, id)
+				reconciled.Added += 1
+			}
+		}
+	}
+	for userID, timeline := range s.Timelines {
+		for id := range timeline {
+			if _, ok := s.Follows[FollowKey{FollowerID: userID, FolloweeID: s.IDValuePostMap[id].User}]; !ok {
+				delete(timeline, id)
+				reconciled.Removed += 1
+			}
+		}
+	}
+	return reconciled, nil
+}
+
+func (s *StorageMemory) HomeFeed(ctx context.Context, userID int64, page entity.Page, fanOut string) ([]*entity.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]int64, 0)
+	if fanOut == entity.FanOutWrite {
+		for id := range s.Timelines[userID] {
+			ids = append(ids, id)
+		}
+	} else {
+		for id, post := range s.IDValuePostMap {
+			if _, ok := s.Follows[FollowKey{FollowerID: userID, FolloweeID: post.User}]; ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	ids = slices.DeleteFunc(ids, func(id int64) bool {
+		return page.AfterID != 0 && id >= page.AfterID
+	})
+	slices.SortFunc(ids, func(a, b int64) int {
+		return cmp.Compare(b, a)
+	})
+	if len(ids) > page.Limit {
+		ids = ids[:page.Limit]
+	}
+
+	posts := make([]*entity.Post, len(ids))
+	for i, id := range ids {
+		post := s.IDValuePostMap[id]
+		posts[i] = &post
+	}
+	return posts, nil
+}
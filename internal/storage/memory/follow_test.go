@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+func (ts *StoragerTestSuite) TestFollow_OncePerFollowee() {
+	ctx := context.Background()
+	follow := entity.Follow{FollowerID: rand.Int63(), FolloweeID: rand.Int63()}
+
+	followed, err := ts.Follow(ctx, follow, entity.FanOutRead)
+	ts.Require().NoError(err)
+	ts.True(followed)
+	followed, err = ts.Follow(ctx, follow, entity.FanOutRead)
+	ts.Require().NoError(err)
+	ts.False(followed)
+
+	unfollowed, err := ts.Unfollow(ctx, follow, entity.FanOutRead)
+	ts.Require().NoError(err)
+	ts.True(unfollowed)
+	unfollowed, err = ts.Unfollow(ctx, follow, entity.FanOutRead)
+	ts.Require().NoError(err)
+	ts.False(unfollowed)
+
+	followers, err := ts.Followers(ctx, follow.FolloweeID, entity.Page{Limit: 10})
+	ts.Require().NoError(err)
+	ts.Empty(followers)
+}
+
+func (ts *StoragerTestSuite) TestFollowers_Pages() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	followerIDs := []int64{rand.Int63(), rand.Int63(), rand.Int63()}
+	for _, followerID := range followerIDs {
+		_, err := ts.Follow(ctx, entity.Follow{FollowerID: followerID, FolloweeID: userID}, entity.FanOutRead)
+		ts.Require().NoError(err)
+	}
+	// follow of another user is not listed
+	_, err := ts.Follow(ctx, entity.Follow{FollowerID: followerIDs[0], FolloweeID: rand.Int63()}, entity.FanOutRead)
+	ts.Require().NoError(err)
+
+	page, err := ts.Followers(ctx, userID, entity.Page{Limit: 2})
+	ts.Require().NoError(err)
+	ts.Require().Len(page, 2)
+	ts.Equal(followerIDs[2], page[0].FollowerID)
+	ts.Equal(followerIDs[1], page[1].FollowerID)
+	ts.Equal(userID, page[0].FolloweeID)
+	ts.False(page[0].CreatedAt.IsZero())
+
+	page, err = ts.Followers(ctx, userID, entity.Page{AfterID: page[1].ID, Limit: 2})
+	ts.Require().NoError(err)
+	ts.Require().Len(page, 1)
+	ts.Equal(followerIDs[0], page[0].FollowerID)
+
+	following, err := ts.Following(ctx, followerIDs[0], entity.Page{Limit: 10})
+	ts.Require().NoError(err)
+	ts.Len(following, 2)
+
+	ids, err := ts.FollowerIDs(ctx, userID)
+	ts.Require().NoError(err)
+	ts.ElementsMatch(followerIDs, ids)
+}
+
+func (ts *StoragerTestSuite) TestHomeFeed() {
+	for _, fanOut := range []string{entity.FanOutWrite, entity.FanOutRead} {
+		ts.Run(fanOut, func() {
+			ctx := context.Background()
+			readerID, authorID, otherID := rand.Int63(), rand.Int63(), rand.Int63()
+			save := func(userID int64) int64 {
+				post := entity.Post{Text: "awesome post", User: userID}
+				id, err := savedPostID(ts.SavePost(ctx, post))
+				ts.Require().NoError(err)
+				post.ID = id
+				ts.Require().NoError(ts.PushToTimelines(ctx, post))
+				return id
+			}
+
+			// posts created before the follow are in the home feed too
+			old := save(authorID)
+			_, err := ts.Follow(ctx, entity.Follow{FollowerID: readerID, FolloweeID: authorID}, fanOut)
+			ts.Require().NoError(err)
+			save(otherID)
+			post1, post2 := save(authorID), save(authorID)
+
+			posts, err := ts.HomeFeed(ctx, readerID, entity.Page{Limit: 2}, fanOut)
+			ts.Require().NoError(err)
+			ts.Require().Len(posts, 2)
+			ts.Equal(post2, posts[0].ID)
+			ts.Equal(post1, posts[1].ID)
+			ts.Equal("awesome post", posts[0].Text)
+
+			posts, err = ts.HomeFeed(ctx, readerID, entity.Page{AfterID: post1, Limit: 2}, fanOut)
+			ts.Require().NoError(err)
+			ts.Require().Len(posts, 1)
+			ts.Equal(old, posts[0].ID)
+
+			_, err = ts.Unfollow(ctx, entity.Follow{FollowerID: readerID, FolloweeID: authorID}, fanOut)
+			ts.Require().NoError(err)
+			posts, err = ts.HomeFeed(ctx, readerID, entity.Page{Limit: 2}, fanOut)
+			ts.Require().NoError(err)
+			ts.Empty(posts)
+		})
+	}
+}
+
+func (ts *StoragerTestSuite) TestReconcileTimelines() {
+	ctx := context.Background()
+	readerID, authorID, unfollowedID := rand.Int63(), rand.Int63(), rand.Int63()
+
+	// the post is saved, but not pushed into the timeline of the follower
+	_, err := ts.Follow(ctx, entity.Follow{FollowerID: readerID, FolloweeID: authorID}, entity.FanOutWrite)
+	ts.Require().NoError(err)
+	missedID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: authorID}))
+	ts.Require().NoError(err)
+
+	// unfollow with fan-out on read keeps posts in the timeline
+	_, err = ts.Follow(ctx, entity.Follow{FollowerID: readerID, FolloweeID: unfollowedID}, entity.FanOutWrite)
+	ts.Require().NoError(err)
+	post := entity.Post{Text: "post", User: unfollowedID}
+	post.ID, err = savedPostID(ts.SavePost(ctx, post))
+	ts.Require().NoError(err)
+	ts.Require().NoError(ts.PushToTimelines(ctx, post))
+	_, err = ts.Unfollow(ctx, entity.Follow{FollowerID: readerID, FolloweeID: unfollowedID}, entity.FanOutRead)
+	ts.Require().NoError(err)
+
+	reconciled, err := ts.ReconcileTimelines(ctx)
+	ts.Require().NoError(err)
+	ts.Equal(entity.ReconciledTimelines{Added: 1, Removed: 1}, reconciled)
+
+	posts, err := ts.HomeFeed(ctx, readerID, entity.Page{Limit: 10}, entity.FanOutWrite)
+	ts.Require().NoError(err)
+	ts.Require().Len(posts, 1)
+	ts.Equal(missedID, posts[0].ID)
+
+	reconciled, err = ts.ReconcileTimelines(ctx)
+	ts.Require().NoError(err)
+	ts.Equal(entity.ReconciledTimelines{}, reconciled)
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+// client mutation id is unique for the user and entity kind
+type IdempotencyKey struct {
+	Kind   string
+	UserID int64
+	Key    string
+}
+
+type IdempotencyRecord struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+// key in the queue of the keys ordered by creation time
+type IdempotencyEntry struct {
+	Key       IdempotencyKey
+	CreatedAt time.Time
+}
+
+const (
+	kindPost    = "post"
+	kindComment = "comment"
+)
+
+func (s *StorageMemory) SavePostWithKey(ctx context.Context, post entity.Post, window time.Duration) (entity.Post, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeKeys(window)
+	key := IdempotencyKey{Kind: kindPost, UserID: post.User, Key: post.ClientMutationID}
+	if id, ok := s.replay(key, window); ok {
+		return s.IDValuePostMap[id], false, nil
+	}
+
+	saved := s.insertPost(post)
+	s.saveKey(key, saved.ID)
+
+	return saved, true, nil
+}
+
+func (s *StorageMemory) SaveCommentWithKey(ctx context.Context, comment entity.Comment, window time.Duration) (entity.Comment, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeKeys(window)
+	key := IdempotencyKey{Kind: kindComment, UserID: comment.UserID, Key: comment.ClientMutationID}
+	if id, ok := s.replay(key, window); ok {
+		return s.IDValueCommentMap[id], false, nil
+	}
+
+	if err := s.checkComment(comment); err != nil {
+		return entity.Comment{}, false, err
+	}
+	if comment.ParentCommentID != nil {
+		if err := s.checkParentComment(comment); err != nil {
+			return entity.Comment{}, false, err
+		}
+	}
+
+	id := s.insertComment(comment)
+	s.saveKey(key, id)
+
+	return s.IDValueCommentMap[id], true, nil
+}
+
+// returns id of the entity saved with the key within window
+func (s *StorageMemory) replay(key IdempotencyKey, window time.Duration) (int64, bool) {
+	record, ok := s.IdempotencyKeys[key]
+	if !ok || time.Since(record.CreatedAt) >= window {
+		return 0, false
+	}
+	return record.ID, true
+}
+
+func (s *StorageMemory) saveKey(key IdempotencyKey, id int64) {
+	now := time.Now()
+	s.IdempotencyKeys[key] = IdempotencyRecord{ID: id, CreatedAt: now}
+	s.IdempotencyQueue = append(s.IdempotencyQueue, IdempotencyEntry{Key: key, CreatedAt: now})
+}
+
+// expired keys are deleted on save of a new one from the front of the queue,
+// so only the expired keys are visited
+func (s *StorageMemory) purgeKeys(window time.Duration) {
+	n := 0
+	for _, entry := range s.IdempotencyQueue {
+		if time.Since(entry.CreatedAt) < window {
+			break
+		}
+		// the key expired in the map is saved again with a new entry
+		if record, ok := s.IdempotencyKeys[entry.Key]; ok && record.CreatedAt.Equal(entry.CreatedAt) {
+			delete(s.IdempotencyKeys, entry.Key)
+		}
+		n++
+	}
+	s.IdempotencyQueue = s.IdempotencyQueue[n:]
+}
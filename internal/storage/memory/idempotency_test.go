@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestSavePostWithKey_Replay() {
+	ctx := context.Background()
+	post := entity.Post{Text: "awesome post", User: rand.Int63(), ClientMutationID: "post-1"}
+
+	first, created, err := ts.SavePostWithKey(ctx, post, time.Hour)
+	ts.Require().NoError(err)
+	ts.True(created)
+	ts.NotZero(first.ID)
+
+	post.Text = "changed text"
+	retry, created, err := ts.SavePostWithKey(ctx, post, time.Hour)
+	ts.Require().NoError(err)
+	ts.False(created)
+	ts.Equal(first.ID, retry.ID)
+	ts.Equal("awesome post", retry.Text)
+	ts.True(first.LastActivityAt.Equal(retry.LastActivityAt))
+
+	posts, err := ts.AllPosts(ctx)
+	ts.Require().NoError(err)
+	ts.Len(posts, 1)
+}
+
+func (ts *StoragerTestSuite) TestSavePostWithKey_AnotherUser() {
+	ctx := context.Background()
+
+	first, _, err := ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: 1, ClientMutationID: "key"}, time.Hour)
+	ts.Require().NoError(err)
+	second, created, err := ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: 2, ClientMutationID: "key"}, time.Hour)
+	ts.Require().NoError(err)
+	ts.True(created)
+	ts.NotEqual(first.ID, second.ID)
+}
+
+func (ts *StoragerTestSuite) TestSavePostWithKey_WindowExpired() {
+	ctx := context.Background()
+	post := entity.Post{Text: "awesome post", User: rand.Int63(), ClientMutationID: "post-1"}
+
+	first, _, err := ts.SavePostWithKey(ctx, post, time.Millisecond)
+	ts.Require().NoError(err)
+	time.Sleep(10 * time.Millisecond)
+
+	second, created, err := ts.SavePostWithKey(ctx, post, time.Millisecond)
+	ts.Require().NoError(err)
+	ts.True(created)
+	ts.NotEqual(first.ID, second.ID)
+}
+
+func (ts *StoragerTestSuite) TestSavePostWithKey_ExpiredKeysDeleted() {
+	ctx := context.Background()
+	userID := rand.Int63()
+
+	_, _, err := ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: userID, ClientMutationID: "post-1"}, time.Millisecond)
+	ts.Require().NoError(err)
+	time.Sleep(10 * time.Millisecond)
+
+	_, _, err = ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: userID, ClientMutationID: "post-2"}, time.Millisecond)
+	ts.Require().NoError(err)
+	count, err := ts.countIdempotencyKeys(ctx)
+	ts.Require().NoError(err)
+	ts.Equal(1, count)
+}
+
+func (ts *StoragerTestSuite) TestSavePostWithKey_NotExpiredKeysKept() {
+	ctx := context.Background()
+	userID := rand.Int63()
+
+	_, _, err := ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: userID, ClientMutationID: "post-1"}, time.Hour)
+	ts.Require().NoError(err)
+	time.Sleep(50 * time.Millisecond)
+	second, _, err := ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: userID, ClientMutationID: "post-2"}, time.Hour)
+	ts.Require().NoError(err)
+
+	// only the keys older than the window are deleted
+	_, _, err = ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: userID, ClientMutationID: "post-3"}, 25*time.Millisecond)
+	ts.Require().NoError(err)
+	count, err := ts.countIdempotencyKeys(ctx)
+	ts.Require().NoError(err)
+	ts.Equal(2, count)
+
+	retry, created, err := ts.SavePostWithKey(ctx, entity.Post{Text: "post", User: userID, ClientMutationID: "post-2"}, time.Hour)
+	ts.Require().NoError(err)
+	ts.False(created)
+	ts.Equal(second.ID, retry.ID)
+}
+
+func (ts *StoragerTestSuite) TestSaveCommentWithKey_Replay() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	parentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment 1", UserID: rand.Int63(), PostID: postID})
+	ts.Require().NoError(err)
+
+	comment := entity.Comment{Text: "comment 2", UserID: rand.Int63(), PostID: postID, ParentCommentID: &parentID, ClientMutationID: "comment-2"}
+	first, created, err := ts.SaveCommentWithKey(ctx, comment, time.Hour)
+	ts.Require().NoError(err)
+	ts.True(created)
+
+	retry, created, err := ts.SaveCommentWithKey(ctx, comment, time.Hour)
+	ts.Require().NoError(err)
+	ts.False(created)
+	ts.Equal(first, retry)
+
+	limit, offset := 10, 0
+	comments, err := ts.AllComments(ctx, postID, &limit, &offset, entity.SortTree)
+	ts.Require().NoError(err)
+	ts.Len(comments, 2)
+}
+
+func (ts *StoragerTestSuite) TestSaveCommentWithKey_ErrorIsNotRemembered() {
+	ctx := context.Background()
+	comment := entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: rand.Int63(), ClientMutationID: "comment-1"}
+
+	_, _, err := ts.SaveCommentWithKey(ctx, comment, time.Hour)
+	ts.True(errors.Is(err, storage.ErrPostNotFound))
+
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	comment.PostID = postID
+	saved, created, err := ts.SaveCommentWithKey(ctx, comment, time.Hour)
+	ts.Require().NoError(err)
+	ts.True(created)
+	ts.Equal(postID, saved.PostID)
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+)
+
+func (s *StorageMemory) SaveNotifications(ctx context.Context, notifications []entity.Notification) ([]entity.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	saved := make([]entity.Notification, len(notifications))
+	for i, notification := range notifications {
+		notification.ID = s.NotificationCounter
+		s.NotificationCounter += 1
+		notification.Read = false
+		notification.CreatedAt = now
+		s.UserNotifications[notification.UserID] = append(s.UserNotifications[notification.UserID], notification)
+		saved[i] = notification
+	}
+	return saved, nil
+}
+
+func (s *StorageMemory) Notifications(ctx context.Context, userID int64, page entity.Page, unreadOnly bool) ([]entity.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.UserNotifications[userID]
+	notifications := make([]entity.Notification, 0, min(len(list), page.Limit))
+	for i := len(list) - 1; i >= 0 && len(notifications) < page.Limit; i-- {
+		if page.AfterID != 0 && list[i].ID >= page.AfterID {
+			continue
+		}
+		if unreadOnly && list[i].Read {
+			continue
+		}
+		notifications = append(notifications, list[i])
+	}
+	return notifications, nil
+}
+
+func (s *StorageMemory) MarkNotificationsRead(ctx context.Context, userID int64, ids []int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var marked map[int64]bool
+	if ids != nil {
+		marked = make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			marked[id] = true
+		}
+	}
+
+	var count int
+	list := s.UserNotifications[userID]
+	for i := range list {
+		if !list[i].Read && (ids == nil || marked[list[i].ID]) {
+			list[i].Read = true
+			count += 1
+		}
+	}
+	return count, nil
+}
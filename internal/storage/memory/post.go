@@ -3,22 +3,44 @@ package memory
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/dkrasnykh/graphql-app/internal/entity"
 	"github.com/dkrasnykh/graphql-app/internal/storage"
 )
 
-func (s *StorageMemory) SavePost(ctx context.Context, post entity.Post) (int64, error) {
+func (s *StorageMemory) SavePost(ctx context.Context, post entity.Post) (entity.Post, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.insertPost(post), nil
+}
+
+func (s *StorageMemory) SavePosts(ctx context.Context, posts []entity.Post) ([]entity.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := make([]entity.Post, len(posts))
+	for i, post := range posts {
+		saved[i] = s.insertPost(post)
+	}
+	return saved, nil
+}
+
+func (s *StorageMemory) insertPost(post entity.Post) entity.Post {
 	id := s.PostCounter
 	post.ID = id
+	post.Version = entity.FirstVersion
+	now := time.Now()
+	post.LastActivityAt = now
 	s.IDValuePostMap[id] = post
+	s.PostRevisionList[id] = []entity.Revision{{Version: post.Version, Text: post.Text, EditorID: post.User, CreatedAt: now}}
+	s.PostScores[id] = PostScore{CreatedAt: now, Hot: storage.HotTerm(now)}
 	s.PostAdjList[id] = make(map[int64][]int64)
 	s.PostCounter += 1
 
-	return id, nil
+	return post
 }
 
 func (s *StorageMemory) PostByID(ctx context.Context, id int64) (*entity.Post, error) {
@@ -65,7 +87,40 @@ func (s *StorageMemory) DisableComments(ctx context.Context, userID int64, postI
 
 	post := s.IDValuePostMap[postID]
 	post.CommentsOFF = true
+	post.Version += 1
 	s.IDValuePostMap[postID] = post
 
 	return nil
 }
+
+// version is checked under the same lock as the update
+func (s *StorageMemory) UpdatePost(ctx context.Context, edit entity.Edit) (*entity.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.IDValuePostMap[edit.ID]
+	if !ok {
+		return nil, storage.ErrPostNotFound
+	}
+	if post.User != edit.UserID && !edit.Moderator {
+		return nil, fmt.Errorf("%w, keeper ID:%d", storage.ErrAccess, post.User)
+	}
+	if post.Version != edit.ExpectedVersion {
+		return nil, fmt.Errorf("%w; current version: %d", storage.ErrVersionConflict, post.Version)
+	}
+
+	post.Text = edit.Text
+	post.Version += 1
+	s.IDValuePostMap[edit.ID] = post
+	s.PostRevisionList[edit.ID] = append(s.PostRevisionList[edit.ID],
+		entity.Revision{Version: post.Version, Text: post.Text, EditorID: edit.UserID, CreatedAt: time.Now()})
+
+	return &post, nil
+}
+
+func (s *StorageMemory) PostRevisions(ctx context.Context, postID int64) ([]entity.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.PostRevisionList[postID]), nil
+}
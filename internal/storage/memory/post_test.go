@@ -13,13 +13,17 @@ import (
 func (ts *StoragerTestSuite) TestSavePost_OK() {
 	post := entity.Post{Text: "awesome post", User: int64(3)}
 
-	postID, err := ts.SavePost(context.Background(), post)
-	ts.NoError(err)
-
-	saved, err := ts.PostByID(context.Background(), postID)
-	ts.NoError(err)
-	ts.Equal(post.Text, saved.Text)
-	ts.Equal(post.User, saved.User)
+	saved, err := ts.SavePost(context.Background(), post)
+	ts.Require().NoError(err)
+	ts.NotZero(saved.ID)
+	ts.EqualValues(entity.FirstVersion, saved.Version)
+
+	// the saved post has the stored creation time
+	stored, err := ts.PostByID(context.Background(), saved.ID)
+	ts.Require().NoError(err)
+	ts.Equal(post.Text, stored.Text)
+	ts.Equal(post.User, stored.User)
+	ts.True(stored.LastActivityAt.Equal(saved.LastActivityAt))
 }
 
 func (ts *StoragerTestSuite) TestPostByID_PostNotFound() {
@@ -57,7 +61,7 @@ func (ts *StoragerTestSuite) TestDisableComments_PostNotFound() {
 func (ts *StoragerTestSuite) TestDisableComments_PostBelongAnotherUser() {
 	userID := int64(1)
 	post := entity.Post{Text: "awesome post", User: userID}
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	anotherUserID := int64(2)
@@ -68,7 +72,7 @@ func (ts *StoragerTestSuite) TestDisableComments_PostBelongAnotherUser() {
 func (ts *StoragerTestSuite) TestDisableComments_PostAlreadyDisabled() {
 	userID := rand.Int63()
 	post := entity.Post{Text: "awesome post", User: userID, CommentsOFF: true}
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	err = ts.DisableComments(context.Background(), userID, postID)
@@ -78,7 +82,7 @@ func (ts *StoragerTestSuite) TestDisableComments_PostAlreadyDisabled() {
 func (ts *StoragerTestSuite) TestDisableComments_OK() {
 	userID := rand.Int63()
 	post := entity.Post{Text: "awesome post", User: userID}
-	postID, err := ts.SavePost(context.Background(), post)
+	postID, err := savedPostID(ts.SavePost(context.Background(), post))
 	ts.NoError(err)
 
 	err = ts.DisableComments(context.Background(), userID, postID)
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// post or comment with reactions
+type ReactionTarget struct {
+	Type string
+	ID   int64
+}
+
+func (s *StorageMemory) CommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.IDValueCommentMap[id]
+	if !ok {
+		return nil, storage.ErrCommentNotFound
+	}
+	return &comment, nil
+}
+
+func (s *StorageMemory) AddReaction(ctx context.Context, reaction entity.Reaction) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkReactionTarget(reaction); err != nil {
+		return false, err
+	}
+
+	target := ReactionTarget{Type: reaction.TargetType, ID: reaction.TargetID}
+	if s.Reactions[target] == nil {
+		s.Reactions[target] = make(map[string]map[int64]time.Time)
+	}
+	users := s.Reactions[target][reaction.Emoji]
+	if users == nil {
+		users = make(map[int64]time.Time)
+		s.Reactions[target][reaction.Emoji] = users
+	}
+	if _, ok := users[reaction.UserID]; ok {
+		return false, nil
+	}
+	now := time.Now()
+	users[reaction.UserID] = now
+	if reaction.TargetType == entity.TargetPost {
+		s.addActivity(reaction.TargetID, now)
+	}
+
+	return true, nil
+}
+
+func (s *StorageMemory) RemoveReaction(ctx context.Context, reaction entity.Reaction) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkReactionTarget(reaction); err != nil {
+		return false, err
+	}
+
+	target := ReactionTarget{Type: reaction.TargetType, ID: reaction.TargetID}
+	users := s.Reactions[target][reaction.Emoji]
+	createdAt, ok := users[reaction.UserID]
+	if !ok {
+		return false, nil
+	}
+	delete(users, reaction.UserID)
+	if reaction.TargetType == entity.TargetPost {
+		s.removeActivity(reaction.TargetID, createdAt)
+	}
+	if len(users) == 0 {
+		delete(s.Reactions[target], reaction.Emoji)
+	}
+
+	return true, nil
+}
+
+func (s *StorageMemory) checkReactionTarget(reaction entity.Reaction) error {
+	if reaction.TargetType == entity.TargetComment {
+		if _, ok := s.IDValueCommentMap[reaction.TargetID]; !ok {
+			return storage.ErrCommentNotFound
+		}
+		return nil
+	}
+	if _, ok := s.IDValuePostMap[reaction.TargetID]; !ok {
+		return storage.ErrPostNotFound
+	}
+	return nil
+}
+
+func (s *StorageMemory) ReactionSummaries(ctx context.Context, targetType string, targetIDs []int64, viewerID *int64) (map[int64][]entity.ReactionSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make(map[int64][]entity.ReactionSummary)
+	for _, id := range targetIDs {
+		emojis := s.Reactions[ReactionTarget{Type: targetType, ID: id}]
+		if len(emojis) == 0 {
+			continue
+		}
+		summaries := make([]entity.ReactionSummary, 0, len(emojis))
+		for emoji, users := range emojis {
+			summary := entity.ReactionSummary{Emoji: emoji, Count: len(users)}
+			if viewerID != nil {
+				_, summary.ViewerHasReacted = users[*viewerID]
+			}
+			summaries = append(summaries, summary)
+		}
+		slices.SortFunc(summaries, compareReactionSummaries)
+		all[id] = summaries
+	}
+	return all, nil
+}
+
+// most popular emoji goes first
+func compareReactionSummaries(a, b entity.ReactionSummary) int {
+	if a.Count != b.Count {
+		return b.Count - a.Count
+	}
+	return strings.Compare(a.Emoji, b.Emoji)
+}
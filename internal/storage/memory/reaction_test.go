@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestAddReaction_OncePerUser() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+
+	reaction := entity.Reaction{TargetType: entity.TargetPost, TargetID: postID, UserID: rand.Int63(), Emoji: "👍"}
+	added, err := ts.AddReaction(ctx, reaction)
+	ts.Require().NoError(err)
+	ts.True(added)
+	added, err = ts.AddReaction(ctx, reaction)
+	ts.Require().NoError(err)
+	ts.False(added)
+
+	summaries, err := ts.ReactionSummaries(ctx, entity.TargetPost, []int64{postID}, nil)
+	ts.Require().NoError(err)
+	ts.Equal([]entity.ReactionSummary{{Emoji: "👍", Count: 1}}, summaries[postID])
+}
+
+func (ts *StoragerTestSuite) TestRemoveReaction() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "awesome comment", UserID: rand.Int63(), PostID: postID})
+	ts.Require().NoError(err)
+
+	reaction := entity.Reaction{TargetType: entity.TargetComment, TargetID: commentID, UserID: rand.Int63(), Emoji: "🔥"}
+	removed, err := ts.RemoveReaction(ctx, reaction)
+	ts.Require().NoError(err)
+	ts.False(removed)
+
+	_, err = ts.AddReaction(ctx, reaction)
+	ts.Require().NoError(err)
+	removed, err = ts.RemoveReaction(ctx, reaction)
+	ts.Require().NoError(err)
+	ts.True(removed)
+
+	summaries, err := ts.ReactionSummaries(ctx, entity.TargetComment, []int64{commentID}, nil)
+	ts.Require().NoError(err)
+	ts.Empty(summaries[commentID])
+}
+
+func (ts *StoragerTestSuite) TestReactionSummaries_OrderAndViewer() {
+	ctx := context.Background()
+	post1, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "post 1", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	post2, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "post 2", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: post1})
+	ts.Require().NoError(err)
+
+	viewerID, otherID := rand.Int63(), rand.Int63()
+	reactions := []entity.Reaction{
+		{TargetType: entity.TargetPost, TargetID: post1, UserID: viewerID, Emoji: "🔥"},
+		{TargetType: entity.TargetPost, TargetID: post1, UserID: otherID, Emoji: "🔥"},
+		{TargetType: entity.TargetPost, TargetID: post1, UserID: otherID, Emoji: "👍"},
+		{TargetType: entity.TargetPost, TargetID: post1, UserID: otherID, Emoji: "🎉"},
+		{TargetType: entity.TargetPost, TargetID: post2, UserID: otherID, Emoji: "👍"},
+		// the same id with another target type is not counted
+		{TargetType: entity.TargetComment, TargetID: commentID, UserID: viewerID, Emoji: "👍"},
+	}
+	for _, reaction := range reactions {
+		_, err = ts.AddReaction(ctx, reaction)
+		ts.Require().NoError(err)
+	}
+
+	summaries, err := ts.ReactionSummaries(ctx, entity.TargetPost, []int64{post1, post2, rand.Int63()}, &viewerID)
+	ts.Require().NoError(err)
+	ts.Len(summaries, 2)
+	ts.Equal([]entity.ReactionSummary{
+		{Emoji: "🔥", Count: 2, ViewerHasReacted: true},
+		{Emoji: "🎉", Count: 1},
+		{Emoji: "👍", Count: 1},
+	}, summaries[post1])
+	ts.Equal([]entity.ReactionSummary{{Emoji: "👍", Count: 1}}, summaries[post2])
+}
+
+func (ts *StoragerTestSuite) TestAddReaction_TargetNotFound() {
+	ctx := context.Background()
+	_, err := ts.AddReaction(ctx, entity.Reaction{TargetType: entity.TargetPost, TargetID: rand.Int63(), UserID: rand.Int63(), Emoji: "👍"})
+	ts.True(errors.Is(err, storage.ErrPostNotFound))
+	_, err = ts.RemoveReaction(ctx, entity.Reaction{TargetType: entity.TargetComment, TargetID: rand.Int63(), UserID: rand.Int63(), Emoji: "👍"})
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
+
+func (ts *StoragerTestSuite) TestCommentByID() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	comment := entity.Comment{Text: "awesome comment", UserID: rand.Int63(), PostID: postID}
+	comment.ID, err = ts.SaveComment(ctx, comment)
+	ts.Require().NoError(err)
+	comment.Version = entity.FirstVersion
+
+	actual, err := ts.CommentByID(ctx, comment.ID)
+	ts.Require().NoError(err)
+	ts.Equal(comment, *actual)
+
+	_, err = ts.CommentByID(ctx, rand.Int63())
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
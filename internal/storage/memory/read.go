@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+type ReadMarkKey struct {
+	UserID int64
+	PostID int64
+}
+
+// id of the last read comment and number of comments of the post up to it
+type ReadPosition struct {
+	CommentID int64
+	Count     int64
+}
+
+// the read mark only moves forward, so a request from an old tab does not make read comments unread
+func (s *StorageMemory) MarkPostRead(ctx context.Context, mark entity.ReadMark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.IDValuePostMap[mark.PostID]; !ok {
+		return storage.ErrPostNotFound
+	}
+	commentID := mark.CommentID
+	if commentID == 0 {
+		ids := s.PostComments[mark.PostID]
+		if len(ids) == 0 {
+			return nil
+		}
+		commentID = ids[len(ids)-1]
+	} else if comment, ok := s.IDValueCommentMap[commentID]; !ok || comment.PostID != mark.PostID {
+		return storage.ErrCommentNotFound
+	}
+
+	key := ReadMarkKey{UserID: mark.UserID, PostID: mark.PostID}
+	if commentID > s.ReadMarks[key].CommentID {
+		read, _ := slices.BinarySearch(s.PostComments[mark.PostID], commentID+1)
+		s.ReadMarks[key] = ReadPosition{CommentID: commentID, Count: int64(read)}
+	}
+	return nil
+}
+
+// unread comments are the counter of the post without the comments read up to the mark
+// (the counter may lag behind until the reconciliation, so the post with the first unread comment has at least one),
+// comment ids of the post are sorted, so the first unread comment is found with binary search
+func (s *StorageMemory) UnreadComments(ctx context.Context, userID int64, postIDs []int64) (map[int64]entity.Unread, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make(map[int64]entity.Unread, len(postIDs))
+	for _, postID := range postIDs {
+		ids := s.PostComments[postID]
+		mark := s.ReadMarks[ReadMarkKey{UserID: userID, PostID: postID}]
+		first, _ := slices.BinarySearch(ids, mark.CommentID+1)
+		if first == len(ids) {
+			continue
+		}
+		count := max(s.IDValuePostMap[postID].CommentCount-mark.Count, 1)
+		all[postID] = entity.Unread{Count: int(count), FirstCommentID: ids[first]}
+	}
+	return all, nil
+}
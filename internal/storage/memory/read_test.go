@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestMarkPostRead_UnreadComments() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: 1}))
+	ts.Require().NoError(err)
+	otherPostID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "other post", User: 1}))
+	ts.Require().NoError(err)
+	ids := make([]int64, 3)
+	for i := range ids {
+		ids[i], err = ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: 2, PostID: postID})
+		ts.Require().NoError(err)
+	}
+
+	// the post without a read mark has all comments unread, the post without comments is missing
+	unread, err := ts.UnreadComments(ctx, userID, []int64{postID, otherPostID})
+	ts.Require().NoError(err)
+	ts.Equal(map[int64]entity.Unread{postID: {Count: 3, FirstCommentID: ids[0]}}, unread)
+
+	ts.Require().NoError(ts.MarkPostRead(ctx, entity.ReadMark{UserID: userID, PostID: postID, CommentID: ids[1]}))
+	// the mark does not move back
+	ts.Require().NoError(ts.MarkPostRead(ctx, entity.ReadMark{UserID: userID, PostID: postID, CommentID: ids[0]}))
+	unread, err = ts.UnreadComments(ctx, userID, []int64{postID})
+	ts.Require().NoError(err)
+	ts.Equal(entity.Unread{Count: 1, FirstCommentID: ids[2]}, unread[postID])
+
+	// read marks are kept per user
+	unread, err = ts.UnreadComments(ctx, rand.Int63(), []int64{postID})
+	ts.Require().NoError(err)
+	ts.Equal(3, unread[postID].Count)
+
+	ts.Require().NoError(ts.MarkPostRead(ctx, entity.ReadMark{UserID: userID, PostID: postID}))
+	unread, err = ts.UnreadComments(ctx, userID, []int64{postID})
+	ts.Require().NoError(err)
+	ts.Empty(unread)
+
+	newID, err := ts.SaveComment(ctx, entity.Comment{Text: "new comment", UserID: 2, PostID: postID})
+	ts.Require().NoError(err)
+	unread, err = ts.UnreadComments(ctx, userID, []int64{postID})
+	ts.Require().NoError(err)
+	ts.Equal(entity.Unread{Count: 1, FirstCommentID: newID}, unread[postID])
+
+	// the mark in the middle keeps the number of comments read up to it
+	lastID, err := ts.SaveComment(ctx, entity.Comment{Text: "last comment", UserID: 2, PostID: postID})
+	ts.Require().NoError(err)
+	unread, err = ts.UnreadComments(ctx, userID, []int64{postID})
+	ts.Require().NoError(err)
+	ts.Equal(entity.Unread{Count: 2, FirstCommentID: newID}, unread[postID])
+	ts.Require().NoError(ts.MarkPostRead(ctx, entity.ReadMark{UserID: userID, PostID: postID, CommentID: newID}))
+	unread, err = ts.UnreadComments(ctx, userID, []int64{postID})
+	ts.Require().NoError(err)
+	ts.Equal(entity.Unread{Count: 1, FirstCommentID: lastID}, unread[postID])
+
+	// marking the post without comments is a no-op
+	ts.Require().NoError(ts.MarkPostRead(ctx, entity.ReadMark{UserID: userID, PostID: otherPostID}))
+}
+
+func (ts *StoragerTestSuite) TestMarkPostRead_Errors() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: 1}))
+	ts.Require().NoError(err)
+	otherPostID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "other post", User: 1}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: 2, PostID: otherPostID})
+	ts.Require().NoError(err)
+
+	err = ts.MarkPostRead(ctx, entity.ReadMark{UserID: 1, PostID: rand.Int63()})
+	ts.True(errors.Is(err, storage.ErrPostNotFound))
+	err = ts.MarkPostRead(ctx, entity.ReadMark{UserID: 1, PostID: postID, CommentID: commentID})
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+	err = ts.MarkPostRead(ctx, entity.ReadMark{UserID: 1, PostID: postID, CommentID: rand.Int63()})
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestPostRevisions_Order() {
+	ctx := context.Background()
+	authorID, moderatorID := rand.Int63(), rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "text 1", User: authorID}))
+	ts.Require().NoError(err)
+
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: authorID, Text: "text 2", ExpectedVersion: 1})
+	ts.Require().NoError(err)
+	// comments switch changes version, but not text
+	ts.Require().NoError(ts.DisableComments(ctx, authorID, postID))
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: moderatorID, Text: "text 4", ExpectedVersion: 3, Moderator: true})
+	ts.Require().NoError(err)
+
+	revisions, err := ts.PostRevisions(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Require().Len(revisions, 3)
+
+	expected := []entity.Revision{
+		{Version: 1, Text: "text 1", EditorID: authorID},
+		{Version: 2, Text: "text 2", EditorID: authorID},
+		{Version: 4, Text: "text 4", EditorID: moderatorID},
+	}
+	for i, revision := range revisions {
+		ts.Equal(expected[i].Version, revision.Version)
+		ts.Equal(expected[i].Text, revision.Text)
+		ts.Equal(expected[i].EditorID, revision.EditorID)
+		ts.False(revision.CreatedAt.IsZero())
+		if i > 0 {
+			ts.False(revision.CreatedAt.Before(revisions[i-1].CreatedAt))
+		}
+	}
+}
+
+func (ts *StoragerTestSuite) TestCommentRevisions_Order() {
+	ctx := context.Background()
+	authorID, moderatorID := rand.Int63(), rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "text 1", UserID: authorID, PostID: postID})
+	ts.Require().NoError(err)
+
+	_, err = ts.UpdateComment(ctx, entity.Edit{ID: commentID, UserID: moderatorID, Text: "text 2", ExpectedVersion: 1, Moderator: true})
+	ts.Require().NoError(err)
+	_, err = ts.UpdateComment(ctx, entity.Edit{ID: commentID, UserID: authorID, Text: "text 3", ExpectedVersion: 2})
+	ts.Require().NoError(err)
+
+	revisions, err := ts.CommentRevisions(ctx, commentID)
+	ts.Require().NoError(err)
+	ts.Require().Len(revisions, 3)
+	for i, revision := range revisions {
+		ts.Equal(int64(i+1), revision.Version)
+	}
+	ts.Equal("text 1", revisions[0].Text)
+	ts.Equal(moderatorID, revisions[1].EditorID)
+	ts.Equal("text 3", revisions[2].Text)
+	ts.Equal(authorID, revisions[2].EditorID)
+}
+
+func (ts *StoragerTestSuite) TestRevisions_FailedUpdateIsNotSaved() {
+	ctx := context.Background()
+	authorID := rand.Int63()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "text 1", User: authorID}))
+	ts.Require().NoError(err)
+
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: authorID + 1, Text: "text 2", ExpectedVersion: 1})
+	ts.True(errors.Is(err, storage.ErrAccess))
+	_, err = ts.UpdatePost(ctx, entity.Edit{ID: postID, UserID: authorID, Text: "text 2", ExpectedVersion: 2})
+	ts.True(errors.Is(err, storage.ErrVersionConflict))
+
+	revisions, err := ts.PostRevisions(ctx, postID)
+	ts.Require().NoError(err)
+	ts.Len(revisions, 1)
+}
+
+func (ts *StoragerTestSuite) TestRevisions_Batch() {
+	ctx := context.Background()
+	userID := rand.Int63()
+	posts, err := ts.SavePosts(ctx, []entity.Post{{Text: "post 1", User: userID}, {Text: "post 2", User: userID}})
+	ts.Require().NoError(err)
+
+	parent := 0
+	commentIDs, err := ts.SaveComments(ctx, []entity.BatchComment{
+		{Comment: entity.Comment{Text: "comment 1", UserID: userID, PostID: posts[0].ID}},
+		{Comment: entity.Comment{Text: "comment 2", UserID: userID, PostID: posts[0].ID}, ParentIndex: &parent},
+	})
+	ts.Require().NoError(err)
+
+	revisions, err := ts.PostRevisions(ctx, posts[1].ID)
+	ts.Require().NoError(err)
+	ts.Require().Len(revisions, 1)
+	ts.Equal("post 2", revisions[0].Text)
+
+	revisions, err = ts.CommentRevisions(ctx, commentIDs[1])
+	ts.Require().NoError(err)
+	ts.Require().Len(revisions, 1)
+	ts.Equal(int64(entity.FirstVersion), revisions[0].Version)
+	ts.Equal("comment 2", revisions[0].Text)
+	ts.Equal(userID, revisions[0].EditorID)
+}
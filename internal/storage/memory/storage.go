@@ -1,7 +1,9 @@
 package memory
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/dkrasnykh/graphql-app/internal/entity"
 )
@@ -9,29 +11,79 @@ import (
 // all structures are under one mutex, because a possible case:
 // when one goroutine adds a comment for post, and another disables comments for this post
 type StorageMemory struct {
-	mu                sync.RWMutex
-	PostCounter       int64
-	CommentCounter    int64
-	IDValuePostMap    map[int64]entity.Post
-	IDValueCommentMap map[int64]entity.Comment
+	mu             sync.RWMutex
+	PostCounter    int64
+	CommentCounter int64
+	FollowCounter  int64
+	// notifications have ids unique across users
+	NotificationCounter int64
+	IDValuePostMap      map[int64]entity.Post
+	IDValueCommentMap   map[int64]entity.Comment
 	// for each comments store root comments
 	PostRootComments map[int64][]int64
 	// for each post store comments adjacency list
 	PostAdjList map[int64]map[int64][]int64
+	// for each reply store id of the parent comment (reverse of the adjacency list)
+	CommentParents map[int64]int64
+	// for each post store ids of comments in the order of creation (ascending)
+	PostComments map[int64][]int64
+	// for each post (comment) store all texts ordered by version
+	PostRevisionList    map[int64][]entity.Revision
+	CommentRevisionList map[int64][]entity.Revision
+	// for each comment store votes of users
+	CommentVotes map[int64]map[int64]int
+	// for each target store users, who added the emoji, and time of the reaction
+	Reactions map[ReactionTarget]map[string]map[int64]time.Time
+	// for each post store creation time and scores of the feed
+	PostScores map[int64]PostScore
+	// ranking snapshots of the feed by id
+	FeedSnapshots map[string]entity.FeedSnapshot
+	// follows by follower and followee
+	Follows map[FollowKey]entity.Follow
+	// for each user store ids of posts of the home feed (fan-out on write)
+	Timelines map[int64]map[int64]bool
+	// for each user store notifications ordered by id
+	UserNotifications map[int64][]entity.Notification
+	// for each user and post store id of the last read comment
+	ReadMarks map[ReadMarkKey]ReadPosition
+	// ids of posts and comments created with client mutation id
+	IdempotencyKeys map[IdempotencyKey]IdempotencyRecord
+	// keys in the order of creation, expired keys are deleted from the front
+	IdempotencyQueue []IdempotencyEntry
 }
 
 func New() *StorageMemory {
 	return &StorageMemory{
-		mu:                sync.RWMutex{},
-		PostCounter:       1,
-		CommentCounter:    1,
-		IDValuePostMap:    make(map[int64]entity.Post),
-		IDValueCommentMap: make(map[int64]entity.Comment),
-		PostRootComments:  make(map[int64][]int64),
-		PostAdjList:       make(map[int64]map[int64][]int64),
+		mu:                  sync.RWMutex{},
+		PostCounter:         1,
+		CommentCounter:      1,
+		FollowCounter:       1,
+		NotificationCounter: 1,
+		IDValuePostMap:      make(map[int64]entity.Post),
+		IDValueCommentMap:   make(map[int64]entity.Comment),
+		PostRootComments:    make(map[int64][]int64),
+		PostAdjList:         make(map[int64]map[int64][]int64),
+		CommentParents:      make(map[int64]int64),
+		PostComments:        make(map[int64][]int64),
+		PostRevisionList:    make(map[int64][]entity.Revision),
+		CommentRevisionList: make(map[int64][]entity.Revision),
+		CommentVotes:        make(map[int64]map[int64]int),
+		Reactions:           make(map[ReactionTarget]map[string]map[int64]time.Time),
+		PostScores:          make(map[int64]PostScore),
+		FeedSnapshots:       make(map[string]entity.FeedSnapshot),
+		Follows:             make(map[FollowKey]entity.Follow),
+		Timelines:           make(map[int64]map[int64]bool),
+		UserNotifications:   make(map[int64][]entity.Notification),
+		ReadMarks:           make(map[ReadMarkKey]ReadPosition),
+		IdempotencyKeys:     make(map[IdempotencyKey]IdempotencyRecord),
 	}
 }
 
+// memory storage is always ready
+func (s *StorageMemory) Health(ctx context.Context) error {
+	return nil
+}
+
 // used only in unit tests
 func (s *StorageMemory) Clear() {
 	s.mu.Lock()
@@ -39,8 +91,24 @@ func (s *StorageMemory) Clear() {
 
 	s.CommentCounter = 1
 	s.PostCounter = 1
+	s.FollowCounter = 1
+	s.NotificationCounter = 1
 	s.PostAdjList = make(map[int64]map[int64][]int64)
+	s.CommentParents = make(map[int64]int64)
+	s.PostComments = make(map[int64][]int64)
 	s.IDValuePostMap = make(map[int64]entity.Post)
 	s.IDValueCommentMap = make(map[int64]entity.Comment)
 	s.PostRootComments = make(map[int64][]int64)
+	s.PostRevisionList = make(map[int64][]entity.Revision)
+	s.CommentRevisionList = make(map[int64][]entity.Revision)
+	s.CommentVotes = make(map[int64]map[int64]int)
+	s.Reactions = make(map[ReactionTarget]map[string]map[int64]time.Time)
+	s.PostScores = make(map[int64]PostScore)
+	s.FeedSnapshots = make(map[string]entity.FeedSnapshot)
+	s.Follows = make(map[FollowKey]entity.Follow)
+	s.Timelines = make(map[int64]map[int64]bool)
+	s.UserNotifications = make(map[int64][]entity.Notification)
+	s.ReadMarks = make(map[ReadMarkKey]ReadPosition)
+	s.IdempotencyKeys = make(map[IdempotencyKey]IdempotencyRecord)
+	s.IdempotencyQueue = nil
 }
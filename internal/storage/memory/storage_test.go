@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -10,18 +11,59 @@ import (
 )
 
 type Storager interface {
-	SavePost(ctx context.Context, post entity.Post) (int64, error)
+	SavePost(ctx context.Context, post entity.Post) (entity.Post, error)
+	SavePostWithKey(ctx context.Context, post entity.Post, window time.Duration) (entity.Post, bool, error)
+	SavePosts(ctx context.Context, posts []entity.Post) ([]entity.Post, error)
 	PostByID(ctx context.Context, id int64) (*entity.Post, error)
 	AllPosts(ctx context.Context) ([]*entity.Post, error)
+	UpdatePost(ctx context.Context, edit entity.Edit) (*entity.Post, error)
+	PostRevisions(ctx context.Context, postID int64) ([]entity.Revision, error)
 	DisableComments(ctx context.Context, userID int64, postID int64) error
+	PostsByIDs(ctx context.Context, ids []int64) ([]*entity.Post, error)
+	RankedPostIDs(ctx context.Context, query entity.FeedQuery) ([]int64, error)
+	SaveFeedSnapshot(ctx context.Context, snapshot entity.FeedSnapshot) (*entity.FeedSnapshot, error)
+	FeedSnapshot(ctx context.Context, id string) (*entity.FeedSnapshot, error)
+	DeleteExpiredFeedSnapshots(ctx context.Context) error
+	Follow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error)
+	Unfollow(ctx context.Context, follow entity.Follow, fanOut string) (bool, error)
+	Followers(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error)
+	Following(ctx context.Context, userID int64, page entity.Page) ([]entity.Follow, error)
+	FollowerIDs(ctx context.Context, userID int64) ([]int64, error)
+	PushToTimelines(ctx context.Context, post entity.Post) error
+	HomeFeed(ctx context.Context, userID int64, page entity.Page, fanOut string) ([]*entity.Post, error)
+	SaveNotifications(ctx context.Context, notifications []entity.Notification) ([]entity.Notification, error)
+	Notifications(ctx context.Context, userID int64, page entity.Page, unreadOnly bool) ([]entity.Notification, error)
+	MarkNotificationsRead(ctx context.Context, userID int64, ids []int64) (int, error)
+	MarkPostRead(ctx context.Context, mark entity.ReadMark) error
+	UnreadComments(ctx context.Context, userID int64, postIDs []int64) (map[int64]entity.Unread, error)
+	ReconcileCounters(ctx context.Context) (entity.Reconciled, error)
+	ReconcileTimelines(ctx context.Context) (entity.ReconciledTimelines, error)
 
 	SaveComment(ctx context.Context, comment entity.Comment) (int64, error)
-	AllComments(ctx context.Context, postID int64, limit *int, offset *int) ([]*entity.Comment, error)
+	SaveCommentWithKey(ctx context.Context, comment entity.Comment, window time.Duration) (entity.Comment, bool, error)
+	SaveComments(ctx context.Context, comments []entity.BatchComment) ([]int64, error)
+	AllComments(ctx context.Context, postID int64, limit *int, offset *int, sort string) ([]*entity.Comment, error)
+	CommentsAfter(ctx context.Context, postID int64, afterID int64, inclusive bool, limit *int, offset *int) ([]*entity.Comment, error)
+	CommentContext(ctx context.Context, id int64, ancestors int, descendants int) (*entity.CommentContext, error)
+	Vote(ctx context.Context, vote entity.Vote) (*entity.Comment, error)
+	UpdateComment(ctx context.Context, edit entity.Edit) (*entity.Comment, error)
+	CommentRevisions(ctx context.Context, commentID int64) ([]entity.Revision, error)
+	CommentByID(ctx context.Context, id int64) (*entity.Comment, error)
+
+	AddReaction(ctx context.Context, reaction entity.Reaction) (bool, error)
+	RemoveReaction(ctx context.Context, reaction entity.Reaction) (bool, error)
+	ReactionSummaries(ctx context.Context, targetType string, targetIDs []int64, viewerID *int64) (map[int64][]entity.ReactionSummary, error)
+
+	Health(ctx context.Context) error
 }
 
 type testStorager interface {
 	Storager
 	clean(ctx context.Context)
+	// breaks counters of all posts and comments for the reconciliation tests
+	resetCounters(ctx context.Context) error
+	// returns number of kept idempotency keys
+	countIdempotencyKeys(ctx context.Context) (int, error)
 }
 
 type StoragerTestSuite struct {
@@ -39,10 +81,54 @@ func (s *StorageMemory) clean(ctx context.Context) {
 
 	s.CommentCounter = 1
 	s.PostCounter = 1
+	s.FollowCounter = 1
+	s.NotificationCounter = 1
 	s.PostAdjList = make(map[int64]map[int64][]int64)
+	s.CommentParents = make(map[int64]int64)
+	s.PostComments = make(map[int64][]int64)
 	s.IDValuePostMap = make(map[int64]entity.Post)
 	s.IDValueCommentMap = make(map[int64]entity.Comment)
 	s.PostRootComments = make(map[int64][]int64)
+	s.PostRevisionList = make(map[int64][]entity.Revision)
+	s.CommentRevisionList = make(map[int64][]entity.Revision)
+	s.CommentVotes = make(map[int64]map[int64]int)
+	s.Reactions = make(map[ReactionTarget]map[string]map[int64]time.Time)
+	s.PostScores = make(map[int64]PostScore)
+	s.FeedSnapshots = make(map[string]entity.FeedSnapshot)
+	s.Follows = make(map[FollowKey]entity.Follow)
+	s.Timelines = make(map[int64]map[int64]bool)
+	s.UserNotifications = make(map[int64][]entity.Notification)
+	s.ReadMarks = make(map[ReadMarkKey]ReadPosition)
+	s.IdempotencyKeys = make(map[IdempotencyKey]IdempotencyRecord)
+	s.IdempotencyQueue = nil
+}
+
+func (s *StorageMemory) resetCounters(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, post := range s.IDValuePostMap {
+		post.CommentCount = 0
+		post.LastActivityAt = time.Time{}
+		s.IDValuePostMap[id] = post
+	}
+	for id, comment := range s.IDValueCommentMap {
+		comment.ReplyCount = 0
+		s.IDValueCommentMap[id] = comment
+	}
+	return nil
+}
+
+// returns id of the post saved by SavePost
+func savedPostID(post entity.Post, err error) (int64, error) {
+	return post.ID, err
+}
+
+func (s *StorageMemory) countIdempotencyKeys(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.IdempotencyKeys), nil
 }
 
 func (ts *StoragerTestSuite) TearDownSuite() {
@@ -60,3 +146,7 @@ func (ts *StoragerTestSuite) SetupTest() {
 func (ts *StoragerTestSuite) TearDownTest() {
 	ts.clean(context.Background())
 }
+
+func (ts *StoragerTestSuite) TestHealth_OK() {
+	ts.Require().NoError(ts.Health(context.Background()))
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// ancestors are found with the parent index, descendants with the adjacency list of the post
+func (s *StorageMemory) CommentContext(ctx context.Context, id int64, ancestors int, descendants int) (*entity.CommentContext, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.IDValueCommentMap[id]
+	if !ok {
+		return nil, storage.ErrCommentNotFound
+	}
+	thread := entity.CommentContext{Comment: comment}
+
+	for v, ok := s.CommentParents[id]; ok && len(thread.Ancestors) < ancestors; v, ok = s.CommentParents[v] {
+		ancestor := s.IDValueCommentMap[v]
+		thread.Ancestors = append(thread.Ancestors, &ancestor)
+	}
+	slices.Reverse(thread.Ancestors)
+
+	s.walkTree(comment.PostID, s.PostAdjList[comment.PostID][id], func(v int64) bool {
+		if len(thread.Descendants) == descendants {
+			return false
+		}
+		descendant := s.IDValueCommentMap[v]
+		thread.Descendants = append(thread.Descendants, &descendant)
+		return true
+	})
+
+	return &thread, nil
+}
+
+// default values limit = 10, offset = 0 (graphql schema), offset is counted from the comment afterID
+func (s *StorageMemory) CommentsAfter(ctx context.Context, postID int64, afterID int64, inclusive bool, limit *int, offset *int) ([]*entity.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if comment, ok := s.IDValueCommentMap[afterID]; !ok || comment.PostID != postID {
+		return nil, storage.ErrCommentNotFound
+	}
+
+	commentsList := make([]*entity.Comment, 0)
+	found := false
+	i := 0
+	s.walkTree(postID, s.PostRootComments[postID], func(v int64) bool {
+		if !found {
+			found = v == afterID
+			if !found || !inclusive {
+				return true
+			}
+		}
+		if len(commentsList) == *limit {
+			return false
+		}
+		if *offset <= i {
+			comm := s.IDValueCommentMap[v]
+			commentsList = append(commentsList, &comm)
+		}
+		i += 1
+		return true
+	})
+
+	return commentsList, nil
+}
+
+// visits subtrees of the comments in the TREE order until visit returns false
+func (s *StorageMemory) walkTree(postID int64, ids []int64, visit func(v int64) bool) bool {
+	for _, v := range ids {
+		if !visit(v) || !s.walkTree(postID, s.PostAdjList[postID][v], visit) {
+			return false
+		}
+	}
+	return true
+}
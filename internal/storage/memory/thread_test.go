@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func commentIDs(list []*entity.Comment) []int64 {
+	ids := make([]int64, len(list))
+	for i, c := range list {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+/*
+post
+|-> root
+|	|-> a
+|	|	|-> a1
+|	|	|	|-> a11
+|	|	|-> a2
+|	|-> b
+|-> root2
+*/
+func (ts *StoragerTestSuite) TestCommentContext() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: 1}))
+	ts.Require().NoError(err)
+	save := func(parentID *int64) int64 {
+		id, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: 2, PostID: postID, ParentCommentID: parentID})
+		ts.Require().NoError(err)
+		return id
+	}
+	root := save(nil)
+	a := save(&root)
+	b := save(&root)
+	a1 := save(&a)
+	a2 := save(&a)
+	a11 := save(&a1)
+	save(nil)
+
+	thread, err := ts.CommentContext(ctx, a1, 10, 10)
+	ts.Require().NoError(err)
+	ts.Equal(a1, thread.Comment.ID)
+	ts.Equal([]int64{root, a}, commentIDs(thread.Ancestors))
+	ts.Equal([]int64{a11}, commentIDs(thread.Descendants))
+
+	// the nearest ancestors only
+	thread, err = ts.CommentContext(ctx, a11, 1, 0)
+	ts.Require().NoError(err)
+	ts.Equal([]int64{a1}, commentIDs(thread.Ancestors))
+	ts.Empty(thread.Descendants)
+
+	// the first comments of the subtree in the TREE order
+	thread, err = ts.CommentContext(ctx, root, 5, 4)
+	ts.Require().NoError(err)
+	ts.Empty(thread.Ancestors)
+	ts.Equal([]int64{a, a1, a11, a2}, commentIDs(thread.Descendants))
+	thread, err = ts.CommentContext(ctx, root, 0, 10)
+	ts.Require().NoError(err)
+	ts.Equal([]int64{a, a1, a11, a2, b}, commentIDs(thread.Descendants))
+
+	_, err = ts.CommentContext(ctx, 1<<40, 10, 10)
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
+
+func (ts *StoragerTestSuite) TestCommentsAfter() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: 1}))
+	ts.Require().NoError(err)
+	otherID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "another post", User: 1}))
+	ts.Require().NoError(err)
+	save := func(postID int64, parentID *int64) int64 {
+		id, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: 2, PostID: postID, ParentCommentID: parentID})
+		ts.Require().NoError(err)
+		return id
+	}
+	root := save(postID, nil)
+	a := save(postID, &root)
+	root2 := save(postID, nil)
+	b := save(postID, &root)
+	a1 := save(postID, &a)
+	other := save(otherID, nil)
+
+	limit, offset := 10, 0
+	list, err := ts.CommentsAfter(ctx, postID, a, false, &limit, &offset)
+	ts.Require().NoError(err)
+	ts.Equal([]int64{a1, b, root2}, commentIDs(list))
+
+	limit, offset = 1, 1
+	list, err = ts.CommentsAfter(ctx, postID, a, false, &limit, &offset)
+	ts.Require().NoError(err)
+	ts.Equal([]int64{b}, commentIDs(list))
+
+	limit, offset = 10, 0
+	list, err = ts.CommentsAfter(ctx, postID, root2, false, &limit, &offset)
+	ts.Require().NoError(err)
+	ts.Empty(list)
+
+	// inclusive cursor starts with the comment
+	list, err = ts.CommentsAfter(ctx, postID, b, true, &limit, &offset)
+	ts.Require().NoError(err)
+	ts.Equal([]int64{b, root2}, commentIDs(list))
+
+	_, err = ts.CommentsAfter(ctx, postID, other, false, &limit, &offset)
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+	_, err = ts.CommentsAfter(ctx, postID, 1<<40, false, &limit, &offset)
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+// counters of the comment are changed by the difference between the new and the previous vote
+func (s *StorageMemory) Vote(ctx context.Context, vote entity.Vote) (*entity.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.IDValueCommentMap[vote.CommentID]
+	if !ok {
+		return nil, storage.ErrCommentNotFound
+	}
+
+	votes := s.CommentVotes[vote.CommentID]
+	if votes == nil {
+		votes = make(map[int64]int)
+		s.CommentVotes[vote.CommentID] = votes
+	}
+	countVote(&comment, votes[vote.UserID], -1)
+	countVote(&comment, vote.Value, 1)
+	if vote.Value == entity.VoteNone {
+		delete(votes, vote.UserID)
+	} else {
+		votes[vote.UserID] = vote.Value
+	}
+	s.IDValueCommentMap[vote.CommentID] = comment
+
+	return &comment, nil
+}
+
+func countVote(comment *entity.Comment, value int, delta int64) {
+	switch value {
+	case entity.VoteUp:
+		comment.Upvotes += delta
+	case entity.VoteDown:
+		comment.Downvotes += delta
+	}
+}
+
+// returns ids of the sibling comments in the sort order, ids are ordered by creation
+func (s *StorageMemory) siblings(ids []int64, sort string) []int64 {
+	if sort == entity.SortTree || sort == "" {
+		return ids
+	}
+	sorted := slices.Clone(ids)
+	slices.SortFunc(sorted, func(a, b int64) int {
+		return compareComments(s.IDValueCommentMap[a], s.IDValueCommentMap[b], sort)
+	})
+	return sorted
+}
+
+// ties are resolved by creation order (oldest first)
+func compareComments(a, b entity.Comment, sort string) int {
+	var order int
+	switch sort {
+	case entity.SortTop:
+		order = cmp.Compare(b.Upvotes-b.Downvotes, a.Upvotes-a.Downvotes)
+	case entity.SortNew:
+		return cmp.Compare(b.ID, a.ID)
+	case entity.SortControversial:
+		order = cmp.Compare(controversy(b), controversy(a))
+	}
+	if order != 0 {
+		return order
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// comment with many votes and balanced upvotes and downvotes is more controversial,
+// comment without upvotes or downvotes is not controversial at all
+func controversy(comment entity.Comment) float64 {
+	if comment.Upvotes == 0 || comment.Downvotes == 0 {
+		return 0
+	}
+	balance := float64(min(comment.Upvotes, comment.Downvotes)) / float64(max(comment.Upvotes, comment.Downvotes))
+	return math.Pow(float64(comment.Upvotes+comment.Downvotes), balance)
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/dkrasnykh/graphql-app/internal/entity"
+	"github.com/dkrasnykh/graphql-app/internal/storage"
+)
+
+func (ts *StoragerTestSuite) TestVote_ReplaceAndRemove() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+	commentID, err := ts.SaveComment(ctx, entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: postID})
+	ts.Require().NoError(err)
+	user1, user2 := rand.Int63(), rand.Int63()
+
+	comment, err := ts.Vote(ctx, entity.Vote{CommentID: commentID, UserID: user1, Value: entity.VoteUp})
+	ts.Require().NoError(err)
+	ts.Equal(int64(1), comment.Upvotes)
+	ts.Equal(int64(0), comment.Downvotes)
+
+	// the same vote again does not change counters
+	comment, err = ts.Vote(ctx, entity.Vote{CommentID: commentID, UserID: user1, Value: entity.VoteUp})
+	ts.Require().NoError(err)
+	ts.Equal(int64(1), comment.Upvotes)
+
+	_, err = ts.Vote(ctx, entity.Vote{CommentID: commentID, UserID: user2, Value: entity.VoteUp})
+	ts.Require().NoError(err)
+	comment, err = ts.Vote(ctx, entity.Vote{CommentID: commentID, UserID: user1, Value: entity.VoteDown})
+	ts.Require().NoError(err)
+	ts.Equal(int64(1), comment.Upvotes)
+	ts.Equal(int64(1), comment.Downvotes)
+
+	comment, err = ts.Vote(ctx, entity.Vote{CommentID: commentID, UserID: user1, Value: entity.VoteNone})
+	ts.Require().NoError(err)
+	ts.Equal(int64(1), comment.Upvotes)
+	ts.Equal(int64(0), comment.Downvotes)
+	// votes do not change the text, so the version is the same
+	ts.Equal(int64(entity.FirstVersion), comment.Version)
+
+	actual, err := ts.CommentByID(ctx, commentID)
+	ts.Require().NoError(err)
+	ts.Equal(comment, actual)
+}
+
+func (ts *StoragerTestSuite) TestVote_CommentNotFound() {
+	_, err := ts.Vote(context.Background(), entity.Vote{CommentID: rand.Int63(), UserID: rand.Int63(), Value: entity.VoteUp})
+	ts.True(errors.Is(err, storage.ErrCommentNotFound))
+}
+
+/*
+post
+├── comment 1 (+1)
+│   ├── comment 3 (+1 -1)
+│   └── comment 4 (+2)
+├── comment 2 (+3 -2)
+│   └── comment 5
+└── comment 6 (-1)
+*/
+func (ts *StoragerTestSuite) TestAllComments_Sort() {
+	ctx := context.Background()
+	postID, err := savedPostID(ts.SavePost(ctx, entity.Post{Text: "awesome post", User: rand.Int63()}))
+	ts.Require().NoError(err)
+
+	parents := []int{0, 0, 1, 1, 2, 0}
+	ids := make([]int64, len(parents)+1)
+	for i, parent := range parents {
+		comment := entity.Comment{Text: "comment", UserID: rand.Int63(), PostID: postID}
+		if parent > 0 {
+			comment.ParentCommentID = &ids[parent]
+		}
+		ids[i+1], err = ts.SaveComment(ctx, comment)
+		ts.Require().NoError(err)
+	}
+
+	votes := map[int][]int{
+		1: {entity.VoteUp},
+		2: {entity.VoteUp, entity.VoteUp, entity.VoteUp, entity.VoteDown, entity.VoteDown},
+		3: {entity.VoteUp, entity.VoteDown},
+		4: {entity.VoteUp, entity.VoteUp},
+		6: {entity.VoteDown},
+	}
+	for comment, values := range votes {
+		for _, value := range values {
+			_, err = ts.Vote(ctx, entity.Vote{CommentID: ids[comment], UserID: rand.Int63(), Value: value})
+			ts.Require().NoError(err)
+		}
+	}
+
+	tests := []struct {
+		sort     string
+		expected []int
+	}{
+		{sort: entity.SortTree, expected: []int{1, 3, 4, 2, 5, 6}},
+		{sort: entity.SortTop, expected: []int{1, 4, 3, 2, 5, 6}},
+		{sort: entity.SortNew, expected: []int{6, 2, 5, 1, 4, 3}},
+		{sort: entity.SortControversial, expected: []int{2, 5, 1, 3, 4, 6}},
+	}
+	for _, tt := range tests {
+		limit, offset := 10, 0
+		list, err := ts.AllComments(ctx, postID, &limit, &offset, tt.sort)
+		ts.Require().NoError(err)
+		ts.Require().Len(list, len(tt.expected), tt.sort)
+		for i, n := range tt.expected {
+			ts.Equal(ids[n], list[i].ID, "%s: position %d", tt.sort, i)
+		}
+	}
+
+	// pagination is applied to the sorted tree
+	limit, offset := 2, 1
+	list, err := ts.AllComments(ctx, postID, &limit, &offset, entity.SortTop)
+	ts.Require().NoError(err)
+	ts.Require().Len(list, 2)
+	ts.Equal(ids[4], list[0].ID)
+	ts.Equal(ids[3], list[1].ID)
+}
@@ -6,73 +6,175 @@ import (
 	"github.com/dkrasnykh/graphql-app/graph/model"
 )
 
+// Subscription keeps subscriptions of all topics, comments are delivered by the embedded topic
 type Subscription struct {
+	// comments of the posts, key is post id
+	*Topic[*model.Comment]
+	// new posts of the followed users, key is id of the follower
+	Feed *Topic[*model.Post]
+	// new notifications, key is id of the notified user
+	Notifications *Topic[*model.Notification]
+}
+
+func New() *Subscription {
+	return &Subscription{
+		Topic:         NewTopic[*model.Comment](),
+		Feed:          NewTopic[*model.Post](),
+		Notifications: NewTopic[*model.Notification](),
+	}
+}
+
+// Close stops sending updates to all subscriptions (current and new ones), used on server shutdown
+func (s *Subscription) Close() {
+	s.Topic.Close()
+	s.Feed.Close()
+	s.Notifications.Close()
+}
+
+// returns number of active subscriptions of all topics
+func (s *Subscription) Count() int {
+	return s.Topic.Count() + s.Feed.Count() + s.Notifications.Count()
+}
+
+type Stats struct {
+	// number of active subscriptions
+	Subscriptions int
+	// number of posts with at least one subscriber
+	ObservedPosts int
+	// number of (post, subscription) pairs
+	Subscribers int
+}
+
+// returns stats of comment subscriptions
+func (s *Subscription) Stats() Stats {
+	return s.Topic.stats()
+}
+
+// Topic delivers updates of type T to subscribers of the keys (ids of posts or users)
+type Topic[T any] struct {
 	mu sync.RWMutex
 
 	counter int64
-	chs     map[int64]chan *model.Comment
-	// for each post save set of subscribers
-	// map[postID]map[subsribtionID]true
-	postObservers map[int64]map[int64]bool
-	// save subscribtion posts
+	chs     map[int64]chan T
+	// closed when subscription is deleted or all subscriptions are closed (server shutdown)
+	done map[int64]chan struct{}
+	// for each key save set of subscribers
+	// map[key]map[subsribtionID]true
+	observers map[int64]map[int64]bool
+	// save subscribtion keys
 	subscription map[int64][]int64
+	closed       bool
 }
 
-func New() *Subscription {
-	return &Subscription{
-		mu:            sync.RWMutex{},
-		counter:       1,
-		chs:           make(map[int64]chan *model.Comment),
-		postObservers: make(map[int64]map[int64]bool),
-		subscription:  make(map[int64][]int64),
+func NewTopic[T any]() *Topic[T] {
+	return &Topic[T]{
+		mu:           sync.RWMutex{},
+		counter:      1,
+		chs:          make(map[int64]chan T),
+		done:         make(map[int64]chan struct{}),
+		observers:    make(map[int64]map[int64]bool),
+		subscription: make(map[int64][]int64),
 	}
 }
 
-func (s *Subscription) Add(posts []int64) (int64, chan *model.Comment) {
+// returns subscription id, chan with updates from server and chan, which is closed when server stops sending updates
+func (s *Topic[T]) Add(keys []int64) (int64, <-chan T, <-chan struct{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	subscriptionID := s.counter
 	s.counter += 1
 
-	ch := make(chan *model.Comment)
+	ch := make(chan T)
+	done := make(chan struct{})
+	if s.closed {
+		close(done)
+	}
 	s.chs[subscriptionID] = ch
-	s.subscription[subscriptionID] = make([]int64, 0, len(posts))
-	for _, post := range posts {
-		if _, ok := s.postObservers[post]; !ok {
-			s.postObservers[post] = make(map[int64]bool)
+	s.done[subscriptionID] = done
+	s.subscription[subscriptionID] = make([]int64, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := s.observers[key]; !ok {
+			s.observers[key] = make(map[int64]bool)
 		}
-		s.postObservers[post][subscriptionID] = true
-		s.subscription[subscriptionID] = append(s.subscription[subscriptionID], post)
+		s.observers[key][subscriptionID] = true
+		s.subscription[subscriptionID] = append(s.subscription[subscriptionID], key)
 	}
-	// returns subsribtion id and chan with updates from server
-	return subscriptionID, ch
+	return subscriptionID, ch, done
 }
 
-func (s *Subscription) Delete(subscriptionID int64) {
+func (s *Topic[T]) Delete(subscriptionID int64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	// clear data for every post
-	for _, post := range s.subscription[subscriptionID] {
-		delete(s.postObservers[post], subscriptionID)
-		// If there are no more subscriptions for a post, then delete the post
-		if len(s.postObservers[post]) == 0 {
-			delete(s.postObservers, post)
+	// clear data for every key
+	for _, key := range s.subscription[subscriptionID] {
+		delete(s.observers[key], subscriptionID)
+		// If there are no more subscriptions for a key, then delete the key
+		if len(s.observers[key]) == 0 {
+			delete(s.observers, key)
 		}
 	}
+	// done channels are already closed after Close
+	if done, ok := s.done[subscriptionID]; ok && !s.closed {
+		close(done)
+	}
 	delete(s.chs, subscriptionID)
+	delete(s.done, subscriptionID)
 	delete(s.subscription, subscriptionID)
 }
 
-func (s *Subscription) Broadcast(postID int64, comment *model.Comment) {
+func (s *Topic[T]) Broadcast(key int64, update T) {
+	type subscriber struct {
+		ch   chan T
+		done chan struct{}
+	}
+
+	// lock is not held while sending: subscriber can be deleted concurrently
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	subscribers := make([]subscriber, 0, len(s.observers[key]))
+	for subscriptionID := range s.observers[key] {
+		subscribers = append(subscribers, subscriber{ch: s.chs[subscriptionID], done: s.done[subscriptionID]})
+	}
+	s.mu.RUnlock()
+
+	// send update for every subscribtion
+	for _, sub := range subscribers {
+		select {
+		case sub.ch <- update:
+		case <-sub.done:
+		}
+	}
+}
 
-	if _, ok := s.postObservers[postID]; !ok {
+// Close stops sending updates to all subscriptions of the topic (current and new ones)
+func (s *Topic[T]) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
 		return
 	}
-	// send update for every subscribtion
-	for subscriptionID, _ := range s.postObservers[postID] {
-		s.chs[subscriptionID] <- comment
+	s.closed = true
+	for _, done := range s.done {
+		close(done)
+	}
+}
+
+// returns number of active subscriptions
+func (s *Topic[T]) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.chs)
+}
+
+func (s *Topic[T]) stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := Stats{Subscriptions: len(s.chs), ObservedPosts: len(s.observers)}
+	for _, observers := range s.observers {
+		stats.Subscribers += len(observers)
 	}
+	return stats
 }
@@ -0,0 +1,86 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dkrasnykh/graphql-app/graph/model"
+)
+
+func TestBroadcast(t *testing.T) {
+	s := New()
+	_, updates, _ := s.Add([]int64{1, 2})
+
+	go s.Broadcast(1, &model.Comment{ID: "1"})
+
+	select {
+	case comment := <-updates:
+		assert.Equal(t, "1", comment.ID)
+	case <-time.After(time.Second):
+		t.Fatal("update is not received")
+	}
+	assert.Equal(t, Stats{Subscriptions: 1, ObservedPosts: 2, Subscribers: 2}, s.Stats())
+}
+
+func TestBroadcast_DeletedSubscriber(t *testing.T) {
+	s := New()
+	id, _, done := s.Add([]int64{1})
+
+	broadcasted := make(chan struct{})
+	go func() {
+		// nobody reads updates, broadcast is blocked until subscription is deleted
+		s.Broadcast(1, &model.Comment{ID: "1"})
+		close(broadcasted)
+	}()
+
+	s.Delete(id)
+
+	select {
+	case <-broadcasted:
+	case <-time.After(time.Second):
+		t.Fatal("broadcast is blocked")
+	}
+	_, ok := <-done
+	assert.False(t, ok)
+	assert.Equal(t, 0, s.Count())
+}
+
+func TestClose(t *testing.T) {
+	s := New()
+	id, _, done := s.Add([]int64{1})
+
+	s.Close()
+	_, ok := <-done
+	assert.False(t, ok)
+
+	// subscriptions added after close are closed immediately
+	_, _, done2 := s.Add([]int64{1})
+	_, ok = <-done2
+	assert.False(t, ok)
+
+	s.Delete(id)
+	assert.Equal(t, 1, s.Count())
+}
+
+func TestFeed(t *testing.T) {
+	s := New()
+	_, updates, done := s.Feed.Add([]int64{2})
+	assert.Equal(t, 1, s.Count())
+	// feed subscriptions are not counted in stats of comment subscriptions
+	assert.Equal(t, Stats{}, s.Stats())
+
+	go s.Feed.Broadcast(2, &model.Post{ID: "1"})
+
+	select {
+	case post := <-updates:
+		assert.Equal(t, "1", post.ID)
+	case <-time.After(time.Second):
+		t.Fatal("update is not received")
+	}
+
+	s.Close()
+	_, ok := <-done
+	assert.False(t, ok)
+}
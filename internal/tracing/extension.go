@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/dkrasnykh/graphql-app/internal/tracing")
+
+// Extension creates span for every operation (every event for subscriptions)
+// and child spans for the fields with resolvers
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Extension{}
+
+func (e Extension) ExtensionName() string {
+	return "Tracing"
+}
+
+func (e Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	rc := graphql.GetOperationContext(ctx)
+
+	name, opType := rc.OperationName, "unknown"
+	if rc.Operation != nil {
+		name, opType = rc.Operation.Name, string(rc.Operation.Operation)
+	}
+	if name == "" {
+		name = "anonymous"
+	}
+
+	ctx, span := tracer.Start(ctx, opType+" "+name, trace.WithAttributes(
+		attribute.String("graphql.operation.name", name),
+		attribute.String("graphql.operation.type", opType),
+	))
+	defer span.End()
+
+	resp := next(ctx)
+	if resp != nil && len(resp.Errors) > 0 {
+		span.SetStatus(codes.Error, resp.Errors.Error())
+	}
+
+	return resp
+}
+
+func (e Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := tracer.Start(ctx, fc.Object+"."+fc.Field.Name, trace.WithAttributes(
+		attribute.String("graphql.field.path", fc.Path().String()),
+	))
+	defer span.End()
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return res, err
+}
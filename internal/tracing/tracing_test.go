@@ -0,0 +1,32 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/dkrasnykh/graphql-app/internal/config"
+)
+
+func TestSetup_FileExporter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), config.Tracing{Exporter: ExporterFile, File: file, SampleRatio: 1})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"Name":"test span"`)
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), config.Tracing{Exporter: "jaeger"})
+	require.Error(t, err)
+}